@@ -2,4 +2,8 @@ module github.com/nantokaworks/konst
 
 go 1.23.4
 
-require golang.org/x/text v0.23.0
+require (
+	github.com/BurntSushi/toml v1.6.0
+	golang.org/x/text v0.23.0
+	gopkg.in/yaml.v3 v3.0.1
+)
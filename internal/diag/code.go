@@ -0,0 +1,30 @@
+package diag
+
+// Code は診断の種類を示す機械可読なコードです。
+// JSON や SARIF 出力ではルールIDとして使われるため、一度公開したコードは変更しないでください。
+type Code string
+
+const (
+	CodeError               Code = "error"                // 分類されていないエラー
+	CodeJSONSyntax          Code = "json-syntax"          // JSON の構文エラー
+	CodeJSONType            Code = "json-type"            // JSON の値がスキーマの構造と合わない
+	CodeSyntax              Code = "syntax"               // YAML / TOML など JSON 以外の定義ファイルの構文エラー
+	CodeInvalidName         Code = "invalid-name"         // 定義名やパッケージ名が識別子として不正
+	CodeUnknownType         Code = "unknown-type"         // 未知の type
+	CodeUnknownMode         Code = "unknown-mode"         // 未知の tsMode / goMode / goEnumInterfaces
+	CodeMissingField        Code = "missing-field"        // 必須フィールドがない
+	CodeValueType           Code = "value-type"           // value が宣言された型と合わない
+	CodeInvalidEnum         Code = "invalid-enum"         // enum の values / default が不正
+	CodeInvalidTemplate     Code = "invalid-template"     // template と parameters が一致しない
+	CodeInvalidObject       Code = "invalid-object"       // object / table の fields・columns と値が一致しない
+	CodeDuplicateKey        Code = "duplicate-key"        // table のキー列の値が重複している
+	CodeInvalidMap          Code = "invalid-map"          // map のキーが keyType と合わない、enum のメンバーが足りない
+	CodeInvalidExpression   Code = "invalid-expression"   // {{}} の式の構文・型・評価のエラー
+	CodeUndefinedReference  Code = "undefined-reference"  // {{}} で参照した定義が存在しない
+	CodeAmbiguousReference  Code = "ambiguous-reference"  // {{}} で参照した定義名が複数の定義ファイルにあり、参照先を決められない
+	CodeNonScalarReference  Code = "non-scalar-reference" // 配列や object などスカラーでない定義を文字列に埋め込んでいる（警告）
+	CodeDuplicateDefinition Code = "duplicate-definition" // 同じ定義名が複数の定義ファイルにある
+	CodeCircular            Code = "circular-dependency"  // 循環参照
+	CodeDependency          Code = "dependency"           // 依存関係の解決エラー
+	CodeGenerate            Code = "generate"             // コード生成時のエラー
+)
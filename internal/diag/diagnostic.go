@@ -0,0 +1,226 @@
+package diag
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/nantokaworks/konst/internal/types"
+)
+
+// Severity は診断の重大度を示す列挙型です。
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic は定義ファイル内の位置を伴うエラーです。
+// Error() は "file:line:col: message" 形式で、エディタから該当箇所にジャンプできます。
+type Diagnostic struct {
+	Severity Severity       // 重大度
+	Code     Code           // 問題の種類を示すコード
+	Pos      types.Position // 問題の位置
+	Name     string         // 関連する定義名（ファイル全体の問題の場合は空）
+	Message  string         // 問題の内容
+}
+
+// New は位置付きのエラー診断を作成します。
+func New(code Code, pos types.Position, name, format string, args ...any) *Diagnostic {
+	return &Diagnostic{
+		Severity: SeverityError,
+		Code:     code,
+		Pos:      pos,
+		Name:     name,
+		Message:  fmt.Sprintf(format, args...),
+	}
+}
+
+// NewWarning は位置付きの警告診断を作成します。
+func NewWarning(code Code, pos types.Position, name, format string, args ...any) *Diagnostic {
+	d := New(code, pos, name, format, args...)
+	d.Severity = SeverityWarning
+	return d
+}
+
+// Error は "file:line:col: 定義名: 内容" 形式の文字列を返します。警告には "warning: " を付けます。
+func (d *Diagnostic) Error() string {
+	var b strings.Builder
+	if pos := d.Pos.String(); pos != "" {
+		b.WriteString(pos)
+		b.WriteString(": ")
+	}
+	if d.Severity == SeverityWarning {
+		b.WriteString("warning: ")
+	}
+	if d.Name != "" {
+		b.WriteString(d.Name)
+		b.WriteString(": ")
+	}
+	b.WriteString(d.Message)
+	return b.String()
+}
+
+// List は複数の Diagnostic をまとめたエラーです。
+type List []*Diagnostic
+
+// Error は各 Diagnostic を1行ずつ連結した文字列を返します
+func (l List) Error() string {
+	lines := make([]string, len(l))
+	for i, d := range l {
+		lines[i] = d.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Unwrap は各 Diagnostic を返し、errors.Is / errors.As で個々の診断を調べられるようにします。
+func (l List) Unwrap() []error {
+	errs := make([]error, len(l))
+	for i, d := range l {
+		errs[i] = d
+	}
+	return errs
+}
+
+// Sort はファイル、行、列の順に並べ替えます。位置が同じ診断は定義名の順にします。
+func (l List) Sort() {
+	sort.SliceStable(l, func(i, j int) bool {
+		a, b := l[i].Pos, l[j].Pos
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if a.Column != b.Column {
+			return a.Column < b.Column
+		}
+		return l[i].Name < l[j].Name
+	})
+}
+
+// Err は空でなければ List 自身を、空なら nil を返します。
+func (l List) Err() error {
+	if len(l) == 0 {
+		return nil
+	}
+	return l
+}
+
+// HasErrors は重大度がエラーの診断を含むかどうかを返します。
+func (l List) HasErrors() bool {
+	for _, d := range l {
+		if d.Severity != SeverityWarning {
+			return true
+		}
+	}
+	return false
+}
+
+// Flatten はエラーに含まれる Diagnostic を取り出します。
+// 位置情報を持たないエラーの場合は nil を返します。
+func Flatten(err error) List {
+	var list List
+	if errors.As(err, &list) {
+		return list
+	}
+	var d *Diagnostic
+	if errors.As(err, &d) {
+		return List{d}
+	}
+	return nil
+}
+
+// FromError はエラーを診断の一覧に変換します。
+// Diagnostic を含まないエラーは位置なしの CodeError 診断として扱います。
+func FromError(err error) List {
+	if list := Flatten(err); list != nil {
+		return list
+	}
+	return List{New(CodeError, types.Position{}, "", "%v", err)}
+}
+
+// PositionAt はバイトオフセットを行・列の位置に変換します。列は文字単位で数えます。
+func PositionAt(file string, data []byte, offset int64) types.Position {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	line, lineStart := 1, 0
+	for i := 0; i < int(offset); i++ {
+		if data[i] == '\n' {
+			line++
+			lineStart = i + 1
+		}
+	}
+	column := utf8.RuneCount(data[lineStart:offset]) + 1
+	return types.Position{File: file, Line: line, Column: column}
+}
+
+// FromJSONError は encoding/json のエラーを位置付きの Diagnostic に変換します。
+// 位置を特定できないエラーはファイル名のみを付与します。
+func FromJSONError(file string, data []byte, err error) *Diagnostic {
+	return FromJSONErrorAt(file, data, err, func(offset int64) types.Position {
+		return PositionAt(file, data, offset)
+	})
+}
+
+// FromJSONErrorAt は FromJSONError と同様ですが、data 内のオフセットを posAt で位置に変換します。
+// JSONC などを JSON に変換してからデコードした場合に、変換前のファイルの位置を報告するために使います。
+func FromJSONErrorAt(file string, data []byte, err error, posAt func(offset int64) types.Position) *Diagnostic {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		// Offset はエラーを検出した文字の直後を指すため、1文字戻す
+		return New(CodeJSONSyntax, posAt(syntaxErr.Offset-1), "", "%s", syntaxErr.Error())
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		// Offset は不正な値の直後を指すため、値の先頭まで戻す
+		offset := valueStart(data, typeErr.Offset)
+		msg := fmt.Sprintf("cannot use JSON %s as %s", typeErr.Value, typeErr.Type)
+		if typeErr.Field != "" {
+			msg = fmt.Sprintf("%s for field %q", msg, typeErr.Field)
+		}
+		return New(CodeJSONType, posAt(offset), "", "%s", msg)
+	}
+	return New(CodeJSONSyntax, types.Position{File: file}, "", "%v", err)
+}
+
+// valueStart は値の末尾オフセットから値の先頭オフセットを推定します
+func valueStart(data []byte, end int64) int64 {
+	if end > int64(len(data)) {
+		end = int64(len(data))
+	}
+	depth := 0
+	inString := false
+	for i := end - 1; i >= 0; i-- {
+		c := data[i]
+		switch {
+		case inString:
+			if c == '"' && (i == 0 || data[i-1] != '\\') {
+				inString = false
+				if depth == 0 {
+					return i
+				}
+			}
+		case c == '"':
+			inString = true
+		case c == '}' || c == ']':
+			depth++
+		case c == '{' || c == '[':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		case depth == 0 && (c == ':' || c == ',' || c == ' ' || c == '\t' || c == '\n' || c == '\r'):
+			return i + 1
+		}
+	}
+	return 0
+}
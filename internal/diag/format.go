@@ -0,0 +1,244 @@
+package diag
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// 診断の出力形式
+const (
+	FormatText  = "text"  // "file:line:col: message" 形式のテキスト
+	FormatJSON  = "json"  // 1行1件の JSON Lines
+	FormatSARIF = "sarif" // SARIF 2.1.0
+)
+
+// Writer は診断を指定された形式で出力します。
+// Close を呼ぶまで出力が完了しない形式（SARIF など）があるため、最後に必ず Close を呼んでください。
+type Writer interface {
+	Write(list List) error
+	Close() error
+}
+
+// NewWriter は出力形式に対応する Writer を作成します。version は SARIF のツール情報に使用します。
+func NewWriter(format string, w io.Writer, version string) (Writer, error) {
+	switch format {
+	case "", FormatText:
+		return &textWriter{w: w}, nil
+	case FormatJSON:
+		return &jsonWriter{enc: json.NewEncoder(w)}, nil
+	case FormatSARIF:
+		return &sarifWriter{w: w, version: version}, nil
+	default:
+		return nil, fmt.Errorf("unknown diagnostics format: %s", format)
+	}
+}
+
+// IsMachineReadable は出力形式が機械可読な形式かどうかを返します。
+func IsMachineReadable(format string) bool {
+	return format == FormatJSON || format == FormatSARIF
+}
+
+// textWriter は人が読むためのテキスト形式で出力します
+type textWriter struct {
+	w io.Writer
+}
+
+func (t *textWriter) Write(list List) error {
+	for _, d := range list {
+		if _, err := fmt.Fprintln(t.w, d.Error()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *textWriter) Close() error {
+	return nil
+}
+
+// jsonDiagnostic は JSON Lines 形式の1件分の構造です
+type jsonDiagnostic struct {
+	Severity Severity `json:"severity"`
+	Code     Code     `json:"code"`
+	File     string   `json:"file,omitempty"`
+	Line     int      `json:"line,omitempty"`
+	Column   int      `json:"column,omitempty"`
+	Name     string   `json:"name,omitempty"`
+	Message  string   `json:"message"`
+}
+
+// jsonWriter は1件ごとに1行の JSON を出力します
+type jsonWriter struct {
+	enc *json.Encoder
+}
+
+func (j *jsonWriter) Write(list List) error {
+	for _, d := range list {
+		err := j.enc.Encode(jsonDiagnostic{
+			Severity: d.Severity,
+			Code:     d.Code,
+			File:     d.Pos.File,
+			Line:     d.Pos.Line,
+			Column:   d.Pos.Column,
+			Name:     d.Name,
+			Message:  d.Message,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (j *jsonWriter) Close() error {
+	return nil
+}
+
+// sarifWriter は診断を溜めておき、Close 時に SARIF 2.1.0 のログとして出力します
+type sarifWriter struct {
+	w       io.Writer
+	version string
+	list    List
+}
+
+func (s *sarifWriter) Write(list List) error {
+	s.list = append(s.list, list...)
+	return nil
+}
+
+func (s *sarifWriter) Close() error {
+	enc := json.NewEncoder(s.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(buildSARIF(s.list, s.version))
+}
+
+// SARIF 2.1.0 のうち konst が使用する部分の構造
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID           string                 `json:"ruleId"`
+	Level            string                 `json:"level"`
+	Message          sarifMessage           `json:"message"`
+	Locations        []sarifLocation        `json:"locations,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+type sarifLogicalLocation struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+}
+
+// fileURI はファイルパスを SARIF の artifactLocation 用の URI に変換します。
+// 相対パスは相対参照のまま、絶対パスは file スキームの URI にします。
+func fileURI(path string) string {
+	slashed := filepath.ToSlash(path)
+	if !filepath.IsAbs(path) {
+		return (&url.URL{Path: slashed}).String()
+	}
+	if !strings.HasPrefix(slashed, "/") {
+		// Windows のドライブレター付きパス
+		slashed = "/" + slashed
+	}
+	return (&url.URL{Scheme: "file", Path: slashed}).String()
+}
+
+// buildSARIF は診断の一覧から SARIF ログを組み立てます
+func buildSARIF(list List, version string) sarifLog {
+	ruleSet := make(map[Code]bool)
+	results := make([]sarifResult, 0, len(list))
+	for _, d := range list {
+		ruleSet[d.Code] = true
+
+		result := sarifResult{
+			RuleID:  string(d.Code),
+			Level:   string(d.Severity),
+			Message: sarifMessage{Text: d.Message},
+		}
+		if d.Pos.File != "" {
+			loc := sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: fileURI(d.Pos.File)},
+			}
+			if d.Pos.IsValid() {
+				loc.Region = &sarifRegion{StartLine: d.Pos.Line, StartColumn: d.Pos.Column}
+			}
+			result.Locations = []sarifLocation{{PhysicalLocation: loc}}
+		}
+		if d.Name != "" {
+			result.LogicalLocations = []sarifLogicalLocation{{Name: d.Name, Kind: "member"}}
+		}
+		results = append(results, result)
+	}
+
+	rules := make([]sarifRule, 0, len(ruleSet))
+	for code := range ruleSet {
+		rules = append(rules, sarifRule{ID: string(code)})
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].ID < rules[j].ID
+	})
+
+	return sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "konst",
+				Version:        version,
+				InformationURI: "https://github.com/nantokaworks/konst",
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	}
+}
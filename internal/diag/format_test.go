@@ -0,0 +1,132 @@
+package diag
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/nantokaworks/konst/internal/types"
+)
+
+func testDiagnostics() List {
+	return List{
+		New(CodeInvalidEnum, types.Position{File: "defs/enum.json", Line: 4, Column: 5}, "Status", "default %q is not one of the enum values", "x"),
+		NewWarning(CodeDependency, types.Position{File: "defs/limits.json"}, "", "unused definition"),
+	}
+}
+
+func TestTextWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatText, &buf, "v0.0.0")
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	if err := w.Write(testDiagnostics()); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	expected := "defs/enum.json:4:5: Status: default \"x\" is not one of the enum values\n" +
+		"defs/limits.json: warning: unused definition\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestJSONWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatJSON, &buf, "v0.0.0")
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	if err := w.Write(testDiagnostics()); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d: %q", len(lines), buf.String())
+	}
+
+	var first map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatalf("Invalid JSON line: %v", err)
+	}
+	expected := map[string]any{
+		"severity": "error",
+		"code":     "invalid-enum",
+		"file":     "defs/enum.json",
+		"line":     float64(4),
+		"column":   float64(5),
+		"name":     "Status",
+		"message":  `default "x" is not one of the enum values`,
+	}
+	for key, value := range expected {
+		if first[key] != value {
+			t.Errorf("Expected %s = %v, got %v", key, value, first[key])
+		}
+	}
+}
+
+func TestSARIFWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatSARIF, &buf, "v0.0.0")
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	if err := w.Write(testDiagnostics()); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	// SARIF は Close するまで出力しない
+	if buf.Len() != 0 {
+		t.Fatalf("Expected no output before Close, got %q", buf.String())
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("Invalid SARIF: %v", err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("Unexpected SARIF log: %+v", log)
+	}
+
+	run := log.Runs[0]
+	if run.Tool.Driver.Name != "konst" || run.Tool.Driver.Version != "v0.0.0" {
+		t.Errorf("Unexpected tool driver: %+v", run.Tool.Driver)
+	}
+	if len(run.Tool.Driver.Rules) != 2 || run.Tool.Driver.Rules[0].ID != "dependency" {
+		t.Errorf("Expected sorted rules, got %+v", run.Tool.Driver.Rules)
+	}
+	if len(run.Results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(run.Results))
+	}
+
+	first := run.Results[0]
+	if first.RuleID != "invalid-enum" || first.Level != "error" {
+		t.Errorf("Unexpected result: %+v", first)
+	}
+	loc := first.Locations[0].PhysicalLocation
+	if loc.ArtifactLocation.URI != "defs/enum.json" || loc.Region == nil || loc.Region.StartLine != 4 || loc.Region.StartColumn != 5 {
+		t.Errorf("Unexpected location: %+v", loc)
+	}
+	if len(first.LogicalLocations) != 1 || first.LogicalLocations[0].Name != "Status" {
+		t.Errorf("Unexpected logical locations: %+v", first.LogicalLocations)
+	}
+
+	second := run.Results[1]
+	if second.Level != "warning" || second.Locations[0].PhysicalLocation.Region != nil {
+		t.Errorf("Unexpected result: %+v", second)
+	}
+}
+
+func TestNewWriterUnknownFormat(t *testing.T) {
+	if _, err := NewWriter("xml", &bytes.Buffer{}, ""); err == nil {
+		t.Error("Expected error for unknown format, but got nil")
+	}
+}
@@ -0,0 +1,109 @@
+// Package expr は定義の値に書く式（{{BaseRetries}} * 2 など）の構文解析と評価を行います。
+//
+// 式は Go に近い構文で、整数・小数・文字列・真偽値・期間（30s、1h30m）のリテラル、
+// 定義名の参照（network.MaxRetries、path/to/file#Name のように修飾した参照を含む）、括弧、単項演算子（- + !）、二項演算子（* / % << >> + - == != < <= > >= && ||）と
+// 関数呼び出し（min、max、len、upper）を書けます。
+package expr
+
+import "time"
+
+// Node は式の構文木のノードです。
+type Node interface {
+	// Pos は式の中のノードの位置（0 始まりのバイトオフセット）を返します
+	Pos() int
+}
+
+type (
+	// NumberLit は整数または小数のリテラルです
+	NumberLit struct {
+		Offset int
+		Text   string // 書かれた表記（0x1F、1.5e3 など）
+	}
+
+	// DurationLit は期間のリテラル（30s、1h30m、250ms など）です
+	DurationLit struct {
+		Offset int
+		Text   string
+		Value  time.Duration
+	}
+
+	// StringLit は文字列リテラル（"..." または '...'）です
+	StringLit struct {
+		Offset int
+		Value  string // エスケープを解釈した後の値
+	}
+
+	// BoolLit は true / false です
+	BoolLit struct {
+		Offset int
+		Value  bool
+	}
+
+	// Ident は定義名の参照です
+	Ident struct {
+		Offset int
+		Name   string // 修飾した定義名（network.MaxRetries、path/to/file#Name）の場合は修飾を含む
+	}
+
+	// Paren は括弧で囲んだ式です
+	Paren struct {
+		Offset int
+		X      Node
+	}
+
+	// Unary は単項演算子の式です
+	Unary struct {
+		Offset int
+		Op     string
+		X      Node
+	}
+
+	// Binary は二項演算子の式です
+	Binary struct {
+		OpOffset int
+		Op       string
+		X, Y     Node
+	}
+
+	// Call は関数呼び出しです
+	Call struct {
+		Offset int
+		Func   string
+		Args   []Node
+	}
+)
+
+func (n *NumberLit) Pos() int   { return n.Offset }
+func (n *DurationLit) Pos() int { return n.Offset }
+func (n *StringLit) Pos() int   { return n.Offset }
+func (n *BoolLit) Pos() int     { return n.Offset }
+func (n *Ident) Pos() int       { return n.Offset }
+func (n *Paren) Pos() int       { return n.Offset }
+func (n *Unary) Pos() int       { return n.Offset }
+func (n *Binary) Pos() int      { return n.X.Pos() }
+func (n *Call) Pos() int        { return n.Offset }
+
+// Idents は式の中の定義名の参照を出現順に返します
+func Idents(n Node) []*Ident {
+	var idents []*Ident
+	var walk func(Node)
+	walk = func(n Node) {
+		switch n := n.(type) {
+		case *Ident:
+			idents = append(idents, n)
+		case *Paren:
+			walk(n.X)
+		case *Unary:
+			walk(n.X)
+		case *Binary:
+			walk(n.X)
+			walk(n.Y)
+		case *Call:
+			for _, arg := range n.Args {
+				walk(arg)
+			}
+		}
+	}
+	walk(n)
+	return idents
+}
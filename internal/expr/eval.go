@@ -0,0 +1,323 @@
+package expr
+
+import (
+	"math/big"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxShift はシフト演算で許可するシフト量の上限です
+const maxShift = 1024
+
+// Env は定義名の参照を値に解決します。
+type Env func(ident *Ident) (Value, error)
+
+// Eval は構文木を評価して値を返します。定義名の参照は env で解決します。
+func Eval(n Node, env Env) (Value, error) {
+	switch n := n.(type) {
+	case *NumberLit:
+		return evalNumber(n)
+	case *DurationLit:
+		return DurationValue(n.Value), nil
+	case *StringLit:
+		return StringValue(n.Value), nil
+	case *BoolLit:
+		return BoolValue(n.Value), nil
+	case *Ident:
+		if env == nil {
+			return Value{}, errorf(n.Offset, "undefined reference %q", n.Name)
+		}
+		return env(n)
+	case *Paren:
+		return Eval(n.X, env)
+	case *Unary:
+		x, err := Eval(n.X, env)
+		if err != nil {
+			return Value{}, err
+		}
+		return evalUnary(n, x)
+	case *Binary:
+		return evalBinary(n, env)
+	case *Call:
+		return evalCall(n, env)
+	}
+	return Value{}, errorf(n.Pos(), "unsupported expression")
+}
+
+// evalNumber は数値リテラルを評価します。
+// 小数点を含むリテラルは小数、それ以外（16進数や 1e3 のような整数値の指数表記）は整数になります。
+func evalNumber(n *NumberLit) (Value, error) {
+	if strings.HasPrefix(n.Text, "0x") || strings.HasPrefix(n.Text, "0X") {
+		i, ok := new(big.Int).SetString(n.Text[2:], 16)
+		if !ok {
+			return Value{}, errorf(n.Offset, "invalid number %q", n.Text)
+		}
+		return IntValue(i), nil
+	}
+	r, ok := new(big.Rat).SetString(n.Text)
+	if !ok {
+		return Value{}, errorf(n.Offset, "invalid number %q", n.Text)
+	}
+	if !strings.Contains(n.Text, ".") && r.IsInt() {
+		return IntValue(new(big.Int).Set(r.Num())), nil
+	}
+	return FloatValue(r), nil
+}
+
+// evalUnary は単項演算子を評価します
+func evalUnary(n *Unary, x Value) (Value, error) {
+	switch {
+	case n.Op == "!" && x.Kind == KindBool:
+		return BoolValue(!x.Bool), nil
+	case n.Op == "+" && (x.IsNumber() || x.Kind == KindDuration):
+		return x, nil
+	case n.Op == "-" && x.Kind == KindInt:
+		return IntValue(new(big.Int).Neg(x.Int)), nil
+	case n.Op == "-" && x.Kind == KindFloat:
+		return FloatValue(new(big.Rat).Neg(x.Float)), nil
+	case n.Op == "-" && x.Kind == KindDuration:
+		return durationResult(n.Offset, new(big.Int).Neg(big.NewInt(int64(x.Duration))))
+	}
+	return Value{}, errorf(n.Offset, "invalid operation: %s%s", n.Op, x.Kind)
+}
+
+// evalBinary は二項演算子を評価します。&& と || は左辺で結果が決まる場合は右辺を評価しません。
+func evalBinary(n *Binary, env Env) (Value, error) {
+	x, err := Eval(n.X, env)
+	if err != nil {
+		return Value{}, err
+	}
+	if (n.Op == "&&" || n.Op == "||") && x.Kind == KindBool && x.Bool == (n.Op == "||") {
+		return x, nil
+	}
+	y, err := Eval(n.Y, env)
+	if err != nil {
+		return Value{}, err
+	}
+	invalid := errorf(n.OpOffset, "invalid operation: %s %s %s", x.Kind, n.Op, y.Kind)
+
+	switch n.Op {
+	case "&&", "||":
+		if x.Kind != KindBool || y.Kind != KindBool {
+			return Value{}, invalid
+		}
+		return y, nil
+	case "==", "!=", "<", "<=", ">", ">=":
+		c, ok := compare(x, y, n.Op == "==" || n.Op == "!=")
+		if !ok {
+			return Value{}, invalid
+		}
+		return BoolValue(compareResult(n.Op, c)), nil
+	}
+
+	switch {
+	case x.Kind == KindInt && y.Kind == KindInt:
+		return intOp(n, x.Int, y.Int, invalid)
+	case x.IsNumber() && y.IsNumber():
+		return floatOp(n, x.Rat(), y.Rat(), invalid)
+	case x.Kind == KindString && y.Kind == KindString && n.Op == "+":
+		return StringValue(x.Str + y.Str), nil
+	case x.Kind == KindDuration || y.Kind == KindDuration:
+		return durationOp(n, x, y, invalid)
+	}
+	return Value{}, invalid
+}
+
+// compare は2つの値を比較して -1・0・1 を返します。比較できない組み合わせの場合は ok が false になります。
+// equality が true の場合（== と !=）は真偽値どうしも比較できます。
+func compare(x, y Value, equality bool) (int, bool) {
+	switch {
+	case x.IsNumber() && y.IsNumber():
+		return x.Rat().Cmp(y.Rat()), true
+	case x.Kind == KindString && y.Kind == KindString:
+		return strings.Compare(x.Str, y.Str), true
+	case x.Kind == KindDuration && y.Kind == KindDuration:
+		return big.NewInt(int64(x.Duration)).Cmp(big.NewInt(int64(y.Duration))), true
+	case x.Kind == KindBool && y.Kind == KindBool && equality:
+		if x.Bool == y.Bool {
+			return 0, true
+		}
+		return 1, true
+	}
+	return 0, false
+}
+
+// compareResult は比較の結果 c を比較演算子 op の結果にします
+func compareResult(op string, c int) bool {
+	switch op {
+	case "==":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	default:
+		return c >= 0
+	}
+}
+
+// intOp は整数どうしの演算を Go と同じ規則（除算・剰余は0方向への切り捨て）で計算します
+func intOp(n *Binary, x, y *big.Int, invalid error) (Value, error) {
+	z := new(big.Int)
+	switch n.Op {
+	case "+":
+		z.Add(x, y)
+	case "-":
+		z.Sub(x, y)
+	case "*":
+		z.Mul(x, y)
+	case "/", "%":
+		if y.Sign() == 0 {
+			return Value{}, errorf(n.OpOffset, "division by zero")
+		}
+		if n.Op == "/" {
+			z.Quo(x, y)
+		} else {
+			z.Rem(x, y)
+		}
+	case "<<", ">>":
+		if y.Sign() < 0 || y.Cmp(big.NewInt(maxShift)) > 0 {
+			return Value{}, errorf(n.OpOffset, "invalid shift count %s (must be 0 to %d)", y, maxShift)
+		}
+		if n.Op == "<<" {
+			z.Lsh(x, uint(y.Int64()))
+		} else {
+			z.Rsh(x, uint(y.Int64()))
+		}
+	default:
+		return Value{}, invalid
+	}
+	return IntValue(z), nil
+}
+
+// floatOp は小数を含む数値の演算を誤差のない有理数で計算します
+func floatOp(n *Binary, x, y *big.Rat, invalid error) (Value, error) {
+	z := new(big.Rat)
+	switch n.Op {
+	case "+":
+		z.Add(x, y)
+	case "-":
+		z.Sub(x, y)
+	case "*":
+		z.Mul(x, y)
+	case "/":
+		if y.Sign() == 0 {
+			return Value{}, errorf(n.OpOffset, "division by zero")
+		}
+		z.Quo(x, y)
+	default:
+		return Value{}, invalid
+	}
+	return FloatValue(z), nil
+}
+
+// durationOp は期間を含む演算を計算します。
+// 期間どうしの加減算・剰余、期間と整数の乗算、期間の整数による除算、期間どうしの除算（結果は整数）ができます。
+func durationOp(n *Binary, x, y Value, invalid error) (Value, error) {
+	nanos := func(v Value) *big.Int { return big.NewInt(int64(v.Duration)) }
+	z := new(big.Int)
+	switch {
+	case x.Kind == KindDuration && y.Kind == KindDuration:
+		switch n.Op {
+		case "+":
+			z.Add(nanos(x), nanos(y))
+		case "-":
+			z.Sub(nanos(x), nanos(y))
+		case "/", "%":
+			if y.Duration == 0 {
+				return Value{}, errorf(n.OpOffset, "division by zero")
+			}
+			if n.Op == "/" {
+				return IntValue(z.Quo(nanos(x), nanos(y))), nil
+			}
+			z.Rem(nanos(x), nanos(y))
+		default:
+			return Value{}, invalid
+		}
+	case x.Kind == KindDuration && y.Kind == KindInt && (n.Op == "*" || n.Op == "/"):
+		if n.Op == "*" {
+			z.Mul(nanos(x), y.Int)
+		} else {
+			if y.Int.Sign() == 0 {
+				return Value{}, errorf(n.OpOffset, "division by zero")
+			}
+			z.Quo(nanos(x), y.Int)
+		}
+	case x.Kind == KindInt && y.Kind == KindDuration && n.Op == "*":
+		z.Mul(x.Int, nanos(y))
+	default:
+		return Value{}, invalid
+	}
+	return durationResult(n.OpOffset, z)
+}
+
+// durationResult はナノ秒の整数を期間の値にします。time.Duration の範囲を超える場合はエラーにします。
+func durationResult(offset int, nanos *big.Int) (Value, error) {
+	if !nanos.IsInt64() {
+		return Value{}, errorf(offset, "duration overflows (the maximum is about 290 years)")
+	}
+	return DurationValue(time.Duration(nanos.Int64())), nil
+}
+
+// evalCall は組み込み関数（min、max、len、upper）を呼び出します
+func evalCall(n *Call, env Env) (Value, error) {
+	args := make([]Value, len(n.Args))
+	for i, arg := range n.Args {
+		v, err := Eval(arg, env)
+		if err != nil {
+			return Value{}, err
+		}
+		args[i] = v
+	}
+
+	switch n.Func {
+	case "min", "max":
+		return evalMinMax(n, args)
+	case "len", "upper":
+		if len(args) != 1 {
+			return Value{}, errorf(n.Offset, "%s expects 1 argument, got %d", n.Func, len(args))
+		}
+		if args[0].Kind != KindString {
+			return Value{}, errorf(n.Args[0].Pos(), "%s expects a string, got %s", n.Func, args[0].Kind)
+		}
+		if n.Func == "len" {
+			return IntValue(big.NewInt(int64(utf8.RuneCountInString(args[0].Str)))), nil
+		}
+		return StringValue(strings.ToUpper(args[0].Str)), nil
+	}
+	return Value{}, errorf(n.Offset, "unknown function %q (expected min, max, len or upper)", n.Func)
+}
+
+// evalMinMax は引数の最小値・最大値を返します。
+// 引数は全て数値・全て期間・全て文字列のいずれかで、整数と小数が混ざる場合の結果は小数になります。
+func evalMinMax(n *Call, args []Value) (Value, error) {
+	if len(args) == 0 {
+		return Value{}, errorf(n.Offset, "%s expects at least 1 argument", n.Func)
+	}
+	result := args[0]
+	anyFloat := result.Kind == KindFloat
+	for i, arg := range args[1:] {
+		if arg.Kind == KindFloat {
+			anyFloat = true
+		}
+		c, ok := compare(arg, result, false)
+		if !ok || arg.Kind == KindBool {
+			return Value{}, errorf(n.Args[i+1].Pos(), "%s cannot compare %s with %s", n.Func, arg.Kind, result.Kind)
+		}
+		if (n.Func == "min" && c < 0) || (n.Func == "max" && c > 0) {
+			result = arg
+		}
+	}
+	if result.Kind == KindBool {
+		return Value{}, errorf(n.Args[0].Pos(), "%s cannot compare bool values", n.Func)
+	}
+	if anyFloat && result.Kind == KindInt {
+		result = FloatValue(result.Rat())
+	}
+	return result, nil
+}
@@ -0,0 +1,119 @@
+package expr
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testEnv はテスト用の定義名の値です
+var testEnv = map[string]Value{
+	"Base":    IntValue(big.NewInt(3)),
+	"Ratio":   FloatValue(big.NewRat(3, 2)),
+	"Prefix":  StringValue("api"),
+	"Timeout": DurationValue(30 * time.Second),
+	"Enabled": BoolValue(true),
+}
+
+func lookup(ident *Ident) (Value, error) {
+	if v, ok := testEnv[ident.Name]; ok {
+		return v, nil
+	}
+	return Value{}, errorf(ident.Offset, "undefined reference %q", ident.Name)
+}
+
+func TestEval(t *testing.T) {
+	tests := []struct {
+		src      string
+		kind     Kind
+		expected string
+	}{
+		{"1 + 2 * 3", KindInt, "7"},
+		{"(1 + 2) * 3", KindInt, "9"},
+		{"-Base * 2", KindInt, "-6"},
+		{"7 / 2", KindInt, "3"},
+		{"-7 / 2", KindInt, "-3"},
+		{"-7 % 3", KindInt, "-1"},
+		{"7 / 2.0", KindFloat, "3.5"},
+		{"0.1 + 0.2", KindFloat, "0.3"},
+		{"Ratio * 4", KindFloat, "6"},
+		{"1e3", KindInt, "1000"},
+		{"0xFF", KindInt, "255"},
+		{"1 << 10", KindInt, "1024"},
+		{"1 << 64", KindInt, "18446744073709551616"},
+		{"0x100 >> 4", KindInt, "16"},
+		{"Base >= 3 && Ratio < 2", KindBool, "true"},
+		{"!Enabled || Base == 4", KindBool, "false"},
+		{"Prefix + \"-\" + 'v2'", KindString, "api-v2"},
+		{"Prefix < \"b\"", KindBool, "true"},
+		{"upper(Prefix)", KindString, "API"},
+		{"len(\"こんにちは\")", KindInt, "5"},
+		{"min(Base, 2, 10)", KindInt, "2"},
+		{"max(Base, Ratio)", KindFloat, "3"},
+		{"max(Timeout, 1m)", KindDuration, "1m0s"},
+		{"min(\"b\", \"a\")", KindString, "a"},
+		{"Timeout * 2", KindDuration, "1m0s"},
+		{"2 * Timeout + 500ms", KindDuration, "1m0.5s"},
+		{"Timeout / 4", KindDuration, "7.5s"},
+		{"1h / Timeout", KindInt, "120"},
+		{"1h % 7m", KindDuration, "4m0s"},
+		{"-Timeout", KindDuration, "-30s"},
+		{"Timeout > 10s", KindBool, "true"},
+		{"false && Missing", KindBool, "false"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			n, err := Parse(tt.src)
+			if err != nil {
+				t.Fatalf("Parse(%q) unexpected error: %v", tt.src, err)
+			}
+			v, err := Eval(n, lookup)
+			if err != nil {
+				t.Fatalf("Eval(%q) unexpected error: %v", tt.src, err)
+			}
+			if v.Kind != tt.kind || v.String() != tt.expected {
+				t.Errorf("Eval(%q) = %s %s, expected %s %s", tt.src, v.Kind, v, tt.kind, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{"1 / 0", "column 3: division by zero"},
+		{"1.5 % 2", "column 5: invalid operation: float % int"},
+		{"Prefix * 2", "column 8: invalid operation: string * int"},
+		{"Prefix + Base", "column 8: invalid operation: string + int"},
+		{"Timeout * Ratio", "column 9: invalid operation: duration * float"},
+		{"Timeout + 1", "column 9: invalid operation: duration + int"},
+		{"Enabled < true", "column 9: invalid operation: bool < bool"},
+		{"-Prefix", "column 1: invalid operation: -string"},
+		{"1 << -1", "column 3: invalid shift count -1"},
+		{"Base && true", "column 6: invalid operation: int && bool"},
+		{"Missing + 1", `column 1: undefined reference "Missing"`},
+		{"len(Base)", "column 5: len expects a string, got int"},
+		{"upper()", "column 1: upper expects 1 argument, got 0"},
+		{"min()", "column 1: min expects at least 1 argument"},
+		{"max(1, \"a\")", "column 8: max cannot compare string with int"},
+		{"pow(2, 3)", `column 1: unknown function "pow"`},
+		{"2000000h * 1000", "column 10: duration overflows"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			n, err := Parse(tt.src)
+			if err != nil {
+				t.Fatalf("Parse(%q) unexpected error: %v", tt.src, err)
+			}
+			_, err = Eval(n, lookup)
+			if err == nil || !strings.HasPrefix(err.Error(), tt.expected) {
+				t.Errorf("Eval(%q) error = %v, expected %q", tt.src, err, tt.expected)
+			}
+		})
+	}
+}
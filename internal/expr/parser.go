@@ -0,0 +1,334 @@
+package expr
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Error は式の構文・評価のエラーです。Offset は式の中のエラーの位置です。
+type Error struct {
+	Offset int
+	Msg    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Offset+1, e.Msg)
+}
+
+// errorf は位置付きのエラーを作成します
+func errorf(offset int, format string, args ...any) *Error {
+	return &Error{Offset: offset, Msg: fmt.Sprintf(format, args...)}
+}
+
+// precedences は二項演算子の優先順位です（大きいほど先に結合します）
+var precedences = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"==": 3, "!=": 3, "<": 3, "<=": 3, ">": 3, ">=": 3,
+	"+": 4, "-": 4,
+	"*": 5, "/": 5, "%": 5, "<<": 5, ">>": 5,
+}
+
+// Parse は式を構文木に変換します
+func Parse(src string) (Node, error) {
+	p := &parser{lexer: lexer{src: src}}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	n, err := p.parseBinary(1)
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, errorf(p.tok.offset, "unexpected %s", p.tok)
+	}
+	return n, nil
+}
+
+// parser は字句解析器からトークンを1つ先読みしながら構文木を作ります
+type parser struct {
+	lexer lexer
+	tok   token
+}
+
+// next は次のトークンを読み込みます
+func (p *parser) next() error {
+	tok, err := p.lexer.scan()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+// expect は現在のトークンが演算子・記号 op であることを確認して読み進めます
+func (p *parser) expect(op string) error {
+	if p.tok.kind != tokOp || p.tok.text != op {
+		return errorf(p.tok.offset, "expected %q, got %s", op, p.tok)
+	}
+	return p.next()
+}
+
+// parseBinary は優先順位が minPrec 以上の二項演算子の式を左結合で解析します
+func (p *parser) parseBinary(minPrec int) (Node, error) {
+	x, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == tokOp {
+		op := p.tok
+		prec := precedences[op.text]
+		if prec == 0 || prec < minPrec {
+			break
+		}
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		y, err := p.parseBinary(prec + 1)
+		if err != nil {
+			return nil, err
+		}
+		x = &Binary{OpOffset: op.offset, Op: op.text, X: x, Y: y}
+	}
+	return x, nil
+}
+
+// parseUnary は単項演算子（- + !）の式を解析します
+func (p *parser) parseUnary() (Node, error) {
+	if p.tok.kind == tokOp && (p.tok.text == "-" || p.tok.text == "+" || p.tok.text == "!") {
+		op := p.tok
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &Unary{Offset: op.offset, Op: op.text, X: x}, nil
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary はリテラル・定義名の参照・関数呼び出し・括弧で囲んだ式を解析します
+func (p *parser) parsePrimary() (Node, error) {
+	tok := p.tok
+	switch tok.kind {
+	case tokNumber:
+		return &NumberLit{Offset: tok.offset, Text: tok.text}, p.next()
+	case tokDuration:
+		return &DurationLit{Offset: tok.offset, Text: tok.text, Value: tok.duration}, p.next()
+	case tokString:
+		return &StringLit{Offset: tok.offset, Value: tok.value}, p.next()
+	case tokIdent:
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		switch {
+		case tok.text == "true" || tok.text == "false":
+			return &BoolLit{Offset: tok.offset, Value: tok.text == "true"}, nil
+		case p.tok.kind == tokOp && p.tok.text == "(":
+			return p.parseCall(tok)
+		}
+		return &Ident{Offset: tok.offset, Name: tok.text}, nil
+	case tokOp:
+		if tok.text == "(" {
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+			x, err := p.parseBinary(1)
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return &Paren{Offset: tok.offset, X: x}, nil
+		}
+	}
+	return nil, errorf(tok.offset, "unexpected %s", tok)
+}
+
+// parseCall は関数名 name に続く引数リストを解析します
+func (p *parser) parseCall(name token) (Node, error) {
+	call := &Call{Offset: name.offset, Func: name.text}
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	for !(p.tok.kind == tokOp && p.tok.text == ")") {
+		arg, err := p.parseBinary(1)
+		if err != nil {
+			return nil, err
+		}
+		call.Args = append(call.Args, arg)
+		if p.tok.kind != tokOp || p.tok.text != "," {
+			break
+		}
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+	}
+	return call, p.expect(")")
+}
+
+// ============================================================================
+// 字句解析
+// ============================================================================
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokDuration
+	tokString
+	tokIdent
+	tokOp // 演算子と記号（括弧・カンマ）
+)
+
+// token は字句解析の結果の1トークンです
+type token struct {
+	kind     tokenKind
+	text     string        // 書かれた表記
+	offset   int           // 式の中の位置
+	value    string        // 文字列リテラルのエスケープを解釈した後の値
+	duration time.Duration // 期間リテラルの値
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of expression"
+	}
+	return strconv.Quote(t.text)
+}
+
+// operators は演算子と記号の一覧です（2文字のものを先に照合します）
+var operators = []string{
+	"<<", ">>", "==", "!=", "<=", ">=", "&&", "||",
+	"+", "-", "*", "/", "%", "<", ">", "!", "(", ")", ",",
+}
+
+// durationPattern は期間リテラル（Go の time.ParseDuration の形式）にマッチします
+var durationPattern = regexp.MustCompile(`^(\d+(\.\d+)?(ns|us|µs|ms|s|m|h))+`)
+
+// numberPattern は整数・小数・16進数のリテラルにマッチします
+var numberPattern = regexp.MustCompile(`^(0[xX][0-9a-fA-F]+|\d+(\.\d+)?([eE][+-]?\d+)?)`)
+
+// qualifiedPattern は定義ファイルのパスで修飾した定義名（path/to/file#Name）にマッチします
+var qualifiedPattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_./-]*#[A-Za-z_][A-Za-z0-9_]*`)
+
+// lexer は式の文字列をトークンに分割します
+type lexer struct {
+	src string
+	pos int
+}
+
+// scan は次のトークンを返します
+func (l *lexer) scan() (token, error) {
+	for l.pos < len(l.src) && (l.src[l.pos] == ' ' || l.src[l.pos] == '\t' || l.src[l.pos] == '\n' || l.src[l.pos] == '\r') {
+		l.pos++
+	}
+	start := l.pos
+	if start >= len(l.src) {
+		return token{kind: tokEOF, offset: start}, nil
+	}
+	rest := l.src[start:]
+	c := rest[0]
+
+	// パスで修飾した定義名は / や - を含むため、演算子より先に照合する
+	if m := qualifiedPattern.FindString(rest); m != "" {
+		l.pos += len(m)
+		return token{kind: tokIdent, text: m, offset: start}, nil
+	}
+
+	switch {
+	case isDigit(c):
+		return l.scanNumber()
+	case isIdentStart(c):
+		l.scanIdent()
+		// パッケージ名などで修飾した定義名（network.MaxRetries）
+		if l.pos+1 < len(l.src) && l.src[l.pos] == '.' && isIdentStart(l.src[l.pos+1]) {
+			l.pos++
+			l.scanIdent()
+		}
+		return token{kind: tokIdent, text: l.src[start:l.pos], offset: start}, nil
+	case c == '"' || c == '\'':
+		return l.scanString(c)
+	}
+	for _, op := range operators {
+		if strings.HasPrefix(rest, op) {
+			l.pos += len(op)
+			return token{kind: tokOp, text: op, offset: start}, nil
+		}
+	}
+	return token{}, errorf(start, "unexpected character %q", rest[:1])
+}
+
+// scanIdent は識別子を読み込みます
+func (l *lexer) scanIdent() {
+	for l.pos < len(l.src) && isIdentPart(l.src[l.pos]) {
+		l.pos++
+	}
+}
+
+// scanNumber は数値リテラルまたは期間リテラルを読み込みます
+func (l *lexer) scanNumber() (token, error) {
+	start := l.pos
+	rest := l.src[start:]
+	if m := durationPattern.FindString(rest); m != "" && !l.identFollows(start+len(m)) {
+		d, err := time.ParseDuration(m)
+		if err != nil {
+			return token{}, errorf(start, "invalid duration %q", m)
+		}
+		l.pos += len(m)
+		return token{kind: tokDuration, text: m, offset: start, duration: d}, nil
+	}
+	m := numberPattern.FindString(rest)
+	if l.identFollows(start + len(m)) {
+		end := start + len(m)
+		for end < len(l.src) && (isIdentPart(l.src[end]) || l.src[end] == '.') {
+			end++
+		}
+		return token{}, errorf(start, "invalid number %q", l.src[start:end])
+	}
+	l.pos += len(m)
+	return token{kind: tokNumber, text: m, offset: start}, nil
+}
+
+// identFollows は位置 i に識別子の文字が続くかどうかを返します
+func (l *lexer) identFollows(i int) bool {
+	return i < len(l.src) && (isIdentPart(l.src[i]) || l.src[i] == '.' || l.src[i] >= 0x80)
+}
+
+// scanString は quote で囲まれた文字列リテラルを読み込み、エスケープを解釈します
+func (l *lexer) scanString(quote byte) (token, error) {
+	start := l.pos
+	s := l.src[start+1:]
+	var b strings.Builder
+	for {
+		if s == "" {
+			return token{}, errorf(start, "unterminated string")
+		}
+		if s[0] == quote {
+			break
+		}
+		r, multibyte, tail, err := strconv.UnquoteChar(s, quote)
+		if err != nil {
+			return token{}, errorf(len(l.src)-len(s), "invalid escape in string")
+		}
+		if multibyte {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte(byte(r))
+		}
+		s = tail
+	}
+	l.pos = len(l.src) - len(s) + 1
+	return token{kind: tokString, text: l.src[start:l.pos], offset: start, value: b.String()}, nil
+}
+
+func isDigit(c byte) bool      { return '0' <= c && c <= '9' }
+func isIdentStart(c byte) bool { return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') }
+func isIdentPart(c byte) bool  { return isIdentStart(c) || isDigit(c) }
@@ -0,0 +1,94 @@
+package expr
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		src    string
+		idents []string
+	}{
+		{"1 + 2 * 3", nil},
+		{"(Base + 1) * -Factor", []string{"Base", "Factor"}},
+		{"min(A, max(B, 3), 1.5e3)", []string{"A", "B"}},
+		{"Prefix + '-' + \"v\\\"2\"", []string{"Prefix"}},
+		{"1h30m + 250ms * 2", nil},
+		{"Flags << 2 >= 0x10 && !Disabled || true", []string{"Flags", "Disabled"}},
+		{"len()", nil},
+		{"network.MaxRetries * 2", []string{"network.MaxRetries"}},
+		{"path/to/file-name#Limit - 1", []string{"path/to/file-name#Limit"}},
+		{"Total/Count", []string{"Total", "Count"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			n, err := Parse(tt.src)
+			if err != nil {
+				t.Fatalf("Parse(%q) unexpected error: %v", tt.src, err)
+			}
+			var names []string
+			for _, ident := range Idents(n) {
+				names = append(names, ident.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.idents, ",") {
+				t.Errorf("Idents(%q) = %v, expected %v", tt.src, names, tt.idents)
+			}
+		})
+	}
+}
+
+func TestParsePrecedence(t *testing.T) {
+	n, err := Parse("A + B * C == D || E && F")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	or, ok := n.(*Binary)
+	if !ok || or.Op != "||" {
+		t.Fatalf("Expected || at the root, got %#v", n)
+	}
+	and, ok := or.Y.(*Binary)
+	if !ok || and.Op != "&&" {
+		t.Fatalf("Expected && on the right of ||, got %#v", or.Y)
+	}
+	eq, ok := or.X.(*Binary)
+	if !ok || eq.Op != "==" {
+		t.Fatalf("Expected == on the left of ||, got %#v", or.X)
+	}
+	plus, ok := eq.X.(*Binary)
+	if !ok || plus.Op != "+" {
+		t.Fatalf("Expected + on the left of ==, got %#v", eq.X)
+	}
+	if mul, ok := plus.Y.(*Binary); !ok || mul.Op != "*" {
+		t.Errorf("Expected * on the right of +, got %#v", plus.Y)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{"", "column 1: unexpected end of expression"},
+		{"1 +", "column 4: unexpected end of expression"},
+		{"(1 + 2", `column 7: expected ")", got end of expression`},
+		{"1 2", `column 3: unexpected "2"`},
+		{"Base ** 2", `column 7: unexpected "*"`},
+		{"3x", `column 1: invalid number "3x"`},
+		{"30sec", `column 1: invalid number "30sec"`},
+		{`"abc`, "column 1: unterminated string"},
+		{"a # b", `column 3: unexpected character "#"`},
+		{"network.", `column 8: unexpected character "."`},
+		{"min(1,", "column 7: unexpected end of expression"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			_, err := Parse(tt.src)
+			if err == nil || err.Error() != tt.expected {
+				t.Errorf("Parse(%q) error = %v, expected %q", tt.src, err, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package expr
+
+import (
+	"math/big"
+	"strconv"
+	"time"
+)
+
+// Kind は式の値の種類です。
+type Kind int
+
+const (
+	KindInt      Kind = iota // 整数（桁数の制限なし）
+	KindFloat                // 小数（誤差のない有理数）
+	KindString               // 文字列
+	KindBool                 // 真偽値
+	KindDuration             // 期間
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindInt:
+		return "int"
+	case KindFloat:
+		return "float"
+	case KindString:
+		return "string"
+	case KindBool:
+		return "bool"
+	case KindDuration:
+		return "duration"
+	}
+	return "unknown"
+}
+
+// Value は式の値です。Kind に応じたフィールドだけが意味を持ちます。
+// 数値は Go の型なし定数と同じく誤差なく計算し、出力するときに宣言された型へ変換します。
+type Value struct {
+	Kind     Kind
+	Int      *big.Int
+	Float    *big.Rat
+	Str      string
+	Bool     bool
+	Duration time.Duration
+}
+
+// IntValue は整数の値を作ります
+func IntValue(n *big.Int) Value { return Value{Kind: KindInt, Int: n} }
+
+// FloatValue は小数の値を作ります
+func FloatValue(r *big.Rat) Value { return Value{Kind: KindFloat, Float: r} }
+
+// StringValue は文字列の値を作ります
+func StringValue(s string) Value { return Value{Kind: KindString, Str: s} }
+
+// BoolValue は真偽値の値を作ります
+func BoolValue(b bool) Value { return Value{Kind: KindBool, Bool: b} }
+
+// DurationValue は期間の値を作ります
+func DurationValue(d time.Duration) Value { return Value{Kind: KindDuration, Duration: d} }
+
+// IsNumber は整数または小数かどうかを返します
+func (v Value) IsNumber() bool {
+	return v.Kind == KindInt || v.Kind == KindFloat
+}
+
+// Rat は数値を有理数として返します
+func (v Value) Rat() *big.Rat {
+	if v.Kind == KindInt {
+		return new(big.Rat).SetInt(v.Int)
+	}
+	return v.Float
+}
+
+// FormatFloat は小数を bitSize ビットの浮動小数点数に丸めた最短の10進表記で返します
+func (v Value) FormatFloat(bitSize int) string {
+	f, _ := v.Rat().Float64()
+	return strconv.FormatFloat(f, 'g', -1, bitSize)
+}
+
+// String は値を文字列に埋め込むときの表記を返します
+func (v Value) String() string {
+	switch v.Kind {
+	case KindInt:
+		return v.Int.String()
+	case KindFloat:
+		return v.FormatFloat(64)
+	case KindString:
+		return v.Str
+	case KindBool:
+		return strconv.FormatBool(v.Bool)
+	case KindDuration:
+		return v.Duration.String()
+	}
+	return ""
+}
@@ -16,6 +16,12 @@ const (
 	HelpWatch          = "help_watch"
 	HelpNaming         = "help_naming"
 	HelpLocale         = "help_locale"
+	HelpFormat         = "help_format"
+	HelpExtensions     = "help_extensions"
+	HelpSymbolic       = "help_symbolic"
+	HelpDiff           = "help_diff"
+	HelpCheck          = "help_check"
+	HelpPrune          = "help_prune"
 )
 
 // helpLocale はヘルプメッセージ用のロケール設定を保持
@@ -25,34 +31,46 @@ var helpLocale string
 func GetHelpMessage(key string) string {
 	// デフォルト（英語）のヘルプメッセージ
 	defaultHelp := map[string]string{
-		HelpInputFile:   "JSON file or directory for constant definitions (uses first argument if not specified)",
+		HelpInputFile:   "Definition file (JSON, JSONC, JSON5, YAML or TOML) or directory for constant definitions (uses first argument if not specified)",
 		HelpOutputDir:   "Output directory (required)",
 		HelpTemplateDir: "Custom template directory path (uses KONST_TEMPLATES env var if omitted, or templates directory in same location as executable)",
 		HelpForce:       "Force overwrite existing files",
 		HelpIndent:      "Number of indents (default is 2)",
 		HelpVersion:     "Show version",
 		HelpMode:        "Specify output mode (go, ts)",
-		HelpValidate:    "Only validate JSON definitions (no code generation)",
-		HelpDryRun:      "Show list of files to be generated without actual generation",
-		HelpWatch:       "Monitor file changes for automatic generation (experimental feature)",
+		HelpValidate:    "Only validate definition files (no code generation)",
+		HelpDryRun:      "Show list of files to be generated (created, changed or unchanged) without actual generation",
+		HelpWatch:       "Monitor definition files and regenerate automatically on changes (requires -f)",
 		HelpNaming:      "File naming convention (kebab, camel, snake) - TypeScript defaults to kebab, Go defaults to snake",
 		HelpLocale:      "Language setting (ja, en) - uses KONST_LOCALE env var if not specified, then auto-detects system locale",
+		HelpFormat:      "Diagnostics output format (text, json, sarif) - json and sarif are written to stdout",
+		HelpExtensions:  "Comma-separated definition file extensions to read from directories (json, jsonc, json5, yaml, yml, toml) - all are read if omitted",
+		HelpSymbolic:    "Keep {{references}} as expressions of the referenced constants in generated code (the computed value is used when the expression cannot be represented)",
+		HelpDiff:        "Show a unified diff of each file to be generated against the existing file (implies --dry-run)",
+		HelpPrune:       "Delete files generated by a previous run that are no longer generated (files modified after generation are kept)",
+		HelpCheck:       "Check that the generated code is up to date without writing files, and exit with status 1 if there are stale, missing or orphaned files",
 	}
 
 	// 日本語のヘルプメッセージ
 	japaneseHelp := map[string]string{
-		HelpInputFile:   "定数定義のJSONファイルまたはディレクトリ（指定がなければ最初の引数を使用）",
+		HelpInputFile:   "定数定義ファイル（JSON, JSONC, JSON5, YAML, TOML）またはディレクトリ（指定がなければ最初の引数を使用）",
 		HelpOutputDir:   "出力先ディレクトリ（必須）",
 		HelpTemplateDir: "カスタムテンプレートディレクトリのパス（省略時は環境変数 KONST_TEMPLATES、なければ実行ファイルと同じ場所のtemplatesディレクトリを使用）",
 		HelpForce:       "既存ファイルを強制的に上書きする",
 		HelpIndent:      "インデント数（デフォルトは2）",
 		HelpVersion:     "バージョンを表示する",
 		HelpMode:        "出力モードを指定する（go, ts）",
-		HelpValidate:    "JSON定義の検証のみを行う（コード生成は行わない）",
-		HelpDryRun:      "実際の生成は行わず、生成予定のファイル一覧を表示する",
-		HelpWatch:       "ファイル変更を監視して自動生成する（実験的機能）",
+		HelpValidate:    "定義ファイルの検証のみを行う（コード生成は行わない）",
+		HelpDryRun:      "実際の生成は行わず、生成予定のファイル一覧（新規・変更・変更なし）を表示する",
+		HelpWatch:       "定義ファイルの変更を監視して自動的に再生成する（-f が必要）",
 		HelpNaming:      "ファイル命名規則（kebab, camel, snake）TypeScriptはデフォルトでkebab、Goはデフォルトでsnake",
 		HelpLocale:      "言語設定（ja, en）未指定時は環境変数KONST_LOCALE、次にシステムロケールを自動検出",
+		HelpFormat:      "診断の出力形式（text, json, sarif）json と sarif は標準出力に出力する",
+		HelpExtensions:  "ディレクトリから読み込む定義ファイルの拡張子をカンマ区切りで指定する（json, jsonc, json5, yaml, yml, toml）省略時は全て読み込む",
+		HelpSymbolic:    "{{参照}} を生成コードでも参照先の定数を使った式のまま出力する（式で表せない場合は計算した値を出力する）",
+		HelpDiff:        "生成予定のファイルと既存のファイルの差分を unified diff 形式で表示する（--dry-run を含む）",
+		HelpPrune:       "前回生成したファイルのうち、生成されなくなったファイルを削除する（生成後に書き換えられたファイルは削除しない）",
+		HelpCheck:       "ファイルを書き込まずに生成済みのコードが最新か確認し、古い・未生成・不要なファイルがあれば終了コード1で終了する",
 	}
 
 	// 初期化時に設定されたロケールを使用
@@ -26,6 +26,27 @@ const (
 	MsgFileError           MessageKey = "file_error"
 	MsgOutputRequired      MessageKey = "output_required"
 	MsgExecutablePathError MessageKey = "executable_path_error"
+	MsgWatchStarted        MessageKey = "watch_started"
+	MsgWatchChangeDetected MessageKey = "watch_change_detected"
+	MsgWatchRegenerated    MessageKey = "watch_regenerated"
+	MsgWatchStopped        MessageKey = "watch_stopped"
+	MsgWatchError          MessageKey = "watch_error"
+	MsgWatchRequiresForce  MessageKey = "watch_requires_force"
+	MsgValidationIssues    MessageKey = "validation_issues"
+	MsgFileCreated         MessageKey = "file_created"
+	MsgFileChanged         MessageKey = "file_changed"
+	MsgFileUnchanged       MessageKey = "file_unchanged"
+	MsgDryRunSummary       MessageKey = "dry_run_summary"
+	MsgCheckError          MessageKey = "check_error"
+	MsgCheckUpToDate       MessageKey = "check_up_to_date"
+	MsgCheckOutOfDate      MessageKey = "check_out_of_date"
+	MsgFileStale           MessageKey = "file_stale"
+	MsgFileMissing         MessageKey = "file_missing"
+	MsgFileOrphaned        MessageKey = "file_orphaned"
+	MsgFileRemoved         MessageKey = "file_removed"
+	MsgRemoved             MessageKey = "removed"
+	MsgPruneSkipped        MessageKey = "prune_skipped"
+	MsgGenerateSummary     MessageKey = "generate_summary"
 )
 
 // Messages は言語別のメッセージを管理する構造体
@@ -40,7 +61,7 @@ var defaultMessages = map[MessageKey]string{
 	MsgOutputDirectory:     "Output directory",
 	MsgFilesToBeGenerated:  "Files to be generated",
 	MsgGenerated:           "Generated",
-	MsgValidationSuccess:   "Validation successful: No issues found in JSON definitions",
+	MsgValidationSuccess:   "Validation successful: No issues found in definition files",
 	MsgInputMustBeDir:      "input must be a directory",
 	MsgOutputMustBeDir:     "output must be a directory",
 	MsgCmdArgError:         "Command line argument error",
@@ -51,6 +72,27 @@ var defaultMessages = map[MessageKey]string{
 	MsgFileError:           "file",
 	MsgOutputRequired:      "please specify output filename with -o option",
 	MsgExecutablePathError: "executable path error",
+	MsgWatchStarted:        "Watching for changes",
+	MsgWatchChangeDetected: "Change detected",
+	MsgWatchRegenerated:    "Regeneration completed",
+	MsgWatchStopped:        "Watch mode stopped",
+	MsgWatchError:          "Watch error",
+	MsgWatchRequiresForce:  "--watch regenerates over the files it generated; specify -f together with --watch",
+	MsgValidationIssues:    "%d problem(s) found in definitions",
+	MsgFileCreated:         "new",
+	MsgFileChanged:         "changed",
+	MsgFileUnchanged:       "unchanged",
+	MsgDryRunSummary:       "%d new, %d changed, %d unchanged",
+	MsgCheckError:          "Check error",
+	MsgCheckUpToDate:       "Generated code is up to date",
+	MsgCheckOutOfDate:      "Generated code is out of date (regenerate without --check)",
+	MsgFileStale:           "stale",
+	MsgFileMissing:         "missing",
+	MsgFileOrphaned:        "orphaned",
+	MsgFileRemoved:         "removed",
+	MsgRemoved:             "Removed",
+	MsgPruneSkipped:        "Not removed (modified after generation)",
+	MsgGenerateSummary:     "%d generated, %d unchanged, %d skipped",
 }
 
 var globalMessages *Messages
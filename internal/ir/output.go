@@ -0,0 +1,114 @@
+package ir
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/nantokaworks/konst/internal/diag"
+	"github.com/nantokaworks/konst/internal/types"
+	"github.com/nantokaworks/konst/internal/utils"
+)
+
+// Target は生成するコードの種類と出力先です
+type Target struct {
+	OutDir      string // 出力ディレクトリ
+	TS          bool   // TypeScript を生成するかどうか（false の場合は Go）
+	NamingStyle string // ファイル名の命名規則（空の場合は言語ごとのデフォルト）
+}
+
+// NewTarget はコマンドオプションから Target を作成します
+func NewTarget(outDir string, option *types.CommandOption, isTS bool) Target {
+	t := Target{OutDir: outDir, TS: isTS}
+	if option.NamingStyle != nil {
+		t.NamingStyle = *option.NamingStyle
+	}
+	return t
+}
+
+// OutputPath は定義ファイル f から生成するファイルのパスを返します
+func (t Target) OutputPath(f *File) string {
+	// ディレクトリとファイル名を分離
+	dir := filepath.Dir(f.Rel)
+	fileName := filepath.Base(f.Rel)
+	fileName = strings.TrimSuffix(fileName, filepath.Ext(fileName))
+
+	// ファイル名とディレクトリを命名規則に従って変換
+	convertedFileName := utils.ConvertFileName(fileName, t.NamingStyle, t.TS)
+	convertedDir := dir
+	if dir != "." {
+		convertedDir = utils.ConvertPath(dir, t.NamingStyle, t.TS)
+	}
+
+	// Goの場合、goPackageごとにサブディレクトリを作成
+	if !t.TS && f.Schema.GoPackage != "" {
+		return filepath.Join(t.OutDir, convertedDir, f.Schema.GoPackage, convertedFileName+t.Ext())
+	}
+	return filepath.Join(t.OutDir, convertedDir, convertedFileName+t.Ext())
+}
+
+// Conflicts は同じファイルに生成される定義ファイルを探し、問題の一覧を返します。
+// ファイル名の命名規則の変換で同じ名前になる定義ファイル（my-file.json と my_file.json など）や、
+// TypeScript で index.ts と同じパスに生成される定義ファイルは、後のファイルが先のファイルを上書きしてしまいます。
+func (t Target) Conflicts(files []*File) diag.List {
+	var list diag.List
+	owners := make(map[string]string) // 生成するファイルのパス → 定義ファイルのパス（index.ts の場合は空）
+	if t.TS {
+		owners[t.IndexPath()] = ""
+	}
+	for _, f := range files {
+		path := t.OutputPath(f)
+		other, exists := owners[path]
+		switch {
+		case !exists:
+			owners[path] = f.Path
+		case other == "":
+			list = append(list, diag.New(diag.CodeError, types.Position{File: f.Path}, "", "%s would generate %s, which is the index.ts that re-exports the generated files", f.Path, path))
+		default:
+			list = append(list, diag.New(diag.CodeError, types.Position{File: f.Path}, "", "%s and %s would generate the same output file %s", other, f.Path, path))
+		}
+	}
+	return list
+}
+
+// Mode は出力モードの名前（-m に指定する go または ts）を返します
+func (t Target) Mode() string {
+	if t.TS {
+		return "ts"
+	}
+	return "go"
+}
+
+// Ext は生成するファイルの拡張子を返します
+func (t Target) Ext() string {
+	if t.TS {
+		return ".ts"
+	}
+	return ".go"
+}
+
+// Namespace は定義ファイル src から生成するコードの名前空間を返します。同じ名前空間の定義名は衝突します。
+// Go は生成するパッケージのディレクトリ、TypeScript は index.ts が全てのファイルを再エクスポートするため index.ts です。
+func (t Target) Namespace(src utils.Source) string {
+	if t.TS {
+		return t.IndexPath()
+	}
+	f := &File{Rel: filepath.FromSlash(src.ID) + filepath.Ext(src.Path), Schema: src.Schema}
+	return filepath.Dir(t.OutputPath(f))
+}
+
+// IndexPath は TypeScript の生成ファイルを再エクスポートする index.ts のパスを返します。Go の場合は空文字列を返します
+func (t Target) IndexPath() string {
+	if !t.TS {
+		return ""
+	}
+	return filepath.Join(t.OutDir, "index.ts")
+}
+
+// ModulePath は index.ts から TypeScript の生成ファイル path を参照するモジュールパス（拡張子なし）を返します
+func (t Target) ModulePath(path string) (string, error) {
+	rel, err := filepath.Rel(t.OutDir, path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(filepath.ToSlash(rel), ".ts"), nil
+}
@@ -0,0 +1,167 @@
+// Package ir は入力ディレクトリの定義ファイルを一度だけ読み込み、検証と依存関係の解決を行った中間表現です。
+//
+// コードの生成とドライランはこの中間表現を使い、定義ファイルを読み直しません。
+package ir
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nantokaworks/konst/internal/diag"
+	"github.com/nantokaworks/konst/internal/types"
+	"github.com/nantokaworks/konst/internal/utils"
+	"github.com/nantokaworks/konst/internal/validator"
+)
+
+// Program は入力ディレクトリの全ての定義ファイルです
+type Program struct {
+	InputDir string
+	Files    []*File      // 定義ファイル（読み込み順）
+	Scope    *utils.Scope // 定義ファイルをまたいだ参照の解決
+
+	// Dependencies は定義から {{}} で参照している定義（と map の keyType の enum）への依存関係のグラフです
+	Dependencies map[utils.Symbol][]utils.Symbol
+
+	files map[string]*File // 定義ファイルのパス → 定義ファイル
+}
+
+// File は1つの定義ファイルです
+type File struct {
+	Path   string        // 定義ファイルのパス
+	Rel    string        // 入力ディレクトリからの相対パス
+	ID     string        // {{ID#Name}} で参照する名前（相対パスの / 区切り、拡張子なし）
+	Schema *types.Schema // 定義ファイルの内容。定義は {{式}} を展開した解決済みの定義
+	Hash   string        // 定義ファイルの内容のハッシュ
+}
+
+// Load は inputDir の定義ファイルを全て読み込み、検証して依存関係を解決します。
+// 定義内容の誤りは全ての定義ファイルについて集めてエラー（diag.List）として返し、生成を止めない警告は report に渡します。
+// 誤りがある場合も、問題のなかった定義ファイルだけを読み込んだ Program をエラーと共に返します（--validate が問題のなかった定義ファイルを表示するため）。
+func Load(inputDir string, report func(diag.List) error) (*Program, error) {
+	p := &Program{InputDir: inputDir, files: make(map[string]*File)}
+	var sources []utils.Source
+	var problems diag.List
+	// 拡張子違いの同名ファイル（foo.json と foo.yaml など）は同じ出力先になるため検出する
+	bases := make(map[string]string)
+
+	err := filepath.Walk(inputDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !utils.IsSchemaFile(path) {
+			return nil
+		}
+		base := strings.TrimSuffix(path, filepath.Ext(path))
+		if other, exists := bases[base]; exists {
+			problems = append(problems, diag.New(diag.CodeError, types.Position{File: path}, "", "%s and %s would generate the same output file", other, path))
+			return nil
+		}
+		bases[base] = path
+		// ハッシュはパースした内容から求める（読み直すと、その間に変更された内容のハッシュになる）
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		schema, err := utils.ParseSchemaData(path, data)
+		if err != nil {
+			problems = append(problems, diag.FromError(err)...)
+			return nil
+		}
+		sum := sha256.Sum256(data)
+		// 定義内容の誤り（table のキー列の重複など）があれば生成しない
+		issues := validator.ValidateSchema(schema)
+		if issues.HasErrors() {
+			problems = append(problems, issues...)
+			return nil
+		}
+		if err := report(issues); err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(inputDir, path)
+		if err != nil {
+			return err
+		}
+		src := utils.NewSource(inputDir, path, schema)
+		sources = append(sources, src)
+		f := &File{Path: path, Rel: rel, ID: src.ID, Schema: schema, Hash: hex.EncodeToString(sum[:])}
+		p.Files = append(p.Files, f)
+		p.files[path] = f
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 定義ファイルをまたいで依存関係を解決（問題のあった定義ファイルを除いても、残りの定義ファイルの問題は報告する）
+	// 同じ名前の定義が衝突するかは出力先によるため、生成時に Target.Namespace で検証する
+	p.Scope = utils.NewScope(sources)
+	resolution, warnings, err := utils.ResolveScope(p.Scope)
+	if err := report(warnings); err != nil {
+		return nil, err
+	}
+	if err != nil {
+		problems = append(problems, diag.FromError(err)...)
+	}
+	if len(problems) > 0 {
+		problems.Sort()
+		return p, problems
+	}
+	p.Dependencies = resolution.Dependencies
+
+	// 定義を解決済みの定義で置き換える（参照の解決には元の定義を使うため、Scope とは別のスキーマにする）
+	for _, f := range p.Files {
+		schema := *f.Schema
+		schema.Definitions = resolution.Definitions[f.Path]
+		f.Schema = &schema
+	}
+	return p, nil
+}
+
+// File は定義ファイルのパスの定義ファイルを返します。見つからない場合は nil を返します
+func (p *Program) File(path string) *File {
+	return p.files[path]
+}
+
+// DependencyFiles は定義ファイル f の定義が {{}} で直接・間接に参照している定義ファイル（f 自身を除く）をパス順に返します
+func (p *Program) DependencyFiles(f *File) []*File {
+	seen := make(map[utils.Symbol]bool)
+	var stack []utils.Symbol
+	for name := range f.Schema.Definitions {
+		stack = append(stack, utils.Symbol{File: f.Path, Name: name})
+	}
+	files := make(map[string]bool)
+	for len(stack) > 0 {
+		sym := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if seen[sym] {
+			continue
+		}
+		seen[sym] = true
+		if sym.File != f.Path {
+			files[sym.File] = true
+		}
+		stack = append(stack, p.Dependencies[sym]...)
+	}
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	deps := make([]*File, len(paths))
+	for i, path := range paths {
+		deps[i] = p.files[path]
+	}
+	return deps
+}
+
+// Definition は解決済みの定義を返します
+func (p *Program) Definition(sym utils.Symbol) (types.Definition, bool) {
+	f, ok := p.files[sym.File]
+	if !ok {
+		return types.Definition{}, false
+	}
+	def, ok := f.Schema.Definitions[sym.Name]
+	return def, ok
+}
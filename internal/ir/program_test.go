@@ -0,0 +1,191 @@
+package ir
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nantokaworks/konst/internal/diag"
+	"github.com/nantokaworks/konst/internal/types"
+	"github.com/nantokaworks/konst/internal/utils"
+)
+
+// writeSchemas はテスト用の定義ファイルを作成します
+func writeSchemas(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	writeSchemas(t, dir, map[string]string{
+		"network/network.json": `{"version": "1.0", "goPackage": "network", "definitions": {"MaxRetries": {"type": "int", "value": 5}}}`,
+		"app.json":             `{"version": "1.0", "goPackage": "app", "definitions": {"MaxRetries": {"type": "int", "value": "{{network.MaxRetries}} * 2"}}}`,
+	})
+
+	var warnings diag.List
+	prog, err := Load(dir, func(list diag.List) error {
+		warnings = append(warnings, list...)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(prog.Files) != 2 {
+		t.Fatalf("Expected 2 files, got %d", len(prog.Files))
+	}
+	if len(warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", warnings)
+	}
+
+	app := prog.File(filepath.Join(dir, "app.json"))
+	if app == nil || app.ID != "app" || app.Schema.Definitions["MaxRetries"].Value != json.Number("10") {
+		t.Fatalf("Unexpected app.json: %+v", app)
+	}
+	network := utils.Symbol{File: filepath.Join(dir, "network", "network.json"), Name: "MaxRetries"}
+	if def, ok := prog.Definition(network); !ok || def.Value != json.Number("5") {
+		t.Errorf("network MaxRetries = %#v, expected 5", def.Value)
+	}
+	deps := prog.Dependencies[utils.Symbol{File: app.Path, Name: "MaxRetries"}]
+	if len(deps) != 1 || deps[0] != network {
+		t.Errorf("Dependencies = %v, expected [%v]", deps, network)
+	}
+	if files := prog.DependencyFiles(app); len(files) != 1 || files[0].Path != network.File {
+		t.Errorf("DependencyFiles = %v, expected [%s]", files, network.File)
+	}
+	if files := prog.DependencyFiles(prog.File(network.File)); len(files) != 0 {
+		t.Errorf("DependencyFiles = %v, expected none", files)
+	}
+	if app.Hash == "" {
+		t.Error("Expected the content hash of app.json")
+	}
+}
+
+func TestLoadSameOutputFile(t *testing.T) {
+	dir := t.TempDir()
+	writeSchemas(t, dir, map[string]string{
+		"config.json": `{"version": "1.0", "definitions": {"A": {"type": "int", "value": 1}}}`,
+		"config.yaml": "version: \"1.0\"\ndefinitions:\n  B:\n    type: int\n    value: 2\n",
+	})
+	if _, err := Load(dir, func(diag.List) error { return nil }); err == nil {
+		t.Error("Expected error for files generating the same output file")
+	}
+}
+
+func TestLoadProblems(t *testing.T) {
+	dir := t.TempDir()
+	writeSchemas(t, dir, map[string]string{
+		"a.json":   `{"version": "1.0", "definitions": {"A": {"type": "int", "value": 1}}}`,
+		"a.yaml":   "version: \"1.0\"\ndefinitions:\n  B:\n    type: int\n    value: 2\n",
+		"bad.json": `{"version": "1.0", "definitions": {"C": {"type": "int", "value": "x"}}}`,
+		"ref.json": `{"version": "1.0", "definitions": {"D": {"type": "int", "value": "{{Missing}}"}}}`,
+	})
+
+	// 最初の問題で止まらず、全ての定義ファイルの問題を返す
+	prog, err := Load(dir, func(diag.List) error { return nil })
+	issues := diag.Flatten(err)
+	if len(issues) != 3 {
+		t.Fatalf("Load() error = %v, expected 3 issues", err)
+	}
+	for i, file := range []string{"a.yaml", "bad.json", "ref.json"} {
+		if issues[i].Pos.File != filepath.Join(dir, file) {
+			t.Errorf("issues[%d] is in %s, expected %s", i, issues[i].Pos.File, file)
+		}
+	}
+	// 問題のなかった定義ファイルは読み込む
+	if prog == nil || len(prog.Files) != 2 || prog.Files[0].Rel != "a.json" || prog.Files[1].Rel != "ref.json" {
+		t.Fatalf("Expected a.json and ref.json to be loaded, got %+v", prog)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "a.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum := sha256.Sum256(data); prog.Files[0].Hash != hex.EncodeToString(sum[:]) {
+		t.Errorf("Hash = %s, expected the hash of the parsed content", prog.Files[0].Hash)
+	}
+}
+
+func TestTargetNamespace(t *testing.T) {
+	schema := &types.Schema{GoPackage: "settings"}
+	a := utils.NewSource("defs", filepath.Join("defs", "user", "a.json"), schema)
+	b := utils.NewSource("defs", filepath.Join("defs", "user", "b.yaml"), schema)
+	c := utils.NewSource("defs", filepath.Join("defs", "c.json"), schema)
+
+	goTarget := Target{OutDir: "out"}
+	if goTarget.Namespace(a) != goTarget.Namespace(b) || goTarget.Namespace(a) == goTarget.Namespace(c) {
+		t.Errorf("Expected Go namespaces to be package directories, got %q, %q, %q", goTarget.Namespace(a), goTarget.Namespace(b), goTarget.Namespace(c))
+	}
+	tsTarget := Target{OutDir: "out", TS: true}
+	if tsTarget.Namespace(a) != tsTarget.Namespace(c) {
+		t.Errorf("Expected one TypeScript namespace, got %q, %q", tsTarget.Namespace(a), tsTarget.Namespace(c))
+	}
+}
+
+func TestTargetConflicts(t *testing.T) {
+	file := func(rel, pkg string) *File {
+		return &File{Path: filepath.Join("defs", rel), Rel: rel, Schema: &types.Schema{GoPackage: pkg}}
+	}
+	tests := []struct {
+		name     string
+		target   Target
+		files    []*File
+		expected []string
+	}{
+		{"Different files", Target{OutDir: "out"}, []*File{file("a.json", "a"), file("b.json", "a")}, nil},
+		{"Same name after conversion", Target{OutDir: "out"}, []*File{file("my-file.json", "a"), file("my_file.json", "a")},
+			[]string{"defs/my-file.json and defs/my_file.json would generate the same output file out/a/my_file.go"}},
+		{"Same name in different packages", Target{OutDir: "out"}, []*File{file("my-file.json", "a"), file("my_file.json", "b")}, nil},
+		{"TypeScript kebab-case", Target{OutDir: "out", TS: true}, []*File{file("myFile.json", ""), file("my_file.json", "")},
+			[]string{"defs/myFile.json and defs/my_file.json would generate the same output file out/my-file.ts"}},
+		{"TypeScript index.ts", Target{OutDir: "out", TS: true}, []*File{file("index.json", "")},
+			[]string{"defs/index.json would generate out/index.ts, which is the index.ts"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := tt.target.Conflicts(tt.files)
+			if len(issues) != len(tt.expected) {
+				t.Fatalf("Conflicts() = %v, expected %d issues", issues, len(tt.expected))
+			}
+			for i, expected := range tt.expected {
+				if !strings.Contains(filepath.ToSlash(issues[i].Message), expected) {
+					t.Errorf("issue %d = %q, expected to contain %q", i, issues[i].Message, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestTargetOutputPath(t *testing.T) {
+	file := &File{Rel: filepath.Join("user_settings", "retry_policy.json"), Schema: &types.Schema{GoPackage: "settings"}}
+
+	tests := []struct {
+		name     string
+		target   Target
+		expected string
+	}{
+		{"Go with goPackage", Target{OutDir: "out"}, filepath.Join("out", "user_settings", "settings", "retry_policy.go")},
+		{"TypeScript", Target{OutDir: "out", TS: true}, filepath.Join("out", "user-settings", "retry-policy.ts")},
+		{"TypeScript camelCase", Target{OutDir: "out", TS: true, NamingStyle: "camel"}, filepath.Join("out", "userSettings", "retryPolicy.ts")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := tt.target.OutputPath(file); result != tt.expected {
+				t.Errorf("OutputPath() = %q, expected %q", result, tt.expected)
+			}
+		})
+	}
+}
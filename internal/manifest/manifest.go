@@ -0,0 +1,121 @@
+// Package manifest は konst が出力先に生成したファイルを記録するマニフェストです。
+//
+// マニフェストに記録されたファイルだけを konst が作成したファイルとみなし、
+// 定義ファイルの削除や名前の変更で生成されなくなったファイルの削除（prune）に使います。
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// FileName は出力先ディレクトリに置くマニフェストのファイル名です
+const FileName = ".konst-manifest.json"
+
+// Manifest は生成したファイルの一覧です
+type Manifest struct {
+	Version string  `json:"version"` // 生成した konst のバージョン
+	Files   []Entry `json:"files"`   // 生成したファイル（パス順）
+}
+
+// Entry は生成した1つのファイルです
+type Entry struct {
+	Path   string `json:"path"`             // 出力先ディレクトリからの相対パス（/ 区切り）
+	Source string `json:"source,omitempty"` // 生成元の定義ファイルのパス（/ 区切り）。index.ts など定義ファイルに対応しない場合は空
+	Hash   string `json:"hash"`             // 生成した内容のハッシュ
+	Input  string `json:"input,omitempty"`  // 内容を決める入力のハッシュ。同じであれば次の生成で生成を省略できる
+	Mode   string `json:"mode,omitempty"`   // 生成したときの出力モード（go または ts）。同じ出力先に両方のモードで生成できる
+}
+
+// Hash はファイルの内容のハッシュを返します
+func Hash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// Load は出力先ディレクトリ outDir のマニフェストを読み込みます。マニフェストがない場合は空のマニフェストを返します
+func Load(outDir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(outDir, FileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Manifest{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	for _, e := range m.Files {
+		if _, err := filePath(outDir, e); err != nil {
+			return nil, fmt.Errorf("invalid manifest %s: %w", filepath.Join(outDir, FileName), err)
+		}
+	}
+	return &m, nil
+}
+
+// Marshal はマニフェストをファイルに書き込む内容に変換します。ファイルはパス順に並べます
+func (m *Manifest) Marshal() ([]byte, error) {
+	sort.Slice(m.Files, func(i, j int) bool { return m.Files[i].Path < m.Files[j].Path })
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// Stat は記録されたファイル e が出力先ディレクトリ outDir にあるかどうかと、生成後に書き換えられているかどうかを返します
+func Stat(outDir string, e Entry) (exists, modified bool, err error) {
+	path, err := filePath(outDir, e)
+	if err != nil {
+		return false, false, err
+	}
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, err
+	}
+	return true, Hash(content) != e.Hash, nil
+}
+
+// Remove は記録されたファイル e を出力先ディレクトリ outDir から削除し、空になったディレクトリも削除します。
+// outDir 自体は削除しません。
+func Remove(outDir string, e Entry) error {
+	path, err := filePath(outDir, e)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+	root := filepath.Clean(outDir)
+	for dir := filepath.Dir(path); dir != root && dir != "." && dir != string(filepath.Separator); dir = filepath.Dir(dir) {
+		entries, err := os.ReadDir(dir)
+		if err != nil || len(entries) > 0 {
+			break
+		}
+		if err := os.Remove(dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// filePath は記録されたファイル e の出力先ディレクトリ outDir の中のパスを返します。
+// マニフェストは手で書き換えられることがあるため、絶対パスや .. で outDir の外を指すパスはエラーにします
+func filePath(outDir string, e Entry) (string, error) {
+	rel := filepath.FromSlash(e.Path)
+	if !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("path %q in the manifest is outside the output directory %s", e.Path, outDir)
+	}
+	return filepath.Join(outDir, rel), nil
+}
@@ -0,0 +1,147 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMarshalLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	// マニフェストがない場合は空
+	m, err := Load(dir)
+	if err != nil || len(m.Files) != 0 {
+		t.Fatalf("Load() = %+v, %v, expected empty manifest", m, err)
+	}
+
+	m = &Manifest{Version: "v1", Files: []Entry{
+		{Path: "index.ts", Hash: Hash([]byte("index"))},
+		{Path: "app.ts", Source: "defs/app.json", Hash: Hash([]byte("app"))},
+	}}
+	data, err := m.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, FileName), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(loaded.Files) != 2 || loaded.Files[0].Path != "app.ts" || loaded.Files[1].Path != "index.ts" {
+		t.Errorf("Expected files sorted by path, got %+v", loaded.Files)
+	}
+	if loaded.Version != "v1" || loaded.Files[0].Source != "defs/app.json" {
+		t.Errorf("Unexpected manifest: %+v", loaded)
+	}
+}
+
+func TestLoadRejectsOutsidePaths(t *testing.T) {
+	tests := []struct {
+		name  string
+		path  string
+		valid bool
+	}{
+		{"Relative", "a/app.ts", true},
+		{"Cleaned inside", "a/../app.ts", true},
+		{"Parent", "../app.ts", false},
+		{"Nested parent", "a/../../app.ts", false},
+		{"Absolute", "/etc/passwd", false},
+		{"Empty", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			data, err := (&Manifest{Files: []Entry{{Path: tt.path}}}).Marshal()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, FileName), data, 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := Load(dir); (err == nil) != tt.valid {
+				t.Errorf("Load() error = %v, expected valid %v", err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestStat(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "app.ts"), []byte("app"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		entry    Entry
+		exists   bool
+		modified bool
+	}{
+		{"Unmodified", Entry{Path: "app.ts", Hash: Hash([]byte("app"))}, true, false},
+		{"Modified", Entry{Path: "app.ts", Hash: Hash([]byte("old"))}, true, true},
+		{"Missing", Entry{Path: "gone.ts", Hash: Hash([]byte("gone"))}, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exists, modified, err := Stat(dir, tt.entry)
+			if err != nil || exists != tt.exists || modified != tt.modified {
+				t.Errorf("Stat() = %v, %v, %v, expected %v, %v", exists, modified, err, tt.exists, tt.modified)
+			}
+		})
+	}
+}
+
+func TestRemove(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a/b/old.go", "a/keep.go"} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := Remove(dir, Entry{Path: "a/b/old.go"}); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	// 空になったディレクトリは削除し、他のファイルがあるディレクトリは残す
+	if _, err := os.Stat(filepath.Join(dir, "a", "b")); !os.IsNotExist(err) {
+		t.Errorf("Expected empty directory a/b to be removed, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a", "keep.go")); err != nil {
+		t.Errorf("Expected a/keep.go to be kept: %v", err)
+	}
+}
+
+func TestRemoveOutsidePaths(t *testing.T) {
+	root := t.TempDir()
+	outDir := filepath.Join(root, "out")
+	outside := filepath.Join(root, "outside.go")
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(outside, []byte("outside"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// 出力先ディレクトリの外を指すパスは削除も参照もしない
+	for _, path := range []string{"../outside.go", filepath.ToSlash(outside)} {
+		e := Entry{Path: path, Hash: Hash([]byte("outside"))}
+		if err := Remove(outDir, e); err == nil {
+			t.Errorf("Expected Remove(%q) to fail", path)
+		}
+		if _, _, err := Stat(outDir, e); err == nil {
+			t.Errorf("Expected Stat(%q) to fail", path)
+		}
+	}
+	if _, err := os.Stat(outside); err != nil {
+		t.Errorf("Expected %s to be kept: %v", outside, err)
+	}
+}
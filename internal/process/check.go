@@ -0,0 +1,50 @@
+package process
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/nantokaworks/konst/internal/ir"
+	"github.com/nantokaworks/konst/internal/manifest"
+)
+
+// CheckResult は生成済みのコードと定義ファイルから生成するコードの違いです
+type CheckResult struct {
+	Stale    []string // 内容が古いファイル
+	Missing  []string // 生成されていないファイル
+	Orphaned []string // 対応する定義ファイルがなくなったファイル
+}
+
+// UpToDate は生成済みのコードが最新かどうかを返します
+func (r CheckResult) UpToDate() bool {
+	return len(r.Stale) == 0 && len(r.Missing) == 0 && len(r.Orphaned) == 0
+}
+
+// Check は生成するファイル outputs と出力先のファイルを比較します。ファイルは書き込みません。
+// 前回の生成のマニフェスト prev に記録されたファイルのうち、outputs に含まれず出力先に残っているものを不要なファイルとみなします。
+// マニフェストに記録されていないファイルは konst が生成したものではないため、出力先にあっても不要なファイルには含めません。
+func Check(outputs []Output, target ir.Target, prev *manifest.Manifest) (CheckResult, error) {
+	var result CheckResult
+	for _, out := range outputs {
+		change, _, err := out.Compare()
+		if err != nil {
+			return CheckResult{}, err
+		}
+		switch change {
+		case Created:
+			result.Missing = append(result.Missing, out.Path)
+		case Changed:
+			result.Stale = append(result.Stale, out.Path)
+		}
+	}
+
+	leftovers, err := Leftovers(target, prev, outputs)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	for _, l := range leftovers {
+		result.Orphaned = append(result.Orphaned, filepath.Join(target.OutDir, filepath.FromSlash(l.Path)))
+	}
+	sort.Strings(result.Orphaned)
+	return result, nil
+}
@@ -0,0 +1,100 @@
+package process
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/nantokaworks/konst/internal/manifest"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		change   func(t *testing.T, inDir, outDir string)
+		expected func(outDir string) CheckResult
+	}{
+		{
+			name:     "Up to date",
+			expected: func(string) CheckResult { return CheckResult{} },
+		},
+		{
+			name: "Stale",
+			change: func(t *testing.T, inDir, outDir string) {
+				writeSchemas(t, inDir, map[string]string{"a.json": schema("a", "2")})
+			},
+			expected: func(outDir string) CheckResult {
+				return CheckResult{Stale: []string{filepath.Join(outDir, "a", "a.go")}}
+			},
+		},
+		{
+			name: "Missing",
+			change: func(t *testing.T, inDir, outDir string) {
+				if err := os.Remove(filepath.Join(outDir, "a", "a.go")); err != nil {
+					t.Fatal(err)
+				}
+			},
+			expected: func(outDir string) CheckResult {
+				return CheckResult{Missing: []string{filepath.Join(outDir, "a", "a.go")}}
+			},
+		},
+		{
+			name: "Orphaned",
+			change: func(t *testing.T, inDir, outDir string) {
+				if err := os.Remove(filepath.Join(inDir, "b.json")); err != nil {
+					t.Fatal(err)
+				}
+			},
+			expected: func(outDir string) CheckResult {
+				return CheckResult{Orphaned: []string{filepath.Join(outDir, "b", "b.go")}}
+			},
+		},
+		{
+			name: "Orphaned after modification",
+			change: func(t *testing.T, inDir, outDir string) {
+				if err := os.Remove(filepath.Join(inDir, "b.json")); err != nil {
+					t.Fatal(err)
+				}
+				writeSchemas(t, outDir, map[string]string{"b/b.go": "package b\n"})
+			},
+			expected: func(outDir string) CheckResult {
+				return CheckResult{Orphaned: []string{filepath.Join(outDir, "b", "b.go")}}
+			},
+		},
+		{
+			name: "Hand-written file is not orphaned",
+			change: func(t *testing.T, inDir, outDir string) {
+				writeSchemas(t, outDir, map[string]string{"a/helper.go": "package a\n", "doc.go": "package generated\n"})
+			},
+			expected: func(string) CheckResult { return CheckResult{} },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inDir, outDir := t.TempDir(), t.TempDir()
+			writeSchemas(t, inDir, map[string]string{"a.json": schema("a", "1"), "b.json": schema("b", "1")})
+			target := generate(t, inDir, outDir, testOption(false, false))
+			if tt.change != nil {
+				tt.change(t, inDir, outDir)
+			}
+
+			outputs, err := Plan(load(t, inDir), target, testOption(false, false), nil)
+			if err != nil {
+				t.Fatalf("Plan failed: %v", err)
+			}
+			prev, err := manifest.Load(outDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := Check(outputs, target, prev)
+			if err != nil {
+				t.Fatalf("Check failed: %v", err)
+			}
+			if expected := tt.expected(outDir); !reflect.DeepEqual(result, expected) {
+				t.Errorf("Check() = %+v, expected %+v", result, expected)
+			}
+		})
+	}
+}
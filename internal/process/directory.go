@@ -1,73 +1,156 @@
 package process
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
-	"strings"
 
+	"github.com/nantokaworks/konst/internal/diag"
 	"github.com/nantokaworks/konst/internal/i18n"
+	"github.com/nantokaworks/konst/internal/ir"
+	"github.com/nantokaworks/konst/internal/manifest"
 	"github.com/nantokaworks/konst/internal/types"
 	"github.com/nantokaworks/konst/internal/utils"
 )
 
-// ProcessDirectory はディレクトリ内のJSONファイルを再帰的に処理します。
-func ProcessDirectory(inputDir, outDir string, option *types.CommandOption, isTS bool) error {
-	// まず全JSONファイルを読み込んで依存関係を解決
-	allDefinitions := make(map[string]types.Definition)
-	var jsonFiles []string
-	
-	err := filepath.Walk(inputDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil || info.IsDir() || !strings.HasSuffix(info.Name(), ".json") {
-			return nil
-		}
-		jsonFiles = append(jsonFiles, path)
-		schema, err := utils.ParseSchemaFile(&path)
-		if err != nil {
-			return err
-		}
-		// 全定義をマージ
-		for name, def := range schema.Definitions {
-			allDefinitions[name] = def
-		}
+// Progress は生成状況のメッセージの出力先です。
+// 診断を機械可読な形式で標準出力に書く場合は標準エラー出力に切り替えます。
+var Progress io.Writer = os.Stdout
+
+// Report は生成を止めない診断（警告）の出力先です。nil の場合は出力しません。
+var Report func(diag.List) error
+
+// report は警告を Report に出力します
+func report(warnings diag.List) error {
+	if Report == nil || len(warnings) == 0 {
 		return nil
-	})
+	}
+	return Report(warnings)
+}
+
+// ProcessDirectory はディレクトリ内の定義ファイルを再帰的に処理します。
+func ProcessDirectory(inputDir, outDir string, option *types.CommandOption, isTS bool) error {
+	// 全定義ファイルを一度だけ読み込んで依存関係を解決
+	prog, err := ir.Load(inputDir, report)
 	if err != nil {
 		return err
 	}
+	return Generate(prog, ir.NewTarget(outDir, option, isTS), option)
+}
 
-	// 依存関係を解決
-	resolvedDefinitions, err := utils.ResolveDependencies(allDefinitions)
+// Generate は読み込んだ定義ファイルからコードを生成します。
+// 全てのファイルの内容を求めてから一時ファイルに書き込み、最後にまとめて名前を変更するため、
+// 生成や書き込みに失敗した場合は前回の出力を変更しません。名前の変更の途中で失敗した場合は、置き換えたファイルを元に戻します。
+// 前回の生成から入力が変わっていないファイルは生成を省略し、内容が変わったファイルだけを書き込みます。
+func Generate(prog *ir.Program, target ir.Target, option *types.CommandOption) error {
+	prev, err := manifest.Load(target.OutDir)
+	if err != nil {
+		return err
+	}
+	outputs, err := Plan(prog, target, option, prev)
 	if err != nil {
 		return err
 	}
 
-	var tsExports []string
-	// 各JSONファイルを処理
-	for _, jsonPath := range jsonFiles {
-		exportPath, err := ProcessFileWithResolvedDependencies(jsonPath, inputDir, outDir, option, isTS, resolvedDefinitions)
+	var w utils.OutputWriter
+	defer w.Abort()
+	var written []string
+	var unchanged, skipped int
+	for _, out := range outputs {
+		if out.Skipped {
+			skipped++
+			continue
+		}
+		// 内容が同じファイルは書き込まない（更新日時を変えず、ツールチェーンの再ビルドを起こさない）
+		change, _, err := out.Compare()
 		if err != nil {
 			return err
 		}
-		if isTS && exportPath != "" {
-			tsExports = append(tsExports, exportPath)
+		if change == Unchanged {
+			unchanged++
+			continue
 		}
-	}
-	// TS出力の場合、index.tsを生成
-	if isTS {
-		indexPath := filepath.Join(outDir, "index.ts")
-		f, err := os.Create(indexPath)
-		if err != nil {
+		// index.ts は生成したファイルの一覧なので常に上書きする
+		force := out.Source == "" || (option.Force != nil && *option.Force)
+		if err := w.Add(out.Path, out.Content, force); err != nil {
 			return err
 		}
-		defer f.Close()
-		for _, export := range tsExports {
-			line := fmt.Sprintf("export * from './%s';\n", export)
-			if _, err := f.WriteString(line); err != nil {
-				return err
-			}
+		written = append(written, out.Path)
+	}
+
+	// 前回生成して今回は生成しないファイルを、prune する場合は削除し、しない場合はマニフェストに残す
+	leftovers, err := Leftovers(target, prev, outputs)
+	if err != nil {
+		return err
+	}
+	prune := option.Prune != nil && *option.Prune
+	var kept []manifest.Entry
+	var removals []manifest.Entry
+	for _, l := range leftovers {
+		switch {
+		case !prune:
+			kept = append(kept, l.Entry)
+		case l.Modified:
+			// 生成後に書き換えられたファイルは konst が作成した内容ではないため削除しない
+			fmt.Fprintf(Progress, "%s: %s\n", i18n.T(i18n.MsgPruneSkipped), filepath.Join(target.OutDir, filepath.FromSlash(l.Path)))
+			kept = append(kept, l.Entry)
+		default:
+			removals = append(removals, l.Entry)
+		}
+	}
+
+	// マニフェストも生成したファイルと一緒に書き込む
+	if err := addManifest(&w, target, outputs, kept, prev); err != nil {
+		return err
+	}
+
+	if err := w.Commit(); err != nil {
+		return err
+	}
+	for _, path := range written {
+		fmt.Fprintf(Progress, "%s: %s\n", i18n.T(i18n.MsgGenerated), path)
+	}
+	var errs []error
+	for _, e := range removals {
+		if err := manifest.Remove(target.OutDir, e); err != nil {
+			// 削除できなかったファイルは出力先に残るため、次の生成で削除できるようマニフェストに残す
+			kept = append(kept, e)
+			errs = append(errs, err)
+			continue
+		}
+		fmt.Fprintf(Progress, "%s: %s\n", i18n.T(i18n.MsgRemoved), filepath.Join(target.OutDir, filepath.FromSlash(e.Path)))
+	}
+	if len(errs) > 0 {
+		var mw utils.OutputWriter
+		defer mw.Abort()
+		if err := addManifest(&mw, target, outputs, kept, prev); err != nil {
+			errs = append(errs, err)
+		} else if err := mw.Commit(); err != nil {
+			errs = append(errs, err)
 		}
-		fmt.Printf("%s: %s\n", i18n.T(i18n.MsgGenerated), indexPath)
+		return errors.Join(errs...)
 	}
+	fmt.Fprintf(Progress, i18n.T(i18n.MsgGenerateSummary)+"\n", len(written), unchanged, skipped)
 	return nil
 }
+
+// addManifest は生成したファイル outputs と出力先に残すファイル kept のマニフェストを w に追加します。
+// 内容が出力先のマニフェストと同じ場合は書き込みません。
+func addManifest(w *utils.OutputWriter, target ir.Target, outputs []Output, kept []manifest.Entry, prev *manifest.Manifest) error {
+	m, err := newManifest(target, utils.VERSION, outputs, kept, prev)
+	if err != nil {
+		return err
+	}
+	data, err := m.Marshal()
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(target.OutDir, manifest.FileName)
+	if current, err := os.ReadFile(path); err == nil && bytes.Equal(current, data) {
+		return nil
+	}
+	return w.Add(path, data, true)
+}
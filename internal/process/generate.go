@@ -0,0 +1,38 @@
+package process
+
+import (
+	"bytes"
+	texttemplate "text/template"
+
+	"github.com/nantokaworks/konst/internal/diag"
+	"github.com/nantokaworks/konst/internal/ir"
+	"github.com/nantokaworks/konst/internal/types"
+)
+
+// generateFile は定義ファイル f の解決済みの定義から、解析済みのテンプレート tmpl でコードを生成します
+// link が nil でない場合は、{{参照}} を参照先の定数を使った式のまま出力します
+func generateFile(f *ir.File, target ir.Target, tmpl *texttemplate.Template, link *linker) (Output, error) {
+	// 参照を式のまま出力する設定は生成ごとに異なるため、中間表現のスキーマを書き換えない
+	schema := copySchema(f.Schema)
+
+	// 参照を式のまま出力する場合は、参照先の定数を使った式と import を設定
+	if link != nil {
+		link.link(f, schema)
+	}
+	outFilePath := target.OutputPath(f)
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, schema); err != nil {
+		return Output{}, diag.New(diag.CodeGenerate, types.Position{File: f.Path}, "", "%v", err)
+	}
+	return Output{Path: outFilePath, Source: f.Path, Content: buf.Bytes()}, nil
+}
+
+// copySchema は定義を書き換えられるようにスキーマを定義の map ごとコピーします
+func copySchema(src *types.Schema) *types.Schema {
+	schema := *src
+	schema.Definitions = make(map[string]types.Definition, len(src.Definitions))
+	for name, def := range src.Definitions {
+		schema.Definitions[name] = def
+	}
+	return &schema
+}
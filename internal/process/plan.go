@@ -0,0 +1,214 @@
+package process
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/nantokaworks/konst/internal/diag"
+	"github.com/nantokaworks/konst/internal/ir"
+	"github.com/nantokaworks/konst/internal/manifest"
+	"github.com/nantokaworks/konst/internal/template"
+	"github.com/nantokaworks/konst/internal/types"
+	"github.com/nantokaworks/konst/internal/utils"
+)
+
+// Output は生成するファイルです
+type Output struct {
+	Path    string // 生成するファイルのパス
+	Source  string // 生成元の定義ファイルのパス（index.ts の場合は空）
+	Content []byte // 生成するファイルの内容
+	Input   string // 内容を決める入力（定義ファイル、参照先の定義ファイル、テンプレート、konst のバージョン、オプション）のハッシュ
+
+	// Skipped は入力が前回の生成から変わっておらず、生成せずに出力先のファイルの内容を Content にしたことを示します
+	Skipped bool
+}
+
+// Change は生成するファイルと出力先にある既存のファイルの違いです
+type Change int
+
+const (
+	Created   Change = iota // 出力先にファイルがない
+	Changed                 // 既存のファイルと内容が異なる
+	Unchanged               // 既存のファイルと同じ内容
+)
+
+// Compare は出力先にある既存のファイルと比較し、違いと既存のファイルの内容を返します
+func (o Output) Compare() (Change, []byte, error) {
+	current, err := os.ReadFile(o.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Created, nil, nil
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+	if bytes.Equal(current, o.Content) {
+		return Unchanged, current, nil
+	}
+	return Changed, current, nil
+}
+
+// Validate は読み込んだ定義ファイルを target に生成できるかを検証し、問題を全て返します。
+// Plan は生成する前にこの検証を行い、--validate も同じ検証を行います。
+func Validate(prog *ir.Program, target ir.Target, option *types.CommandOption) diag.List {
+	// 同じファイルに生成される定義ファイルは、後のファイルが先のファイルを上書きするため生成しない
+	issues := target.Conflicts(prog.Files)
+	// 同じ名前空間に生成される同じ名前の定義や識別子（テーブル Plan の PlanRows など）はコンパイルできないコードになるため生成しない
+	identifiers := func(name string, def types.Definition) []string {
+		return template.Identifiers(name, def, target.TS)
+	}
+	issues = append(issues, prog.Scope.Duplicates(target.Namespace, identifiers)...)
+
+	// number で誤差なく表せない整数は、値が変わったコードになるため生成しない
+	if target.TS {
+		// 参照を式のまま出力する定義は number で誤差なく計算できる場合だけ式になるため、生成と同じく式に変換してから検証する
+		var link *linker
+		if option.Symbolic != nil && *option.Symbolic {
+			link = newLinker(prog, target, true)
+		}
+		for _, f := range prog.Files {
+			schema := copySchema(f.Schema)
+			if link != nil {
+				link.link(f, schema)
+			}
+			issues = append(issues, template.CheckTS(schema)...)
+		}
+	}
+	issues.Sort()
+	return issues
+}
+
+// Plan は読み込んだ定義ファイルから生成する全てのファイルの内容を求めます。ファイルは書き込みません。
+// 実際の生成とドライランはどちらもこの結果を使います。
+//
+// prev が nil でない場合、入力のハッシュが前回の生成のマニフェスト prev と同じで、
+// 出力先のファイルが前回生成したときの内容のままであれば、生成せずに出力先のファイルの内容を使います。
+func Plan(prog *ir.Program, target ir.Target, option *types.CommandOption, prev *manifest.Manifest) ([]Output, error) {
+	issues := Validate(prog, target, option)
+	if issues.HasErrors() {
+		return nil, issues
+	}
+	if err := report(issues); err != nil {
+		return nil, err
+	}
+
+	// 参照を式のまま出力する場合と、Go の map のキーが別のファイルの enum の場合は、生成コードの間の import を決める
+	symbolic := option.Symbolic != nil && *option.Symbolic
+	var link *linker
+	if symbolic || (!target.TS && hasForeignKeyEnum(prog)) {
+		link = newLinker(prog, target, symbolic)
+	}
+
+	// テンプレートは全ての定義ファイルで同じため、一度だけ解析する
+	tmplText, err := template.Source(target.Ext(), option.TemplateDir)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := template.Parse(tmplText, option.Indent)
+	if err != nil {
+		return nil, err
+	}
+	// Go の import パスは go.mod で決まるため、import を決める場合は入力に含める
+	var importPaths string
+	if link != nil && !target.TS {
+		importPaths = goImportPaths(prog, target)
+	}
+	entries := make(map[string]manifest.Entry)
+	if prev != nil {
+		for _, e := range prev.Files {
+			entries[e.Path] = e
+		}
+	}
+
+	var outputs []Output
+	var index bytes.Buffer
+	// 各定義ファイルを処理
+	for _, f := range prog.Files {
+		outPath := target.OutputPath(f)
+		input := inputHash(prog, f, target, option, tmplText, importPaths, outPath, link != nil)
+		var out Output
+		if content, ok := reusable(target, entries, outPath, input); ok {
+			// 式のまま出力する場合の import の判断は定義ファイルの順に積み重なるため、生成しない定義ファイルも処理する
+			if link != nil {
+				link.link(f, copySchema(f.Schema))
+			}
+			out = Output{Path: outPath, Source: f.Path, Content: content, Skipped: true}
+		} else {
+			out, err = generateFile(f, target, tmpl, link)
+			if err != nil {
+				return nil, err
+			}
+		}
+		out.Input = input
+		outputs = append(outputs, out)
+		if target.TS {
+			// TS出力の場合、index.ts から参照する相対パス（拡張子抜き）を記録
+			exportPath, err := target.ModulePath(out.Path)
+			if err != nil {
+				return nil, err
+			}
+			fmt.Fprintf(&index, "export * from './%s';\n", exportPath)
+		}
+	}
+	// TS出力の場合、index.tsを生成
+	if target.TS {
+		outputs = append(outputs, Output{Path: target.IndexPath(), Content: index.Bytes()})
+	}
+	return outputs, nil
+}
+
+// reusable は入力のハッシュ input が前回の生成と同じで、出力先のファイル outPath が前回生成したときの内容のままであれば、その内容を返します
+func reusable(target ir.Target, entries map[string]manifest.Entry, outPath, input string) ([]byte, bool) {
+	rel, err := manifestPath(target, outPath)
+	if err != nil {
+		return nil, false
+	}
+	e, ok := entries[rel]
+	if !ok || e.Input == "" || e.Input != input {
+		return nil, false
+	}
+	content, err := os.ReadFile(outPath)
+	if err != nil || manifest.Hash(content) != e.Hash {
+		return nil, false
+	}
+	return content, true
+}
+
+// inputHash は定義ファイル f から生成するファイルの内容を決める入力のハッシュを返します。
+// 定義ファイルと参照先の定義ファイルの内容、テンプレート、konst のバージョン、生成に関わるオプション、
+// 出力先ディレクトリからのファイルのパス（-o の書き方によらない）を含みます。
+// 生成コードの間の import を決める場合（linked）は、import の判断が他の定義ファイルにも左右されるため全ての定義ファイルと、Go の import パス importPaths を含みます。
+func inputHash(prog *ir.Program, f *ir.File, target ir.Target, option *types.CommandOption, tmplText, importPaths, outPath string, linked bool) string {
+	h := sha256.New()
+	symbolic := option.Symbolic != nil && *option.Symbolic
+	rel, err := manifestPath(target, outPath)
+	if err != nil {
+		rel = filepath.ToSlash(outPath)
+	}
+	writeFields(h, utils.VERSION, tmplText, rel, importPaths,
+		fmt.Sprint(target.TS), target.NamingStyle, fmt.Sprint(*option.Indent), fmt.Sprint(symbolic))
+
+	files := prog.DependencyFiles(f)
+	if linked {
+		files = prog.Files
+	}
+	writeFields(h, f.Rel, f.Hash)
+	for _, dep := range files {
+		writeFields(h, dep.Rel, dep.Hash)
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil))
+}
+
+// writeFields は区切りを入れて fields をハッシュに書き込みます
+func writeFields(h hash.Hash, fields ...string) {
+	for _, field := range fields {
+		h.Write([]byte(field))
+		h.Write([]byte{0})
+	}
+}
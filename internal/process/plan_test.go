@@ -0,0 +1,321 @@
+package process
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nantokaworks/konst/internal/diag"
+	"github.com/nantokaworks/konst/internal/ir"
+	"github.com/nantokaworks/konst/internal/manifest"
+	"github.com/nantokaworks/konst/internal/types"
+)
+
+func init() {
+	Progress = io.Discard
+}
+
+// writeSchemas はテスト用の定義ファイルを作成します
+func writeSchemas(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+}
+
+// testOption はテスト用のコマンドオプションを作成します
+func testOption(force, prune bool) *types.CommandOption {
+	templateDir, indent, symbolic := "", 2, false
+	return &types.CommandOption{TemplateDir: &templateDir, Indent: &indent, Force: &force, Prune: &prune, Symbolic: &symbolic}
+}
+
+// load は定義ファイルを読み込みます
+func load(t *testing.T, dir string) *ir.Program {
+	t.Helper()
+	prog, err := ir.Load(dir, func(diag.List) error { return nil })
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	return prog
+}
+
+// generate は定義ファイルを読み込んで Go のコードを生成し、生成に使った Target を返します
+func generate(t *testing.T, inDir, outDir string, option *types.CommandOption) ir.Target {
+	t.Helper()
+	target := ir.Target{OutDir: outDir}
+	if err := Generate(load(t, inDir), target, option); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	return target
+}
+
+func TestGenerateKeyEnumDependency(t *testing.T) {
+	inDir, outDir := t.TempDir(), t.TempDir()
+	levels := func(debug, info string) string {
+		return `{"version": "1.0", "goPackage": "a", "definitions": {"Level": {"type": "enum", "values": [{"name": "Debug", "value": ` + debug + `}, {"name": "Info", "value": ` + info + `}]}}}`
+	}
+	writeSchemas(t, inDir, map[string]string{
+		"a.json": levels("0", "1"),
+		"b.json": `{"version": "1.0", "goPackage": "b", "definitions": {"Names": {"type": "map", "keyType": "Level", "valueType": "string", "value": {"Debug": "d", "Info": "i"}}}}`,
+	})
+	generate(t, inDir, outDir, testOption(false, false))
+
+	// 別のファイルにある keyType の enum の値を変えると、map のファイルも生成し直す
+	writeSchemas(t, inDir, map[string]string{"a.json": levels("10", "11")})
+	target := generate(t, inDir, outDir, testOption(true, false))
+
+	content, err := os.ReadFile(filepath.Join(outDir, "b", "b.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "10") {
+		t.Errorf("Expected b.go to use the new enum values, got:\n%s", content)
+	}
+	outputs, err := Plan(load(t, inDir), target, testOption(false, false), nil)
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+	prev, err := manifest.Load(outDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result, err := Check(outputs, target, prev); err != nil || !result.UpToDate() {
+		t.Errorf("Check() = %+v, %v, expected up to date", result, err)
+	}
+}
+
+func TestPlanKeyEnumImport(t *testing.T) {
+	inDir, outDir := t.TempDir(), t.TempDir()
+	names := func(pkg string) string {
+		return `{"version": "1.0", "goPackage": "` + pkg + `", "definitions": {"Names": {"type": "map", "keyType": "Level", "valueType": "string", "value": {"debug": "d"}}}}`
+	}
+	writeSchemas(t, inDir, map[string]string{
+		"a.json": `{"version": "1.0", "goPackage": "a", "definitions": {"Level": {"type": "enum", "values": ["debug"]}}}`,
+		"b.json": names("b"),
+		"c.json": names("a"),
+	})
+	writeSchemas(t, outDir, map[string]string{"go.mod": "module example.com/gen\n"})
+
+	outputs, err := Plan(load(t, inDir), ir.Target{OutDir: outDir}, testOption(false, false), nil)
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+	// 別のパッケージの enum は import して参照し、同じパッケージの enum はそのまま参照する
+	expected := map[string][]string{
+		"b.go": {`"example.com/gen/a"`, "map[a.Level]string", "a.LevelDebug:"},
+		"c.go": {"map[Level]string", "LevelDebug:"},
+	}
+	for _, out := range outputs {
+		for _, want := range expected[filepath.Base(out.Path)] {
+			if !strings.Contains(string(out.Content), want) {
+				t.Errorf("Expected %s to contain %s, got:\n%s", out.Path, want, out.Content)
+			}
+		}
+	}
+}
+
+func TestPlanDuplicateDefinitions(t *testing.T) {
+	tests := []struct {
+		name     string
+		packages [2]string
+		ts       bool
+		wantErr  bool
+	}{
+		{"Different Go packages", [2]string{"a", "b"}, false, false},
+		{"Same Go package", [2]string{"a", "a"}, false, true},
+		{"TypeScript index.ts", [2]string{"a", "b"}, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeSchemas(t, dir, map[string]string{
+				"a.json": `{"version": "1.0", "goPackage": "` + tt.packages[0] + `", "definitions": {"Timeout": {"type": "int", "value": 1}}}`,
+				"b.json": `{"version": "1.0", "goPackage": "` + tt.packages[1] + `", "definitions": {"Timeout": {"type": "int", "value": 2}}}`,
+			})
+			_, err := Plan(load(t, dir), ir.Target{OutDir: t.TempDir(), TS: tt.ts}, testOption(false, false), nil)
+			var d *diag.Diagnostic
+			if tt.wantErr && (!errors.As(err, &d) || d.Code != diag.CodeDuplicateDefinition) {
+				t.Errorf("Expected duplicate-definition error, got %v", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+// schema は値が value の定義 Timeout を持つ Go パッケージ pkg の定義ファイルの内容を返します
+func schema(pkg, value string) string {
+	return `{"version": "1.0", "goPackage": "` + pkg + `", "definitions": {"Timeout": {"type": "int", "value": ` + value + `}}}`
+}
+
+func TestPlanReuse(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(t *testing.T, inDir, outDir string, option *types.CommandOption) *types.CommandOption
+		noPrev  bool
+		skipped bool
+	}{
+		{
+			name:    "Unchanged input",
+			skipped: true,
+		},
+		{
+			name: "Changed definition",
+			change: func(t *testing.T, inDir, outDir string, option *types.CommandOption) *types.CommandOption {
+				writeSchemas(t, inDir, map[string]string{"a.json": schema("a", "2")})
+				return option
+			},
+		},
+		{
+			name: "Output modified after generation",
+			change: func(t *testing.T, inDir, outDir string, option *types.CommandOption) *types.CommandOption {
+				writeSchemas(t, outDir, map[string]string{"a/a.go": "package a\n"})
+				return option
+			},
+		},
+		{
+			name: "Changed option",
+			change: func(t *testing.T, inDir, outDir string, option *types.CommandOption) *types.CommandOption {
+				indent := 4
+				option.Indent = &indent
+				return option
+			},
+		},
+		{
+			name:   "No previous manifest",
+			noPrev: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inDir, outDir := t.TempDir(), t.TempDir()
+			writeSchemas(t, inDir, map[string]string{"a.json": schema("a", "1")})
+			target := generate(t, inDir, outDir, testOption(false, false))
+
+			option := testOption(true, false)
+			if tt.change != nil {
+				option = tt.change(t, inDir, outDir, option)
+			}
+			var prev *manifest.Manifest
+			if !tt.noPrev {
+				var err error
+				if prev, err = manifest.Load(outDir); err != nil {
+					t.Fatal(err)
+				}
+			}
+			outputs, err := Plan(load(t, inDir), target, option, prev)
+			if err != nil {
+				t.Fatalf("Plan failed: %v", err)
+			}
+			if len(outputs) != 1 || outputs[0].Skipped != tt.skipped {
+				t.Errorf("Plan() = %+v, expected one output with Skipped %v", outputs, tt.skipped)
+			}
+		})
+	}
+}
+
+func TestPlanCustomTemplate(t *testing.T) {
+	inDir, tmplDir := t.TempDir(), t.TempDir()
+	writeSchemas(t, inDir, map[string]string{"a.json": schema("a", "1"), "b.json": schema("b", "2")})
+	writeSchemas(t, tmplDir, map[string]string{"go.tmpl": "package {{ .GoPackage }}\n\nconst Indented = {{ indent 1 \"x\" | printf \"%q\" }}\n"})
+
+	option := testOption(false, false)
+	option.TemplateDir = &tmplDir
+	outputs, err := Plan(load(t, inDir), ir.Target{OutDir: t.TempDir()}, option, nil)
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+	// 一度だけ解析したテンプレートで全ての定義ファイルを生成する
+	for i, pkg := range []string{"a", "b"} {
+		expected := "package " + pkg + "\n\nconst Indented = \"  x\"\n"
+		if string(outputs[i].Content) != expected {
+			t.Errorf("outputs[%d] = %q, expected %q", i, outputs[i].Content, expected)
+		}
+	}
+
+	// 解析できないテンプレートは定義ファイルを生成する前にエラーにする
+	writeSchemas(t, tmplDir, map[string]string{"go.tmpl": "package {{ .GoPackage "})
+	if _, err := Plan(load(t, inDir), ir.Target{OutDir: t.TempDir()}, option, nil); err == nil {
+		t.Error("Expected error for invalid template")
+	}
+}
+
+func TestPlanOutputConflicts(t *testing.T) {
+	dir := t.TempDir()
+	writeSchemas(t, dir, map[string]string{"my-file.json": schema("a", "1"), "my_file.json": schema("a", "2")})
+	_, err := Plan(load(t, dir), ir.Target{OutDir: t.TempDir()}, testOption(false, false), nil)
+	if err == nil || !strings.Contains(err.Error(), "my-file.json and ") || !strings.Contains(err.Error(), "my_file.json would generate the same output file") {
+		t.Errorf("Expected error naming both definition files, got %v", err)
+	}
+}
+
+func TestPlanReuseOutDirSpelling(t *testing.T) {
+	inDir, outDir := t.TempDir(), t.TempDir()
+	writeSchemas(t, inDir, map[string]string{"a.json": schema("a", "1")})
+	generate(t, inDir, outDir, testOption(false, false))
+
+	// 同じ出力先を相対パスで指定しても前回の生成を使う
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	relDir, err := filepath.Rel(wd, outDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev, err := manifest.Load(relDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputs, err := Plan(load(t, inDir), ir.Target{OutDir: relDir}, testOption(false, false), prev)
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+	if len(outputs) != 1 || !outputs[0].Skipped {
+		t.Errorf("Plan() = %+v, expected the output to be reused", outputs)
+	}
+}
+
+func TestPlanSymbolicModulePath(t *testing.T) {
+	inDir, outDir := t.TempDir(), t.TempDir()
+	writeSchemas(t, inDir, map[string]string{
+		"a.json": schema("a", "1"),
+		"b.json": `{"version": "1.0", "goPackage": "b", "definitions": {"Double": {"type": "int", "value": "{{a#Timeout}} * 2"}}}`,
+	})
+	writeSchemas(t, outDir, map[string]string{"go.mod": "module example.com/old\n"})
+	option := testOption(false, false)
+	symbolic := true
+	option.Symbolic = &symbolic
+	target := generate(t, inDir, outDir, option)
+
+	// go.mod のモジュールパスが変わると import パスが変わるため、前回の生成を使わない
+	writeSchemas(t, outDir, map[string]string{"go.mod": "module example.com/new\n"})
+	prev, err := manifest.Load(outDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputs, err := Plan(load(t, inDir), target, option, prev)
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+	for _, out := range outputs {
+		if out.Skipped {
+			t.Errorf("Expected %s to be generated again", out.Path)
+		}
+		if filepath.Base(out.Path) == "b.go" && !strings.Contains(string(out.Content), `"example.com/new/a"`) {
+			t.Errorf("Expected b.go to import the new module path, got:\n%s", out.Content)
+		}
+	}
+}
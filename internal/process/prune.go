@@ -0,0 +1,83 @@
+package process
+
+import (
+	"path/filepath"
+
+	"github.com/nantokaworks/konst/internal/ir"
+	"github.com/nantokaworks/konst/internal/manifest"
+)
+
+// Leftover は前回の生成で作成したが、今回は生成しないファイルです
+type Leftover struct {
+	manifest.Entry
+	Modified bool // 生成後に書き換えられている（prune でも削除しない）
+}
+
+// Leftovers は前回の生成のマニフェスト prev に記録されたファイルのうち、outputs に含まれず出力先に残っているものを返します。
+// 同じ出力先に別の出力モードで生成したファイルは対象にしません。
+func Leftovers(target ir.Target, prev *manifest.Manifest, outputs []Output) ([]Leftover, error) {
+	planned := make(map[string]bool, len(outputs))
+	for _, out := range outputs {
+		rel, err := manifestPath(target, out.Path)
+		if err != nil {
+			return nil, err
+		}
+		planned[rel] = true
+	}
+
+	var leftovers []Leftover
+	for _, e := range prev.Files {
+		if planned[e.Path] || !sameMode(target, e) {
+			continue
+		}
+		exists, modified, err := manifest.Stat(target.OutDir, e)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			leftovers = append(leftovers, Leftover{Entry: e, Modified: modified})
+		}
+	}
+	return leftovers, nil
+}
+
+// sameMode は記録されたファイル e が target と同じ出力モードで生成されたかどうかを返します。
+// 出力モードを記録していない古いマニフェストのファイルは拡張子で判断します。
+func sameMode(target ir.Target, e manifest.Entry) bool {
+	if e.Mode == "" {
+		return filepath.Ext(e.Path) == target.Ext()
+	}
+	return e.Mode == target.Mode()
+}
+
+// newManifest は生成したファイル outputs と出力先に残すファイル kept のマニフェストを作成します。
+// 前回のマニフェスト prev のうち別の出力モードで生成したファイルはそのまま残します。
+func newManifest(target ir.Target, version string, outputs []Output, kept []manifest.Entry, prev *manifest.Manifest) (*manifest.Manifest, error) {
+	m := &manifest.Manifest{Version: version}
+	for _, out := range outputs {
+		rel, err := manifestPath(target, out.Path)
+		if err != nil {
+			return nil, err
+		}
+		m.Files = append(m.Files, manifest.Entry{Path: rel, Source: filepath.ToSlash(out.Source), Hash: manifest.Hash(out.Content), Input: out.Input, Mode: target.Mode()})
+	}
+	for _, e := range kept {
+		e.Mode = target.Mode()
+		m.Files = append(m.Files, e)
+	}
+	for _, e := range prev.Files {
+		if !sameMode(target, e) {
+			m.Files = append(m.Files, e)
+		}
+	}
+	return m, nil
+}
+
+// manifestPath はマニフェストに記録する出力先ディレクトリからの相対パスを返します
+func manifestPath(target ir.Target, path string) (string, error) {
+	rel, err := filepath.Rel(target.OutDir, path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
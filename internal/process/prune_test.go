@@ -0,0 +1,118 @@
+package process
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nantokaworks/konst/internal/ir"
+	"github.com/nantokaworks/konst/internal/manifest"
+)
+
+func TestGeneratePrune(t *testing.T) {
+	tests := []struct {
+		name     string
+		prune    bool
+		modify   bool
+		removed  bool // b.go が削除される
+		recorded bool // b.go がマニフェストに残る
+	}{
+		{"Without prune", false, false, false, true},
+		{"Prune", true, false, true, false},
+		{"Prune keeps modified file", true, true, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inDir, outDir := t.TempDir(), t.TempDir()
+			writeSchemas(t, inDir, map[string]string{"a.json": schema("a", "1"), "b.json": schema("b", "1")})
+			generate(t, inDir, outDir, testOption(false, false))
+
+			if err := os.Remove(filepath.Join(inDir, "b.json")); err != nil {
+				t.Fatal(err)
+			}
+			if tt.modify {
+				writeSchemas(t, outDir, map[string]string{"b/b.go": "package b\n"})
+			}
+			generate(t, inDir, outDir, testOption(true, tt.prune))
+
+			_, err := os.Stat(filepath.Join(outDir, "b", "b.go"))
+			if removed := os.IsNotExist(err); removed != tt.removed {
+				t.Errorf("b.go removed = %v, expected %v", removed, tt.removed)
+			}
+			if tt.removed {
+				if _, err := os.Stat(filepath.Join(outDir, "b")); !os.IsNotExist(err) {
+					t.Errorf("Expected empty directory b to be removed")
+				}
+			}
+			m, err := manifest.Load(outDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			recorded := false
+			for _, e := range m.Files {
+				if e.Path == "b/b.go" {
+					recorded = true
+				}
+			}
+			if recorded != tt.recorded {
+				t.Errorf("b/b.go recorded = %v, expected %v", recorded, tt.recorded)
+			}
+		})
+	}
+}
+
+func TestGenerateBothModes(t *testing.T) {
+	inDir, outDir := t.TempDir(), t.TempDir()
+	writeSchemas(t, inDir, map[string]string{
+		"a.json": schema("a", "1"),
+		"b.json": `{"version": "1.0", "goPackage": "b", "definitions": {"Retries": {"type": "int", "value": 3}}}`,
+	})
+	goTarget := ir.Target{OutDir: outDir}
+	tsTarget := ir.Target{OutDir: outDir, TS: true}
+	for _, target := range []ir.Target{goTarget, tsTarget} {
+		if err := Generate(load(t, inDir), target, testOption(false, true)); err != nil {
+			t.Fatalf("Generate(%s) failed: %v", target.Mode(), err)
+		}
+	}
+
+	// 同じ出力先にある別の出力モードのファイルは不要なファイルとみなさない
+	for _, target := range []ir.Target{goTarget, tsTarget} {
+		outputs, err := Plan(load(t, inDir), target, testOption(false, false), nil)
+		if err != nil {
+			t.Fatalf("Plan failed: %v", err)
+		}
+		prev, err := manifest.Load(outDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result, err := Check(outputs, target, prev); err != nil || !result.UpToDate() {
+			t.Errorf("Check(%s) = %+v, %v, expected up to date", target.Mode(), result, err)
+		}
+	}
+
+	// prune は同じ出力モードのファイルだけを削除する
+	if err := os.Remove(filepath.Join(inDir, "b.json")); err != nil {
+		t.Fatal(err)
+	}
+	if err := Generate(load(t, inDir), goTarget, testOption(true, true)); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outDir, "b", "b.go")); !os.IsNotExist(err) {
+		t.Errorf("Expected b/b.go to be pruned")
+	}
+	if _, err := os.Stat(filepath.Join(outDir, "b.ts")); err != nil {
+		t.Errorf("Expected b.ts to be kept: %v", err)
+	}
+	m, err := manifest.Load(outDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	modes := make(map[string]string)
+	for _, e := range m.Files {
+		modes[e.Path] = e.Mode
+	}
+	if modes["b.ts"] != "ts" || modes["a/a.go"] != "go" || modes["index.ts"] != "ts" {
+		t.Errorf("Unexpected manifest entries: %+v", m.Files)
+	}
+}
@@ -0,0 +1,322 @@
+package process
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nantokaworks/konst/internal/ir"
+	"github.com/nantokaworks/konst/internal/template"
+	"github.com/nantokaworks/konst/internal/types"
+)
+
+// linker は {{参照}} を参照先の定数を使った式のまま出力するために、参照先の定義ファイルから生成するコードを求め、
+// 生成コードの間の import を決めます。import が循環する参照は計算した値のまま出力します。
+// Go の map のキーが別のファイルの enum の場合も、enum の型を参照する import を決めます。
+type linker struct {
+	prog     *ir.Program
+	target   ir.Target
+	symbolic bool // {{参照}} を式のまま出力する
+
+	deps map[string]map[string]bool // 生成コードの単位の間の import（循環の検出用）
+}
+
+// pendingImport は式に変換できた場合に追加する import です
+type pendingImport struct {
+	path string // import パス
+	name string // Go のパッケージを参照する名前、または TypeScript で import する定義名
+	unit string // import する生成コードの単位
+}
+
+func newLinker(prog *ir.Program, target ir.Target, symbolic bool) *linker {
+	return &linker{
+		prog:     prog,
+		target:   target,
+		symbolic: symbolic,
+		deps:     make(map[string]map[string]bool),
+	}
+}
+
+// unit は定義ファイルの生成コードの import の単位（Go はパッケージのディレクトリ、TypeScript はファイル）を返します
+func (l *linker) unit(f *ir.File) string {
+	if l.target.TS {
+		return l.target.OutputPath(f)
+	}
+	return filepath.Dir(l.target.OutputPath(f))
+}
+
+// link は schema の定義のうち {{}} で他の定義を参照するものに、参照先の定数を使った式と必要な import を設定します。
+// 式で表せない定義は計算した値のまま出力します。
+// Go の map のキーが別のファイルの enum の場合は、enum の型を参照する名前（KeyEnumRef）と import を設定します。
+func (l *linker) link(file *ir.File, schema *types.Schema) {
+	from := l.unit(file)
+	imports := make(map[string]*types.Import) // import パス → import
+	packageNames := make(map[string]string)   // Go のパッケージを参照する名前 → import パス
+	importedNames := make(map[string]string)  // TypeScript で import する定義名 → import パス
+
+	names := make([]string, 0, len(schema.Definitions))
+	for name := range schema.Definitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// goPackage は別のパッケージ to に生成される定義ファイル owner を参照する名前と import を返します
+	goPackage := func(owner *ir.File, to string) (pendingImport, bool) {
+		importPath, ok := goImportPath(to)
+		if !ok || owner.Schema.GoPackage == "" {
+			return pendingImport{}, false
+		}
+		// 同じ名前のパッケージを複数 import する場合は2つ目以降に番号を付ける
+		base := owner.Schema.GoPackage
+		pkg := base
+		for i := 2; packageNames[pkg] != "" && packageNames[pkg] != importPath; i++ {
+			pkg = fmt.Sprintf("%s%d", base, i)
+		}
+		packageNames[pkg] = importPath
+		return pendingImport{path: importPath, name: pkg, unit: to}, true
+	}
+	addDependency := func(p pendingImport) {
+		if l.deps[from] == nil {
+			l.deps[from] = make(map[string]bool)
+		}
+		l.deps[from][p.unit] = true
+	}
+
+	for _, name := range names {
+		def := schema.Definitions[name]
+		if !l.symbolic || def.Expression == "" {
+			continue
+		}
+		var pending []pendingImport
+		resolve := func(ref string) (template.SymbolRef, bool) {
+			sym, err := l.prog.Scope.Lookup(file.Path, ref)
+			if err != nil {
+				return template.SymbolRef{}, false
+			}
+			refDef, ok := l.prog.Definition(sym)
+			if !ok {
+				return template.SymbolRef{}, false
+			}
+			if sym.File == file.Path {
+				// TypeScript の const は宣言より前に参照できないため、名前順で先に出力される定義だけを参照する
+				if l.target.TS && sym.Name >= name {
+					return template.SymbolRef{}, false
+				}
+				return template.SymbolRef{Def: refDef, Code: sym.Name}, true
+			}
+			owner := l.prog.File(sym.File)
+			to := l.unit(owner)
+			if _, shadowed := schema.Definitions[sym.Name]; shadowed && (l.target.TS || to == from) {
+				// 同名の定義がこのファイルにもあり、import や同じパッケージの定数と名前が衝突する
+				return template.SymbolRef{}, false
+			}
+			if to == from {
+				// Go の同じパッケージの定数
+				return template.SymbolRef{Def: refDef, Code: sym.Name}, true
+			}
+			if l.reaches(to, from) {
+				return template.SymbolRef{}, false
+			}
+			if l.target.TS {
+				modulePath := tsModulePath(l.target.OutputPath(file), l.target.OutputPath(owner))
+				if p, ok := importedNames[sym.Name]; ok && p != modulePath {
+					// 別のファイルの同名の定義を import している
+					return template.SymbolRef{}, false
+				}
+				for _, p := range pending {
+					if p.name == sym.Name && p.path != modulePath {
+						return template.SymbolRef{}, false
+					}
+				}
+				pending = append(pending, pendingImport{path: modulePath, name: sym.Name, unit: to})
+				return template.SymbolRef{Def: refDef, Code: sym.Name}, true
+			}
+
+			p, ok := goPackage(owner, to)
+			if !ok {
+				return template.SymbolRef{}, false
+			}
+			pending = append(pending, p)
+			return template.SymbolRef{Def: refDef, Code: p.name + "." + sym.Name}, true
+		}
+
+		var (
+			code string
+			ok   bool
+		)
+		if l.target.TS {
+			code, ok = template.SymbolicTS(def, resolve)
+		} else {
+			code, ok = template.SymbolicGo(def, resolve)
+		}
+		if !ok {
+			continue
+		}
+		def.Code = code
+		schema.Definitions[name] = def
+		for _, p := range pending {
+			l.addImport(imports, p)
+			if l.target.TS {
+				importedNames[p.name] = p.path
+			}
+			addDependency(p)
+		}
+	}
+
+	// Go の map のキーの別のファイルの enum は、同じパッケージなら enum の名前で、別のパッケージなら import して参照する
+	// （import が循環する場合や import パスが決まらない場合は enum の元の型のキーにする）
+	for _, name := range names {
+		def := schema.Definitions[name]
+		if l.target.TS || def.KeyEnum == nil || def.KeyEnum.Pos.File == file.Path {
+			continue
+		}
+		sym, err := l.prog.Scope.Lookup(file.Path, string(def.KeyType))
+		if err != nil {
+			continue
+		}
+		owner := l.prog.File(sym.File)
+		to := l.unit(owner)
+		switch {
+		case to == from:
+			def.KeyEnumRef = sym.Name
+		case l.reaches(to, from):
+			continue
+		default:
+			p, ok := goPackage(owner, to)
+			if !ok {
+				continue
+			}
+			l.addImport(imports, p)
+			addDependency(p)
+			def.KeyEnumRef = p.name + "." + sym.Name
+		}
+		schema.Definitions[name] = def
+	}
+
+	schema.Imports = nil
+	for _, imp := range imports {
+		sort.Strings(imp.Names)
+		schema.Imports = append(schema.Imports, *imp)
+	}
+	sort.Slice(schema.Imports, func(i, j int) bool { return schema.Imports[i].Path < schema.Imports[j].Path })
+}
+
+// addImport は import を追加します
+func (l *linker) addImport(imports map[string]*types.Import, p pendingImport) {
+	imp, ok := imports[p.path]
+	if !ok {
+		imp = &types.Import{Path: p.path}
+		if !l.target.TS && p.name != path.Base(p.path) {
+			imp.Name = p.name
+		}
+		imports[p.path] = imp
+	}
+	if !l.target.TS {
+		return
+	}
+	for _, name := range imp.Names {
+		if name == p.name {
+			return
+		}
+	}
+	imp.Names = append(imp.Names, p.name)
+}
+
+// reaches は生成コードの単位 from から to へ import をたどれるかどうかを返します
+func (l *linker) reaches(from, to string) bool {
+	visited := make(map[string]bool)
+	var visit func(string) bool
+	visit = func(unit string) bool {
+		if unit == to {
+			return true
+		}
+		if visited[unit] {
+			return false
+		}
+		visited[unit] = true
+		for next := range l.deps[unit] {
+			if visit(next) {
+				return true
+			}
+		}
+		return false
+	}
+	return visit(from)
+}
+
+// tsModulePath は TypeScript のファイル from から to を import するときのモジュールの相対パスを返します
+func tsModulePath(from, to string) string {
+	rel, err := filepath.Rel(filepath.Dir(from), to)
+	if err != nil {
+		rel = to
+	}
+	rel = strings.TrimSuffix(filepath.ToSlash(rel), ".ts")
+	if !strings.HasPrefix(rel, "../") {
+		rel = "./" + rel
+	}
+	return rel
+}
+
+// goImportPath は Go のパッケージのディレクトリ dir の import パスを返します。
+// dir を含む Go モジュール（go.mod）が見つからない場合は ok が false になります。
+func goImportPath(dir string) (string, bool) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	for d := abs; ; d = filepath.Dir(d) {
+		if module, ok := goModulePath(filepath.Join(d, "go.mod")); ok {
+			rel, err := filepath.Rel(d, abs)
+			if err != nil {
+				return "", false
+			}
+			if rel == "." {
+				return module, true
+			}
+			return module + "/" + filepath.ToSlash(rel), true
+		}
+		if filepath.Dir(d) == d {
+			return "", false
+		}
+	}
+}
+
+// goImportPaths は全ての定義ファイルから生成する Go のパッケージの import パスを、生成コードの入力のハッシュに含める形で返します
+func goImportPaths(prog *ir.Program, target ir.Target) string {
+	var paths []string
+	for _, f := range prog.Files {
+		importPath, _ := goImportPath(filepath.Dir(target.OutputPath(f)))
+		paths = append(paths, importPath)
+	}
+	return strings.Join(paths, "\n")
+}
+
+// goModulePath は go.mod の module ディレクティブのモジュールパスを返します
+func goModulePath(goMod string) (string, bool) {
+	data, err := os.ReadFile(goMod)
+	if err != nil {
+		return "", false
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`), true
+		}
+	}
+	return "", false
+}
+
+// hasForeignKeyEnum は map のキーに別の定義ファイルの enum を使う定義があるかどうかを返します
+func hasForeignKeyEnum(prog *ir.Program) bool {
+	for _, f := range prog.Files {
+		for _, def := range f.Schema.Definitions {
+			if def.KeyEnum != nil && def.KeyEnum.Pos.File != f.Path {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package template
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/nantokaworks/konst/internal/diag"
+	"github.com/nantokaworks/konst/internal/types"
+	"github.com/nantokaworks/konst/internal/utils"
+)
+
+// CheckTS はスキーマを TypeScript に出力する前に、number では誤差なく表せない整数の値を探して問題の一覧を返します。
+// tsMode が number（省略時）の整数型の値が Number.MAX_SAFE_INTEGER を超える場合はエラーにし、bigint か string の tsMode を指定するよう促します。
+func CheckTS(schema *types.Schema) diag.List {
+	names := make([]string, 0, len(schema.Definitions))
+	for name := range schema.Definitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var list diag.List
+	for _, name := range names {
+		def := schema.Definitions[name]
+		// 参照を式のまま出力する定義は、number で誤差なく計算できる場合だけ式になる
+		if def.Code != "" {
+			continue
+		}
+		for _, msg := range checkTSNumbers(def) {
+			list = append(list, diag.New(diag.CodeValueType, def.Pos, name, "%s", msg))
+		}
+	}
+	list.Sort()
+	return list
+}
+
+// checkTSNumbers は定義の値（object のフィールド、table の行、map の値、配列の要素を含む）のうち number で誤差なく表せない整数を探し、問題の内容を返します
+func checkTSNumbers(def types.Definition) []string {
+	switch {
+	case utils.IsIntegerType(def.Type):
+		if literal, ok := tsUnsafeInteger(def.Value, def.TSMode); ok {
+			return []string{unsafeIntegerMessage(literal)}
+		}
+	case def.Type == types.DefinitionTypeObject:
+		obj, _ := def.Value.(map[string]any)
+		var msgs []string
+		for _, f := range def.Fields {
+			for _, msg := range checkTSNumbers(f.Definition(def, utils.ToPascalCase(f.Name), obj[f.Name])) {
+				msgs = append(msgs, fmt.Sprintf("field %q: %s", f.Name, msg))
+			}
+		}
+		return msgs
+	case def.Type == types.DefinitionTypeTable:
+		var msgs []string
+		for i := range def.Rows {
+			for _, msg := range checkTSNumbers(def.RowDefinition(i)) {
+				msgs = append(msgs, fmt.Sprintf("rows[%d]: %s", i, msg))
+			}
+		}
+		return msgs
+	case def.Type == types.DefinitionTypeMap:
+		var msgs []string
+		for _, e := range mapEntries(def) {
+			for _, msg := range checkTSNumbers(def.ValueDefinition(e.Value)) {
+				msgs = append(msgs, fmt.Sprintf("key %q: %s", e.Key, msg))
+			}
+		}
+		return msgs
+	case strings.HasSuffix(string(def.Type), "[]") && utils.IsIntegerType(arrayElementDefinition(def).Type):
+		elems, _ := def.Value.([]any)
+		var msgs []string
+		for i, elem := range elems {
+			if literal, ok := tsUnsafeInteger(elem, def.TSMode); ok {
+				msgs = append(msgs, fmt.Sprintf("element %d: %s", i, unsafeIntegerMessage(literal)))
+			}
+		}
+		return msgs
+	}
+	return nil
+}
+
+// tsUnsafeInteger は tsMode が number の整数が安全な整数の範囲を超える場合に、その値の10進表記を返します
+func tsUnsafeInteger(value any, mode types.TSMode) (string, bool) {
+	if mode == types.ModeBigInt || mode == types.ModeString {
+		return "", false
+	}
+	n, ok := utils.AsNumber(value)
+	if !ok {
+		return "", false
+	}
+	r, ok := utils.NumberRat(n)
+	if !ok || !r.IsInt() {
+		return "", false
+	}
+	if new(big.Int).Abs(r.Num()).Cmp(tsMaxSafeInteger) <= 0 {
+		return "", false
+	}
+	return r.Num().String(), true
+}
+
+// unsafeIntegerMessage は number で誤差なく表せない整数の問題の内容を返します
+func unsafeIntegerMessage(literal string) string {
+	return fmt.Sprintf(`value %s is outside the range a TypeScript number represents exactly (±Number.MAX_SAFE_INTEGER); use tsMode "bigint" or "string"`, literal)
+}
@@ -0,0 +1,100 @@
+package template
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/nantokaworks/konst/internal/types"
+)
+
+func TestCheckTS(t *testing.T) {
+	tests := []struct {
+		name     string
+		def      types.Definition
+		expected []string
+	}{
+		{
+			name: "Safe integer",
+			def:  types.Definition{Type: types.DefinitionTypeInt64, Value: json.Number("9007199254740991")},
+		},
+		{
+			name:     "Int64 above MAX_SAFE_INTEGER",
+			def:      types.Definition{Type: types.DefinitionTypeInt64, Value: json.Number("9007199254740992")},
+			expected: []string{`value 9007199254740992 is outside the range`},
+		},
+		{
+			name:     "Negative int below MIN_SAFE_INTEGER",
+			def:      types.Definition{Type: types.DefinitionTypeInt, Value: json.Number("-9007199254740992"), TSMode: types.ModeNumber},
+			expected: []string{`value -9007199254740992 is outside the range`},
+		},
+		{
+			name: "Uint64 as bigint",
+			def:  types.Definition{Type: types.DefinitionTypeUint64, Value: json.Number("18446744073709551615"), TSMode: types.ModeBigInt},
+		},
+		{
+			name: "Int64 as string",
+			def:  types.Definition{Type: types.DefinitionTypeInt64, Value: json.Number("9223372036854775807"), TSMode: types.ModeString},
+		},
+		{
+			name: "Float is not checked",
+			def:  types.Definition{Type: types.DefinitionTypeFloat64, Value: json.Number("1e20")},
+		},
+		{
+			name: "Symbolic expression",
+			def:  types.Definition{Type: types.DefinitionTypeInt64, Value: json.Number("9007199254740992"), Code: "Base * 2"},
+		},
+		{
+			name:     "Array element",
+			def:      types.Definition{Type: "uint64[]", Value: []interface{}{json.Number("1"), json.Number("18446744073709551615")}},
+			expected: []string{`element 1: value 18446744073709551615 is outside`},
+		},
+		{
+			name: "Array as bigint",
+			def:  types.Definition{Type: "uint64[]", Value: []interface{}{json.Number("18446744073709551615")}, TSMode: types.ModeBigInt},
+		},
+		{
+			name: "Object field",
+			def: types.Definition{
+				Type: types.DefinitionTypeObject,
+				Fields: []types.Field{
+					{Name: "id", Type: types.DefinitionTypeInt64},
+					{Name: "hash", Type: types.DefinitionTypeUint64, TSMode: types.ModeBigInt},
+				},
+				Value: map[string]interface{}{"id": json.Number("9007199254740993"), "hash": json.Number("18446744073709551615")},
+			},
+			expected: []string{`field "id": value 9007199254740993 is outside`},
+		},
+		{
+			name: "Table row",
+			def: types.Definition{
+				Type:    types.DefinitionTypeTable,
+				Columns: []types.Field{{Name: "id", Type: types.DefinitionTypeInt64, Key: true}},
+				Rows:    []map[string]interface{}{{"id": json.Number("1")}, {"id": json.Number("9007199254740993")}},
+			},
+			expected: []string{`rows[1]: field "id": value 9007199254740993 is outside`},
+		},
+		{
+			name:     "Map value",
+			def:      types.Definition{Type: types.DefinitionTypeMap, KeyType: types.DefinitionTypeString, ValueType: types.DefinitionTypeInt64, Value: map[string]interface{}{"a": json.Number("1"), "b": json.Number("9007199254740993")}},
+			expected: []string{`key "b": value 9007199254740993 is outside`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := CheckTS(&types.Schema{Definitions: map[string]types.Definition{"Value": tt.def}})
+			if len(issues) != len(tt.expected) {
+				t.Fatalf("CheckTS() = %v, expected %d issues", issues, len(tt.expected))
+			}
+			for i, expected := range tt.expected {
+				if !strings.Contains(issues[i].Error(), expected) {
+					t.Errorf("issue %d = %q, expected to contain %q", i, issues[i].Error(), expected)
+				}
+				if !strings.Contains(issues[i].Error(), `tsMode "bigint" or "string"`) {
+					t.Errorf("issue %d = %q, expected to suggest tsMode", i, issues[i].Error())
+				}
+			}
+		})
+	}
+}
@@ -1,25 +1,47 @@
 package template
 
 const defaultGoTemplate = `package {{ .GoPackage }}
-{{- $needsStrings := or (hasEnum .Definitions) (hasTemplate .Definitions) }}
+{{- $needsErrors := hasEnum .Definitions }}
+{{- $needsSQL := and $needsErrors (.UsesGoEnumInterface "sql") }}
+{{- $needsJSON := and $needsErrors (.UsesGoEnumInterface "json") }}
+{{- $needsStrconv := hasIntEnum .Definitions }}
+{{- $needsStrings := hasTemplate .Definitions }}
 {{- $needsTime := hasDate .Definitions }}
-{{- if or $needsStrings $needsTime }}
+{{- $needsStd := or $needsErrors $needsStrconv $needsStrings $needsTime }}
+{{- if or $needsStd .Imports }}
 import (
-{{- if or $needsStrings $needsTime }}
+{{- if $needsSQL }}
+	"database/sql/driver"
+{{- end }}
+{{- if $needsJSON }}
+	"encoding/json"
+{{- end }}
+{{- if $needsErrors }}
 	"errors"
 {{- end }}
+{{- if $needsSQL }}
+	"fmt"
+{{- end }}
+{{- if $needsStrconv }}
+	"strconv"
+{{- end }}
 {{- if $needsStrings }}
 	"strings"
 {{- end }}
 {{- if $needsTime }}
 	"time"
 {{- end }}
+{{- if and $needsStd .Imports }}
+{{ end }}
+{{- range .Imports }}
+	{{ with .Name }}{{ . }} {{ end }}{{ printf "%q" .Path }}
+{{- end }}
 )
 {{- end }}
 
 {{- range $name, $def := .Definitions }}
 	{{- if eq $def.Type "template" }}
-// {{ $name }} template string
+{{ goDoc $def (printf "%s template string" $name) }}
 const {{ $name }}Template = {{ printf "%q" $def.Template }}
 
 // Build{{ $name }} builds the template string with provided parameters
@@ -31,13 +53,180 @@ func Build{{ $name }}({{- range $i, $param := $def.Parameters }}{{if $i}}, {{end
 	return result
 }
 
+	{{- else if $def.IsIntEnum }}
+{{ goDoc $def (printf "%s enum values" $name) }}
+type {{ $name }} int32
+
+const (
+	{{- range $value := $def.Values }}
+	{{- with goValueDoc $def $value }}
+	{{ . }}
+	{{- end }}
+	{{ $name }}{{ toTitle $value.Name }} {{ $name }} = {{ $value.Value }}
+	{{- end }}
+)
+
+// String returns the name of the {{ $name }} value
+func (v {{ $name }}) String() string {
+	switch v {
+	{{- range $value := $def.Values }}
+	case {{ $name }}{{ toTitle $value.Name }}:
+		return "{{ $value.Name }}"
+	{{- end }}
+	default:
+		return "{{ $name }}(" + strconv.FormatInt(int64(v), 10) + ")"
+	}
+}
+
+// IsValid{{ $name }} validates if the given number is a valid {{ $name }}
+func IsValid{{ $name }}(value int32) bool {
+	switch {{ $name }}(value) {
+	{{- range $value := $def.Values }}
+	case {{ $name }}{{ toTitle $value.Name }}:
+		return true
+	{{- end }}
+	default:
+		return false
+	}
+}
+
+// Parse{{ $name }} parses a name to {{ $name }} with error handling
+func Parse{{ $name }}(name string) ({{ $name }}, error) {
+	switch name {
+	{{- range $value := $def.Values }}
+	case "{{ $value.Name }}":
+		return {{ $name }}{{ toTitle $value.Name }}, nil
+	{{- end }}
+	default:
+		return 0, errors.New("invalid {{ $name }}: " + name)
+	}
+}
+
+// Parse{{ $name }}Number parses a number to {{ $name }} with error handling
+func Parse{{ $name }}Number(value int32) ({{ $name }}, error) {
+	if IsValid{{ $name }}(value) {
+		return {{ $name }}(value), nil
+	}
+	return 0, errors.New("invalid {{ $name }}: " + strconv.FormatInt(int64(value), 10))
+}
+
+// GetAll{{ $name }}Values returns all valid {{ $name }} values
+func GetAll{{ $name }}Values() []{{ $name }} {
+	return []{{ $name }}{
+		{{- range $value := $def.Values }}
+		{{ $name }}{{ toTitle $value.Name }},
+		{{- end }}
+	}
+}
+
+{{- if $def.Default }}
+// GetDefault{{ $name }} returns the default {{ $name }} value
+func GetDefault{{ $name }}() {{ $name }} {
+	return {{ $name }}{{ toTitle $def.Default }}
+}
+{{- end }}
+{{- if $.UsesGoEnumInterface "json" }}
+
+// MarshalJSON encodes {{ $name }} as its name
+func (v {{ $name }}) MarshalJSON() ([]byte, error) {
+	if !IsValid{{ $name }}(int32(v)) {
+		return nil, errors.New("invalid {{ $name }}: " + strconv.FormatInt(int64(v), 10))
+	}
+	return json.Marshal(v.String())
+}
+
+// UnmarshalJSON decodes {{ $name }} from its name or number
+func (v *{{ $name }}) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		parsed, err := Parse{{ $name }}(name)
+		if err != nil {
+			return err
+		}
+		*v = parsed
+		return nil
+	}
+	var number int32
+	if err := json.Unmarshal(data, &number); err != nil {
+		return errors.New("invalid {{ $name }}: " + string(data))
+	}
+	parsed, err := Parse{{ $name }}Number(number)
+	if err != nil {
+		return err
+	}
+	*v = parsed
+	return nil
+}
+{{- end }}
+
+{{- if $.UsesGoEnumInterface "text" }}
+
+// MarshalText encodes {{ $name }} as its name
+func (v {{ $name }}) MarshalText() ([]byte, error) {
+	if !IsValid{{ $name }}(int32(v)) {
+		return nil, errors.New("invalid {{ $name }}: " + strconv.FormatInt(int64(v), 10))
+	}
+	return []byte(v.String()), nil
+}
+
+// UnmarshalText decodes {{ $name }} from its name
+func (v *{{ $name }}) UnmarshalText(text []byte) error {
+	parsed, err := Parse{{ $name }}(string(text))
+	if err != nil {
+		return err
+	}
+	*v = parsed
+	return nil
+}
+{{- end }}
+
+{{- if $.UsesGoEnumInterface "sql" }}
+
+// Value stores {{ $name }} in the database as its number
+func (v {{ $name }}) Value() (driver.Value, error) {
+	if !IsValid{{ $name }}(int32(v)) {
+		return nil, errors.New("invalid {{ $name }}: " + strconv.FormatInt(int64(v), 10))
+	}
+	return int64(v), nil
+}
+
+// Scan reads {{ $name }} from a database number or name
+func (v *{{ $name }}) Scan(src any) error {
+	var (
+		parsed {{ $name }}
+		err    error
+	)
+	switch s := src.(type) {
+	case int64:
+		if int64(int32(s)) != s {
+			return errors.New("invalid {{ $name }}: " + strconv.FormatInt(s, 10))
+		}
+		parsed, err = Parse{{ $name }}Number(int32(s))
+	case string:
+		parsed, err = Parse{{ $name }}(s)
+	case []byte:
+		parsed, err = Parse{{ $name }}(string(s))
+	default:
+		return fmt.Errorf("cannot scan %T into {{ $name }}", src)
+	}
+	if err != nil {
+		return err
+	}
+	*v = parsed
+	return nil
+}
+{{- end }}
+
 	{{- else if eq $def.Type "enum" }}
-// {{ $name }} enum values
+{{ goDoc $def (printf "%s enum values" $name) }}
 type {{ $name }} string
 
 const (
-	{{- range $i, $value := $def.Values }}
-	{{ $name }}{{ toTitle $value }} {{ $name }} = "{{ $value }}"
+	{{- range $value := $def.Values }}
+	{{- with goValueDoc $def $value }}
+	{{ . }}
+	{{- end }}
+	{{ $name }}{{ toTitle $value.Name }} {{ $name }} = "{{ $value.Name }}"
 	{{- end }}
 )
 
@@ -45,7 +234,7 @@ const (
 func IsValid{{ $name }}(value string) bool {
 	switch value {
 	{{- range $value := $def.Values }}
-	case "{{ $value }}":
+	case "{{ $value.Name }}":
 		return true
 	{{- end }}
 	default:
@@ -65,7 +254,7 @@ func Parse{{ $name }}(value string) ({{ $name }}, error) {
 func GetAll{{ $name }}Values() []{{ $name }} {
 	return []{{ $name }}{
 		{{- range $value := $def.Values }}
-		{{ $name }}{{ toTitle $value }},
+		{{ $name }}{{ toTitle $value.Name }},
 		{{- end }}
 	}
 }
@@ -76,16 +265,135 @@ func GetDefault{{ $name }}() {{ $name }} {
 	return {{ $name }}{{ toTitle $def.Default }}
 }
 {{- end }}
+{{- if $.UsesGoEnumInterface "stringer" }}
 
-	{{- else if eq $def.Type "date" }}
-		{{- if eq $def.GoMode "string" }}
-const {{ $name }} = {{ formatConstValue $def }}
-		{{- else }}
+// String returns the {{ $name }} value as a string
+func (v {{ $name }}) String() string {
+	return string(v)
+}
+{{- end }}
+
+{{- if $.UsesGoEnumInterface "json" }}
+
+// MarshalJSON encodes {{ $name }} as a JSON string
+func (v {{ $name }}) MarshalJSON() ([]byte, error) {
+	if !IsValid{{ $name }}(string(v)) {
+		return nil, errors.New("invalid {{ $name }}: " + string(v))
+	}
+	return json.Marshal(string(v))
+}
+
+// UnmarshalJSON decodes {{ $name }} from a JSON string
+func (v *{{ $name }}) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return errors.New("invalid {{ $name }}: " + string(data))
+	}
+	parsed, err := Parse{{ $name }}(s)
+	if err != nil {
+		return err
+	}
+	*v = parsed
+	return nil
+}
+{{- end }}
+
+{{- if $.UsesGoEnumInterface "text" }}
+
+// MarshalText encodes {{ $name }} as text
+func (v {{ $name }}) MarshalText() ([]byte, error) {
+	if !IsValid{{ $name }}(string(v)) {
+		return nil, errors.New("invalid {{ $name }}: " + string(v))
+	}
+	return []byte(v), nil
+}
+
+// UnmarshalText decodes {{ $name }} from text
+func (v *{{ $name }}) UnmarshalText(text []byte) error {
+	parsed, err := Parse{{ $name }}(string(text))
+	if err != nil {
+		return err
+	}
+	*v = parsed
+	return nil
+}
+{{- end }}
+
+{{- if $.UsesGoEnumInterface "sql" }}
+
+// Value stores {{ $name }} in the database as a string
+func (v {{ $name }}) Value() (driver.Value, error) {
+	if !IsValid{{ $name }}(string(v)) {
+		return nil, errors.New("invalid {{ $name }}: " + string(v))
+	}
+	return string(v), nil
+}
+
+// Scan reads {{ $name }} from a database string
+func (v *{{ $name }}) Scan(src any) error {
+	var s string
+	switch src := src.(type) {
+	case string:
+		s = src
+	case []byte:
+		s = string(src)
+	default:
+		return fmt.Errorf("cannot scan %T into {{ $name }}", src)
+	}
+	parsed, err := Parse{{ $name }}(s)
+	if err != nil {
+		return err
+	}
+	*v = parsed
+	return nil
+}
+{{- end }}
+
+	{{- else if eq $def.Type "object" }}
+{{ goObjectTypes $def }}
+
+{{ goDoc $def (printf "%s constant values" $name) }}
 var {{ $name }} = {{ formatConstValue $def }}
+
+	{{- else if eq $def.Type "table" }}
+{{ goTableTypes $def }}
+
+{{ goDoc $def (printf "%sRows lists all %s rows" $name $name) }}
+var {{ $name }}Rows = {{ formatConstValue $def }}
+		{{- range $key := tableKeys $def }}
+
+var {{ $key.Index }} = map[{{ $key.GoType }}]int{
+			{{- range $row := $key.Rows }}
+	{{ $row.Go }}: {{ $row.Row }},
+			{{- end }}
+}
+
+// {{ $name }}By{{ $key.Name }} returns the {{ $name }} row whose {{ $key.Column }} is the given key
+func {{ $name }}By{{ $key.Name }}(key {{ $key.GoType }}) ({{ $name }}, bool) {
+	i, ok := {{ $key.Index }}[key]
+	if !ok {
+		return {{ $name }}{}, false
+	}
+	return {{ $name }}Rows[i], true
+}
+		{{- end }}
+
+	{{- else }}
+		{{- with goDoc $def "" }}
+{{ . }}
 		{{- end }}
-	{{- else if (contains (asString $def.Type) "[]") }}
+		{{- if eq $def.Type "date" }}
+			{{- if eq $def.GoMode "string" }}
+const {{ $name }} {{ goType $def }} = {{ formatConstValue $def }}
+			{{- else if eq $def.GoMode "int64" }}
+var {{ $name }} {{ goType $def }} = {{ formatConstValue $def }}
+			{{- else }}
 var {{ $name }} = {{ formatConstValue $def }}
-	{{- else if and (ne $def.Type "template") (ne $def.Type "enum") }}
-const {{ $name }} = {{ formatConstValue $def }}
+			{{- end }}
+		{{- else if or (contains (asString $def.Type) "[]") (eq $def.Type "map") }}
+var {{ $name }} = {{ formatConstValue $def }}
+		{{- else }}
+const {{ $name }}{{ with goType $def }} {{ . }}{{ end }} = {{ formatConstValue $def }}
+		{{- end }}
 	{{- end }}
 {{- end }}`
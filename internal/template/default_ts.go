@@ -1,8 +1,13 @@
 package template
 
-const defaultTSTemplate = `{{- range $name, $def := .Definitions -}}
+const defaultTSTemplate = `{{- range .Imports }}
+import { {{ join .Names ", " }} } from '{{ .Path }}';
+{{- end }}
+{{- if .Imports }}
+{{ end }}
+{{- range $name, $def := .Definitions -}}
 {{- if eq $def.Type "template" }}
-// {{ $name }} template string
+{{ tsDoc $def (printf "%s template string" $name) }}
 export const {{ $name }}Template = {{ printf "%q" $def.Template }};
 
 // Build{{ $name }} builds the template string with provided parameters
@@ -14,11 +19,84 @@ export function build{{ $name }}(params: { {{- range $i, $param := $def.Paramete
 	return result;
 }
 
+{{- else if $def.IsIntEnum }}
+{{ tsDoc $def (printf "%s enum values" $name) }}
+export const {{ $name }} = {
+	{{- range $value := $def.Values }}
+	{{- with tsValueDoc $def $value }}
+	{{ . }}
+	{{- end }}
+	{{ toTitle $value.Name }}: {{ $value.Value }},
+	{{- end }}
+} as const;
+
+export type {{ $name }}Type = typeof {{ $name }}[keyof typeof {{ $name }}];
+
+const {{ $name }}Names: Record<{{ $name }}Type, string> = {
+	{{- range $value := $def.Values }}
+	[{{ $name }}.{{ toTitle $value.Name }}]: "{{ $value.Name }}",
+	{{- end }}
+};
+
+// Type guard for {{ $name }}
+export function isValid{{ $name }}(value: number): value is {{ $name }}Type {
+	return Object.prototype.hasOwnProperty.call({{ $name }}Names, value);
+}
+
+// Get the name of a {{ $name }} value
+export function get{{ $name }}Name(value: {{ $name }}Type): string {
+	return {{ $name }}Names[value];
+}
+
+// Parser for {{ $name }} names with exception
+export function parse{{ $name }}(name: string): {{ $name }}Type {
+	const value = parse{{ $name }}Safe(name);
+	if (value === undefined) {
+		throw new Error("Invalid {{ $name }}: " + name);
+	}
+	return value;
+}
+
+// Safe parser for {{ $name }} names returning undefined on error
+export function parse{{ $name }}Safe(name: string): {{ $name }}Type | undefined {
+	switch (name) {
+	{{- range $value := $def.Values }}
+	case "{{ $value.Name }}":
+		return {{ $name }}.{{ toTitle $value.Name }};
+	{{- end }}
+	default:
+		return undefined;
+	}
+}
+
+// Parser for {{ $name }} numbers with exception
+export function parse{{ $name }}Number(value: number): {{ $name }}Type {
+	if (isValid{{ $name }}(value)) {
+		return value;
+	}
+	throw new Error("Invalid {{ $name }}: " + value);
+}
+
+// Get all {{ $name }} values
+export function getAll{{ $name }}Values(): {{ $name }}Type[] {
+	return Object.values({{ $name }});
+}
+
+{{- if $def.Default }}
+// Get default {{ $name }} value
+export function getDefault{{ $name }}(): {{ $name }}Type {
+	return {{ $name }}.{{ toTitle $def.Default }};
+}
+{{- end }}
+
 {{- else if eq $def.Type "enum" }}
-// {{ $name }} enum values
+{{ tsDoc $def (printf "%s enum values" $name) }}
 export const {{ $name }} = {
 	{{- range $value := $def.Values }}
-	{{ toTitle $value }}: "{{ $value }}",
+	{{- with tsValueDoc $def $value }}
+	{{ . }}
+	{{- end }}
+	{{ toTitle $value.Name }}: "{{ $value.Name }}",
 	{{- end }}
 } as const;
 
@@ -54,7 +132,46 @@ export function getDefault{{ $name }}(): {{ $name }}Type {
 }
 {{- end }}
 
+{{- else if eq $def.Type "object" }}
+{{ tsObjectTypes $def }}
+
+{{ tsDoc $def (printf "%s constant values" $name) }}
+export const {{ $name }}: Readonly<{{ tsType $def }}> = {{ formatTSConstValue $def }};
+
+{{- else if eq $def.Type "table" }}
+{{ tsTableTypes $def }}
+
+{{ tsDoc $def (printf "%sRows lists all %s rows" $name $name) }}
+export const {{ $name }}Rows: readonly {{ $name }}[] = {{ formatTSConstValue $def }};
+{{- range $key := tableKeys $def }}
+
+const {{ $key.Index }}: ReadonlyMap<{{ $key.TSType }}, number> = new Map([
+	{{- range $row := $key.Rows }}
+	[{{ $row.TS }}, {{ $row.Row }}],
+	{{- end }}
+]);
+
+// Get the {{ $name }} row whose {{ $key.Column }} is the given key
+export function get{{ $name }}By{{ $key.Name }}(key: {{ $key.TSType }}): {{ $name }} | undefined {
+	const i = {{ $key.Index }}.get(key);
+	return i === undefined ? undefined : {{ $name }}Rows[i];
+}
+{{- end }}
+
+{{- else if eq $def.Type "duration" }}
+{{ tsDoc $def (printf "%s in %s" $name (tsDurationUnit $def)) }}
+export const {{ $name }} = {{ formatTSConstValue $def }};
+
+{{- else if eq $def.Type "map" }}
+{{- with tsDoc $def "" }}
+{{ . }}
+{{- end }}
+export const {{ $name }}: {{ tsType $def }} = {{ formatTSConstValue $def }};
+
 {{- else }}
+{{- with tsDoc $def "" }}
+{{ . }}
+{{- end }}
 export const {{ $name }} = {{ formatTSConstValue $def }};
 {{- end }}
 {{ end }}`
@@ -1,8 +1,11 @@
 package template
 
 import (
+	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/nantokaworks/konst/internal/types"
 	"github.com/nantokaworks/konst/internal/utils"
@@ -18,6 +21,8 @@ func formatGo(value any) string {
 	switch v := value.(type) {
 	case string:
 		return formatGoString(v)
+	case json.Number:
+		return formatGoNumber(v)
 	case float64:
 		return formatGoFloat(v)
 	case bool:
@@ -41,12 +46,15 @@ func formatGoString(v string) string {
 	return fmt.Sprintf("%q", v)
 }
 
-// formatGoFloat は浮動小数点数をフォーマットします
+// formatGoNumber は数値を JSON に書かれた表記のままフォーマットします
+func formatGoNumber(v json.Number) string {
+	return v.String()
+}
+
+// formatGoFloat は浮動小数点数を値を損なわない最短の表記でフォーマットします
 func formatGoFloat(v float64) string {
-	if v == float64(int(v)) {
-		return fmt.Sprintf("%d", int(v))
-	}
-	return fmt.Sprintf("%f", v)
+	n, _ := utils.AsNumber(v)
+	return formatGoNumber(n)
 }
 
 // formatGoBool はbool値をフォーマットします
@@ -70,7 +78,7 @@ func formatGoSlice(v []any) string {
 	// 型の統一性をチェック
 	allNumbers, allStrings, allBools := true, true, true
 	for _, elem := range v {
-		if _, ok := elem.(float64); !ok {
+		if _, ok := utils.AsNumber(elem); !ok {
 			allNumbers = false
 		}
 		if _, ok := elem.(string); !ok {
@@ -98,19 +106,16 @@ func formatGoSlice(v []any) string {
 }
 
 // formatGoNumberSlice は数値スライスをフォーマットします
+// 全ての要素が整数値なら []int、それ以外は []float64 として出力します
 func formatGoNumberSlice(v []any) string {
 	isInt := true
 	var elems []string
 	for _, elem := range v {
-		num := elem.(float64)
-		if num != float64(int(num)) {
+		num, _ := utils.AsNumber(elem)
+		if !utils.IsIntegerNumber(num) {
 			isInt = false
 		}
-		if isInt {
-			elems = append(elems, fmt.Sprintf("%d", int(num)))
-		} else {
-			elems = append(elems, fmt.Sprintf("%f", num))
-		}
+		elems = append(elems, formatGoNumber(num))
 	}
 	if isInt {
 		return "[]int{" + strings.Join(elems, ", ") + "}"
@@ -146,9 +151,14 @@ func formatConstValue(content any) string {
 	if !ok {
 		return formatGo(content)
 	}
+	// 参照を式のまま出力する場合
+	if def.Code != "" {
+		return def.Code
+	}
 
 	switch def.Type {
 	case types.DefinitionTypeInt, types.DefinitionTypeInt32, types.DefinitionTypeInt64,
+		types.DefinitionTypeUint, types.DefinitionTypeUint32, types.DefinitionTypeUint64,
 		types.DefinitionTypeFloat, types.DefinitionTypeFloat32, types.DefinitionTypeFloat64:
 		return formatGo(def.Value)
 	case types.DefinitionTypeString:
@@ -159,6 +169,14 @@ func formatConstValue(content any) string {
 		return formatGoDate(def)
 	case types.DefinitionTypeTimestamp:
 		return formatGoTimestamp(def)
+	case types.DefinitionTypeDuration:
+		return formatGoDuration(def)
+	case types.DefinitionTypeObject:
+		return formatGoObject(def, 0)
+	case types.DefinitionTypeTable:
+		return formatGoTable(def)
+	case types.DefinitionTypeMap:
+		return formatGoMap(def)
 	default:
 		// 配列型の場合
 		if strings.Contains(string(def.Type), "[]") {
@@ -171,6 +189,9 @@ func formatConstValue(content any) string {
 // formatGoDate は日付型の値をフォーマットします
 func formatGoDate(def types.Definition) string {
 	if def.GoMode == types.GoModeString {
+		if dateStr, ok := def.Value.(string); ok {
+			return fmt.Sprintf("%q", dateStr)
+		}
 		return formatGo(def.Value)
 	}
 	if def.GoMode == types.GoModeInt64 {
@@ -194,32 +215,262 @@ func formatGoTimestamp(def types.Definition) string {
 	return formatGo(def.Value)
 }
 
-// formatGoArray は配列型の値をフォーマットします
+// formatGoDuration は期間型の値を time パッケージの単位を使った式（1*time.Hour + 30*time.Minute など）としてフォーマットします
+func formatGoDuration(def types.Definition) string {
+	s, ok := def.Value.(string)
+	if !ok {
+		return formatGo(def.Value)
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return formatGo(def.Value)
+	}
+	return utils.GoDuration(d)
+}
+
+// formatGoArray は配列型の値を要素型付きのスライスリテラルとしてフォーマットします
 func formatGoArray(def types.Definition) string {
 	arrayValue, ok := def.Value.([]any)
 	if !ok {
 		return "nil"
 	}
 
-	var elements []string
-	baseType := strings.TrimSuffix(string(def.Type), "[]")
+	elemDef := arrayElementDefinition(def)
+	elemType := goType(elemDef)
+	if elemType == "" {
+		// 要素型が不明な場合は値から型を推測する
+		return formatGoSlice(arrayValue)
+	}
 
+	var elements []string
 	for _, elem := range arrayValue {
-		switch baseType {
-		case "date":
-			tempDef := types.Definition{
-				Type:   types.DefinitionTypeDate,
-				Value:  elem,
-				TSMode: def.TSMode,
-				GoMode: def.GoMode,
-			}
-			elements = append(elements, formatGoDate(tempDef))
+		elemDef.Value = elem
+		elements = append(elements, formatConstValue(elemDef))
+	}
+
+	return "[]" + elemType + "{" + strings.Join(elements, ", ") + "}"
+}
+
+// formatGoObject は object 型の値を構造体リテラルとしてフォーマットします。
+// 入れ子の object は depth を1つ深くしたインデントで出力します。
+func formatGoObject(def types.Definition, depth int) string {
+	return formatGoStruct(goType(def), def, depth)
+}
+
+// formatGoStruct は def の Fields と Value から型名 typeName の構造体リテラルを作ります。
+// typeName が空の場合は型名を省略します（スライスの要素など）。
+func formatGoStruct(typeName string, def types.Definition, depth int) string {
+	obj, _ := def.Value.(map[string]any)
+	indent := strings.Repeat("\t", depth+1)
+
+	var b strings.Builder
+	b.WriteString(typeName + "{\n")
+	for _, f := range def.Fields {
+		goName := utils.ToPascalCase(f.Name)
+		fieldDef := f.Definition(def, goName, obj[f.Name])
+		value := formatConstValue(fieldDef)
+		if f.Type == types.DefinitionTypeObject {
+			value = formatGoObject(fieldDef, depth+1)
+		}
+		b.WriteString(indent + goName + ": " + value + ",\n")
+	}
+	b.WriteString(strings.Repeat("\t", depth) + "}")
+	return b.String()
+}
+
+// formatGoTable は table 型の行を行の構造体のスライスリテラルとしてフォーマットします
+func formatGoTable(def types.Definition) string {
+	if len(def.Rows) == 0 {
+		return "[]" + def.Name + "{}"
+	}
+	var b strings.Builder
+	b.WriteString("[]" + def.Name + "{\n")
+	for i := range def.Rows {
+		b.WriteString("\t" + formatGoStruct("", def.RowDefinition(i), 1) + ",\n")
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// formatGoMap は map 型の値をキーの順に並べた map リテラルとしてフォーマットします
+func formatGoMap(def types.Definition) string {
+	entries := mapEntries(def)
+	if len(entries) == 0 {
+		return goType(def) + "{}"
+	}
+	var b strings.Builder
+	b.WriteString(goType(def) + "{\n")
+	for _, e := range entries {
+		b.WriteString("\t" + goMapKey(def, e) + ": " + formatConstValue(def.ValueDefinition(e.Value)) + ",\n")
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// goMapKey は map のキーの Go のリテラルを返します。参照できる enum のキーは enum の定数で出力します
+func goMapKey(def types.Definition, e mapEntry) string {
+	switch {
+	case e.Member != nil && isLocalKeyEnum(def):
+		return string(def.KeyType) + toTitle(e.Member.Name)
+	case e.Member != nil && def.KeyEnumRef != "":
+		return def.KeyEnumRef + toTitle(e.Member.Name)
+	case e.Member != nil:
+		return enumMemberLiteral(*e.Member)
+	case utils.IsIntegerType(def.KeyType):
+		return integerKeyLiteral(e.Key)
+	default:
+		return strconv.Quote(e.Key)
+	}
+}
+
+// goMapKeyType は map のキーの Go の型名を返します。
+// 別のファイルの enum のキーは、生成時に参照する名前（KeyEnumRef）が決まらなかった場合は enum の元の型（string または int32）にします。
+func goMapKeyType(def types.Definition) string {
+	switch {
+	case isLocalKeyEnum(def):
+		return string(def.KeyType)
+	case def.KeyEnumRef != "":
+		return def.KeyEnumRef
+	case def.KeyEnum != nil && def.KeyEnum.IsIntEnum():
+		return "int32"
+	case utils.IsIntegerType(def.KeyType):
+		return goType(types.Definition{Type: def.KeyType})
+	default:
+		return "string"
+	}
+}
+
+// goObjectTypes は object 型の定義から構造体の型宣言を作ります。
+// 入れ子の object には「親の型名 + フィールド名」の型を宣言し、親の後に並べます。
+func goObjectTypes(def types.Definition) string {
+	return strings.Join(goStructTypes(goType(def), "the type of "+def.Name, def.Name, def), "\n\n")
+}
+
+// goTableTypes は table 型の定義から行の構造体（定義名の型）の型宣言を作ります
+func goTableTypes(def types.Definition) string {
+	row := types.Definition{Name: def.Name, Fields: def.Columns}
+	return strings.Join(goStructTypes(def.Name, "a row of "+def.Name+"Rows", def.Name, row), "\n\n")
+}
+
+// goStructTypes は def の Fields から型名 typeName の構造体と、入れ子の object の構造体の型宣言を作ります。
+// about は型のコメントに使う説明、path は入れ子の型のコメントに使うフィールドの位置です。
+func goStructTypes(typeName, about, path string, def types.Definition) []string {
+	var b strings.Builder
+	var nested []types.Definition
+	var paths []string
+	fmt.Fprintf(&b, "// %s is %s\n", typeName, about)
+	b.WriteString("type " + typeName + " struct {\n")
+	for _, f := range def.Fields {
+		goName := utils.ToPascalCase(f.Name)
+		fieldDef := f.Definition(def, goName, nil)
+		if f.Description != "" {
+			b.WriteString("\t" + strings.Join(goCommentLines(strings.Split(f.Description, "\n")), "\n\t") + "\n")
+		}
+		fmt.Fprintf(&b, "\t%s %s `json:%q`\n", goName, goType(fieldDef), f.Name)
+		if f.Type == types.DefinitionTypeObject {
+			nested = append(nested, fieldDef)
+			paths = append(paths, path+"."+goName)
+		}
+	}
+	b.WriteString("}")
+
+	decls := []string{b.String()}
+	for i, n := range nested {
+		decls = append(decls, goStructTypes(goType(n), "the type of "+paths[i], paths[i], n)...)
+	}
+	return decls
+}
+
+// arrayElementDefinition は配列型の定義から要素1つ分の定義（値なし）を作ります
+func arrayElementDefinition(def types.Definition) types.Definition {
+	return types.Definition{
+		Type:   types.DefinitionType(strings.TrimSuffix(string(def.Type), "[]")),
+		TSMode: def.TSMode,
+		GoMode: def.GoMode,
+	}
+}
+
+// goType は定義を宣言する Go の型名を返します。
+// float は float32、timestamp は Unix 秒の int64、duration は time.Duration、object は「定義名 + Type」の構造体、map は map[キー]値として扱います。型が決まらない場合は空文字列を返します。
+func goType(def types.Definition) string {
+	switch def.Type {
+	case types.DefinitionTypeInt, types.DefinitionTypeInt32, types.DefinitionTypeInt64,
+		types.DefinitionTypeUint, types.DefinitionTypeUint32, types.DefinitionTypeUint64,
+		types.DefinitionTypeFloat32, types.DefinitionTypeFloat64,
+		types.DefinitionTypeString, types.DefinitionTypeBool:
+		return string(def.Type)
+	case types.DefinitionTypeFloat:
+		return "float32"
+	case types.DefinitionTypeTimestamp:
+		return "int64"
+	case types.DefinitionTypeDuration:
+		return "time.Duration"
+	case types.DefinitionTypeObject:
+		return def.Name + "Type"
+	case types.DefinitionTypeMap:
+		return "map[" + goMapKeyType(def) + "]" + goType(def.ValueDefinition(nil))
+	case types.DefinitionTypeDate:
+		switch def.GoMode {
+		case types.GoModeString:
+			return "string"
+		case types.GoModeInt64:
+			return "int64"
 		default:
-			elements = append(elements, formatGo(elem))
+			return "time.Time"
+		}
+	}
+	if strings.HasSuffix(string(def.Type), "[]") {
+		if elemType := goType(arrayElementDefinition(def)); elemType != "" {
+			return "[]" + elemType
+		}
+	}
+	return ""
+}
+
+// ============================================================================
+// ドキュメントコメント
+// ============================================================================
+
+// goDoc は定義の説明を Go のドキュメントコメント（// 形式）にします。
+// 説明がない場合は fallback を使います。非推奨の定義には標準の "Deprecated:" 段落を付けます。
+func goDoc(def types.Definition, fallback string) string {
+	text := docText(def)
+	if text == "" {
+		text = fallback
+	}
+	var lines []string
+	if text != "" {
+		lines = strings.Split(text, "\n")
+	}
+	if def.Deprecated.Deprecated {
+		if len(lines) > 0 {
+			lines = append(lines, "")
 		}
+		lines = append(lines, "Deprecated: "+deprecationMessage(def))
+	}
+	return strings.Join(goCommentLines(lines), "\n")
+}
+
+// goValueDoc は enum 値の説明を const ブロック内のコメントにします。説明がなければ空文字列を返します
+func goValueDoc(def types.Definition, value types.EnumValue) string {
+	desc := enumValueDescription(def, value)
+	if desc == "" {
+		return ""
 	}
+	return strings.Join(goCommentLines(strings.Split(desc, "\n")), "\n\t")
+}
 
-	return "{" + strings.Join(elements, ", ") + "}"
+// goCommentLines は各行を // コメントにします
+func goCommentLines(lines []string) []string {
+	comments := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line == "" {
+			comments = append(comments, "//")
+		} else {
+			comments = append(comments, "// "+line)
+		}
+	}
+	return comments
 }
 
 // ============================================================================
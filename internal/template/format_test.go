@@ -0,0 +1,488 @@
+package template
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/nantokaworks/konst/internal/types"
+)
+
+func TestFormatConstValueNumbers(t *testing.T) {
+	tests := []struct {
+		name     string
+		def      types.Definition
+		expected string
+	}{
+		{"Int64 max", types.Definition{Type: types.DefinitionTypeInt64, Value: json.Number("9223372036854775807")}, "9223372036854775807"},
+		{"Uint64 max", types.Definition{Type: types.DefinitionTypeUint64, Value: json.Number("18446744073709551615")}, "18446744073709551615"},
+		{"High precision float", types.Definition{Type: types.DefinitionTypeFloat64, Value: json.Number("1.23456789")}, "1.23456789"},
+		{"Exponent", types.Definition{Type: types.DefinitionTypeFloat64, Value: json.Number("6.02214076e23")}, "6.02214076e23"},
+		{"Float64 fallback", types.Definition{Type: types.DefinitionTypeFloat64, Value: 1.23456789}, "1.23456789"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := formatConstValue(tt.def); result != tt.expected {
+				t.Errorf("formatConstValue() = %q, expected %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatTSConstValueNumbers(t *testing.T) {
+	tests := []struct {
+		name     string
+		def      types.Definition
+		expected string
+	}{
+		{"Int64 as number", types.Definition{Type: types.DefinitionTypeInt64, Value: json.Number("9223372036854775807"), TSMode: types.ModeNumber}, "9223372036854775807"},
+		{"Uint64 as bigint", types.Definition{Type: types.DefinitionTypeUint64, Value: json.Number("18446744073709551615"), TSMode: types.ModeBigInt}, "18446744073709551615n"},
+		{"Exponent as bigint", types.Definition{Type: types.DefinitionTypeInt64, Value: json.Number("1e3"), TSMode: types.ModeBigInt}, "1000n"},
+		{"Fraction ignores bigint", types.Definition{Type: types.DefinitionTypeFloat64, Value: json.Number("9.87654321"), TSMode: types.ModeBigInt}, "9.87654321"},
+		{"Int64 as string", types.Definition{Type: types.DefinitionTypeInt64, Value: json.Number("9223372036854775807"), TSMode: types.ModeString}, `"9223372036854775807"`},
+		{"Number array", types.Definition{Type: "float64[]", Value: []interface{}{json.Number("1"), json.Number("2.5")}}, "[1, 2.5]"},
+		{"Uint64 array as bigint", types.Definition{Type: "uint64[]", Value: []interface{}{json.Number("1"), json.Number("18446744073709551615")}, TSMode: types.ModeBigInt}, "[1n, 18446744073709551615n]"},
+		{"Int64 array as string", types.Definition{Type: "int64[]", Value: []interface{}{json.Number("9223372036854775807")}, TSMode: types.ModeString}, `["9223372036854775807"]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := formatTSConstValue(tt.def); result != tt.expected {
+				t.Errorf("formatTSConstValue() = %q, expected %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatGoSliceNumbers(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []any
+		expected string
+	}{
+		{"Integers", []any{json.Number("1"), json.Number("9223372036854775807")}, "[]int{1, 9223372036854775807}"},
+		{"Mixed integers and floats", []any{json.Number("1"), json.Number("2.5")}, "[]float64{1, 2.5}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := formatGoSlice(tt.input); result != tt.expected {
+				t.Errorf("formatGoSlice() = %q, expected %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGoType(t *testing.T) {
+	tests := []struct {
+		def      types.Definition
+		expected string
+	}{
+		{types.Definition{Type: types.DefinitionTypeInt32}, "int32"},
+		{types.Definition{Type: types.DefinitionTypeUint}, "uint"},
+		{types.Definition{Type: types.DefinitionTypeFloat}, "float32"},
+		{types.Definition{Type: types.DefinitionTypeTimestamp}, "int64"},
+		{types.Definition{Type: types.DefinitionTypeDate}, "time.Time"},
+		{types.Definition{Type: types.DefinitionTypeDate, GoMode: types.GoModeString}, "string"},
+		{types.Definition{Type: "float[]"}, "[]float32"},
+		{types.Definition{Type: "date[]", GoMode: types.GoModeInt64}, "[]int64"},
+		{types.Definition{Type: "decimal"}, ""},
+		{types.Definition{Type: "decimal[]"}, ""},
+	}
+
+	for _, tt := range tests {
+		if result := goType(tt.def); result != tt.expected {
+			t.Errorf("goType(%s) = %q, expected %q", tt.def.Type, result, tt.expected)
+		}
+	}
+}
+
+func TestFormatConstValueTypedArrays(t *testing.T) {
+	tests := []struct {
+		name     string
+		def      types.Definition
+		expected string
+	}{
+		{"Int32 array", types.Definition{Type: "int32[]", Value: []any{json.Number("1"), json.Number("2")}}, "[]int32{1, 2}"},
+		{"Float array", types.Definition{Type: "float[]", Value: []any{json.Number("1.5")}}, "[]float32{1.5}"},
+		{"String array", types.Definition{Type: "string[]", Value: []any{"a", "b"}}, `[]string{"a", "b"}`},
+		{"Date array as string", types.Definition{Type: "date[]", GoMode: types.GoModeString, Value: []any{"2024-01-01T00:00:00Z"}}, `[]string{"2024-01-01T00:00:00Z"}`},
+		{"Timestamp array", types.Definition{Type: "timestamp[]", Value: []any{"1970-01-01T00:00:10Z"}}, "[]int64{10}"},
+		{"Unknown element type", types.Definition{Type: "decimal[]", Value: []any{json.Number("1")}}, "[]int{1}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := formatConstValue(tt.def); result != tt.expected {
+				t.Errorf("formatConstValue() = %q, expected %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func testObjectDefinition() types.Definition {
+	return types.Definition{
+		Name: "RateLimit",
+		Type: types.DefinitionTypeObject,
+		Fields: []types.Field{
+			{Name: "limit", Type: types.DefinitionTypeInt, Description: "Requests per window"},
+			{Name: "hosts", Type: "string[]"},
+			{Name: "retry", Type: types.DefinitionTypeObject, Fields: []types.Field{
+				{Name: "max_count", Type: types.DefinitionTypeInt64, TSMode: types.ModeBigInt},
+			}},
+		},
+		Value: map[string]any{
+			"limit": json.Number("100"),
+			"hosts": []any{"a"},
+			"retry": map[string]any{"max_count": json.Number("3")},
+		},
+	}
+}
+
+func TestFormatObject(t *testing.T) {
+	def := testObjectDefinition()
+
+	expectedGo := "RateLimitType{\n" +
+		"\tLimit: 100,\n" +
+		"\tHosts: []string{\"a\"},\n" +
+		"\tRetry: RateLimitRetryType{\n" +
+		"\t\tMaxCount: 3,\n" +
+		"\t},\n" +
+		"}"
+	if result := formatConstValue(def); result != expectedGo {
+		t.Errorf("formatConstValue() = %q, expected %q", result, expectedGo)
+	}
+
+	expectedTS := "{\n" +
+		"\tlimit: 100,\n" +
+		"\thosts: [\"a\"],\n" +
+		"\tretry: {\n" +
+		"\t\tmax_count: 3n,\n" +
+		"\t},\n" +
+		"}"
+	if result := formatTSConstValue(def); result != expectedTS {
+		t.Errorf("formatTSConstValue() = %q, expected %q", result, expectedTS)
+	}
+}
+
+func TestObjectTypes(t *testing.T) {
+	def := testObjectDefinition()
+
+	expectedGo := "// RateLimitType is the type of RateLimit\n" +
+		"type RateLimitType struct {\n" +
+		"\t// Requests per window\n" +
+		"\tLimit int `json:\"limit\"`\n" +
+		"\tHosts []string `json:\"hosts\"`\n" +
+		"\tRetry RateLimitRetryType `json:\"retry\"`\n" +
+		"}\n\n" +
+		"// RateLimitRetryType is the type of RateLimit.Retry\n" +
+		"type RateLimitRetryType struct {\n" +
+		"\tMaxCount int64 `json:\"max_count\"`\n" +
+		"}"
+	if result := goObjectTypes(def); result != expectedGo {
+		t.Errorf("goObjectTypes() = %q, expected %q", result, expectedGo)
+	}
+
+	expectedTS := "// RateLimitType is the type of RateLimit\n" +
+		"export interface RateLimitType {\n" +
+		"\t/** Requests per window */\n" +
+		"\treadonly limit: number;\n" +
+		"\treadonly hosts: readonly string[];\n" +
+		"\treadonly retry: RateLimitRetryType;\n" +
+		"}\n\n" +
+		"// RateLimitRetryType is the type of RateLimit.retry\n" +
+		"export interface RateLimitRetryType {\n" +
+		"\treadonly max_count: bigint;\n" +
+		"}"
+	if result := tsObjectTypes(def); result != expectedTS {
+		t.Errorf("tsObjectTypes() = %q, expected %q", result, expectedTS)
+	}
+}
+
+func TestTable(t *testing.T) {
+	def := types.Definition{
+		Name: "Plan",
+		Type: types.DefinitionTypeTable,
+		Columns: []types.Field{
+			{Name: "id", Type: types.DefinitionTypeInt, Key: true},
+			{Name: "name", Type: types.DefinitionTypeString, Key: true},
+			{Name: "price", Type: types.DefinitionTypeFloat64},
+		},
+		Rows: []map[string]any{
+			{"id": json.Number("1"), "name": "free", "price": json.Number("0")},
+			{"id": json.Number("2"), "name": "pro", "price": json.Number("9.5")},
+		},
+	}
+
+	expectedGo := "[]Plan{\n" +
+		"\t{\n\t\tID: 1,\n\t\tName: \"free\",\n\t\tPrice: 0,\n\t},\n" +
+		"\t{\n\t\tID: 2,\n\t\tName: \"pro\",\n\t\tPrice: 9.5,\n\t},\n" +
+		"}"
+	if result := formatConstValue(def); result != expectedGo {
+		t.Errorf("formatConstValue() = %q, expected %q", result, expectedGo)
+	}
+	expectedTS := "[\n" +
+		"\t{\n\t\tid: 1,\n\t\tname: \"free\",\n\t\tprice: 0,\n\t},\n" +
+		"\t{\n\t\tid: 2,\n\t\tname: \"pro\",\n\t\tprice: 9.5,\n\t},\n" +
+		"]"
+	if result := formatTSConstValue(def); result != expectedTS {
+		t.Errorf("formatTSConstValue() = %q, expected %q", result, expectedTS)
+	}
+	if result := goTableTypes(def); !strings.HasPrefix(result, "// Plan is a row of PlanRows\ntype Plan struct {\n\tID int `json:\"id\"`") {
+		t.Errorf("goTableTypes() = %q", result)
+	}
+
+	keys := tableKeys(def)
+	if len(keys) != 2 {
+		t.Fatalf("Expected 2 keys, got %d", len(keys))
+	}
+	byName := keys[1]
+	if byName.Name != "Name" || byName.Index != "planByNameIndex" || byName.GoType != "string" || byName.TSType != "string" {
+		t.Errorf("Unexpected key: %+v", byName)
+	}
+	if len(byName.Rows) != 2 || byName.Rows[1] != (tableKeyRow{Go: `"pro"`, TS: `"pro"`, Row: 1}) {
+		t.Errorf("Unexpected key rows: %+v", byName.Rows)
+	}
+}
+
+func TestDuration(t *testing.T) {
+	tests := []struct {
+		value      string
+		tsMode     types.TSMode
+		expectedGo string
+		expectedTS string
+	}{
+		{"30s", "", "30 * time.Second", "30000"},
+		{"1h30m", types.ModeSeconds, "1*time.Hour + 30*time.Minute", "5400"},
+		{"250ms", types.ModeSeconds, "250 * time.Millisecond", "0.25"},
+		{"1500us", types.ModeMilliseconds, "1*time.Millisecond + 500*time.Microsecond", "1.5"},
+		{"-1m5s", "", "-(1*time.Minute + 5*time.Second)", "-65000"},
+		{"0s", "", "0", "0"},
+	}
+
+	for _, tt := range tests {
+		def := types.Definition{Type: types.DefinitionTypeDuration, Value: tt.value, TSMode: tt.tsMode}
+		if result := formatConstValue(def); result != tt.expectedGo {
+			t.Errorf("formatConstValue(%q) = %q, expected %q", tt.value, result, tt.expectedGo)
+		}
+		if result := formatTSConstValue(def); result != tt.expectedTS {
+			t.Errorf("formatTSConstValue(%q, %s) = %q, expected %q", tt.value, tt.tsMode, result, tt.expectedTS)
+		}
+	}
+
+	arr := types.Definition{Type: "duration[]", Value: []any{"100ms", "5s"}, TSMode: types.ModeSeconds}
+	if result := formatConstValue(arr); result != "[]time.Duration{100 * time.Millisecond, 5 * time.Second}" {
+		t.Errorf("formatConstValue(duration[]) = %q", result)
+	}
+	if result := formatTSConstValue(arr); result != "[0.1, 5]" {
+		t.Errorf("formatTSConstValue(duration[]) = %q", result)
+	}
+}
+
+func TestMap(t *testing.T) {
+	status := &types.Definition{
+		Type:   types.DefinitionTypeEnum,
+		Values: []types.EnumValue{{Name: "active"}, {Name: "inactive"}},
+		Pos:    types.Position{File: "status.json"},
+	}
+	tests := []struct {
+		name       string
+		def        types.Definition
+		expectedGo string
+		expectedTS string
+		goType     string
+		tsType     string
+	}{
+		{
+			name:       "string keys",
+			def:        types.Definition{Type: types.DefinitionTypeMap, KeyType: types.DefinitionTypeString, ValueType: types.DefinitionTypeUint32, Value: map[string]any{"https": json.Number("443"), "http": json.Number("80")}},
+			expectedGo: "map[string]uint32{\n\t\"http\": 80,\n\t\"https\": 443,\n}",
+			expectedTS: "{\n\t\"http\": 80,\n\t\"https\": 443,\n}",
+			goType:     "map[string]uint32",
+			tsType:     "Readonly<Record<string, number>>",
+		},
+		{
+			name:       "integer keys in numeric order",
+			def:        types.Definition{Type: types.DefinitionTypeMap, KeyType: types.DefinitionTypeInt, ValueType: types.DefinitionTypeString, Value: map[string]any{"404": "Not Found", "1000": "Big", "200": "OK"}},
+			expectedGo: "map[int]string{\n\t200: \"OK\",\n\t404: \"Not Found\",\n\t1000: \"Big\",\n}",
+			expectedTS: "{\n\t200: \"OK\",\n\t404: \"Not Found\",\n\t1000: \"Big\",\n}",
+			goType:     "map[int]string",
+			tsType:     "Readonly<Record<number, string>>",
+		},
+		{
+			name:       "enum keys in the same file",
+			def:        types.Definition{Name: "UserStatusLabels", Type: types.DefinitionTypeMap, KeyType: "UserStatus", ValueType: types.DefinitionTypeString, KeyEnum: status, Pos: types.Position{File: "status.json"}, Value: map[string]any{"inactive": "Inactive", "active": "Active"}},
+			expectedGo: "map[UserStatus]string{\n\tUserStatusActive: \"Active\",\n\tUserStatusInactive: \"Inactive\",\n}",
+			expectedTS: "{\n\t[UserStatus.Active]: \"Active\",\n\t[UserStatus.Inactive]: \"Inactive\",\n}",
+			goType:     "map[UserStatus]string",
+			tsType:     "Readonly<Record<UserStatusType, string>>",
+		},
+		{
+			// TypeScript では enum が map より後に出力されるため、読み込み時に評価される計算プロパティを使わない
+			name:       "enum keys in the same file declared after the map",
+			def:        types.Definition{Name: "Labels", Type: types.DefinitionTypeMap, KeyType: "UserStatus", ValueType: types.DefinitionTypeString, KeyEnum: status, Pos: types.Position{File: "status.json"}, Value: map[string]any{"inactive": "Inactive", "active": "Active"}},
+			expectedGo: "map[UserStatus]string{\n\tUserStatusActive: \"Active\",\n\tUserStatusInactive: \"Inactive\",\n}",
+			expectedTS: "{\n\t\"active\": \"Active\",\n\t\"inactive\": \"Inactive\",\n}",
+			goType:     "map[UserStatus]string",
+			tsType:     "Readonly<Record<UserStatusType, string>>",
+		},
+		{
+			name:       "enum keys in another file",
+			def:        types.Definition{Type: types.DefinitionTypeMap, KeyType: "UserStatus", ValueType: types.DefinitionTypeString, KeyEnum: status, Pos: types.Position{File: "labels.json"}, Value: map[string]any{"active": "Active", "inactive": "Inactive"}},
+			expectedGo: "map[string]string{\n\t\"active\": \"Active\",\n\t\"inactive\": \"Inactive\",\n}",
+			expectedTS: "{\n\t\"active\": \"Active\",\n\t\"inactive\": \"Inactive\",\n}",
+			goType:     "map[string]string",
+			tsType:     `Readonly<Record<"active" | "inactive", string>>`,
+		},
+		{
+			// 生成時に別のパッケージの enum を参照する名前が決まった場合は enum の型と定数を使う
+			name:       "enum keys in another package",
+			def:        types.Definition{Type: types.DefinitionTypeMap, KeyType: "UserStatus", ValueType: types.DefinitionTypeString, KeyEnum: status, KeyEnumRef: "status.UserStatus", Pos: types.Position{File: "labels.json"}, Value: map[string]any{"active": "Active", "inactive": "Inactive"}},
+			expectedGo: "map[status.UserStatus]string{\n\tstatus.UserStatusActive: \"Active\",\n\tstatus.UserStatusInactive: \"Inactive\",\n}",
+			expectedTS: "{\n\t\"active\": \"Active\",\n\t\"inactive\": \"Inactive\",\n}",
+			goType:     "map[status.UserStatus]string",
+			tsType:     `Readonly<Record<"active" | "inactive", string>>`,
+		},
+		{
+			name:       "empty",
+			def:        types.Definition{Type: types.DefinitionTypeMap, KeyType: types.DefinitionTypeString, ValueType: "string[]", Value: map[string]any{}},
+			expectedGo: "map[string][]string{}",
+			expectedTS: "{}",
+			goType:     "map[string][]string",
+			tsType:     "Readonly<Record<string, readonly string[]>>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := formatConstValue(tt.def); result != tt.expectedGo {
+				t.Errorf("formatConstValue() = %q, expected %q", result, tt.expectedGo)
+			}
+			if result := formatTSConstValue(tt.def); result != tt.expectedTS {
+				t.Errorf("formatTSConstValue() = %q, expected %q", result, tt.expectedTS)
+			}
+			if result := goType(tt.def); result != tt.goType {
+				t.Errorf("goType() = %q, expected %q", result, tt.goType)
+			}
+			if result := tsType(tt.def); result != tt.tsType {
+				t.Errorf("tsType() = %q, expected %q", result, tt.tsType)
+			}
+		})
+	}
+}
+
+func TestTSType(t *testing.T) {
+	tests := []struct {
+		def      types.Definition
+		expected string
+	}{
+		{types.Definition{Type: types.DefinitionTypeInt}, "number"},
+		{types.Definition{Type: types.DefinitionTypeInt64, TSMode: types.ModeBigInt}, "bigint"},
+		{types.Definition{Type: types.DefinitionTypeFloat, TSMode: types.ModeString}, "string"},
+		{types.Definition{Type: types.DefinitionTypeDate}, "Date"},
+		{types.Definition{Type: types.DefinitionTypeDate, TSMode: types.ModeString}, "string"},
+		{types.Definition{Type: "int[]", TSMode: types.ModeBigInt}, "bigint[]"},
+		{types.Definition{Type: "float[]", TSMode: types.ModeString}, "number[]"},
+		{types.Definition{Type: "date[]", TSMode: types.ModeString}, "string[]"},
+		{types.Definition{Type: types.DefinitionTypeObject, Name: "Policy"}, "PolicyType"},
+	}
+
+	for _, tt := range tests {
+		if result := tsType(tt.def); result != tt.expected {
+			t.Errorf("tsType(%s, %s) = %q, expected %q", tt.def.Type, tt.def.TSMode, result, tt.expected)
+		}
+	}
+}
+
+func TestDocComments(t *testing.T) {
+	tests := []struct {
+		name       string
+		doc        string
+		fallback   string
+		expectedGo string
+		expectedTS string
+	}{
+		{"No comment", "", "", "", ""},
+		{"Fallback only", "", "Status enum values", "// Status enum values", "// Status enum values"},
+		{"Single line", "リトライ回数の上限", "Status enum values", "// リトライ回数の上限", "/** リトライ回数の上限 */"},
+		{"Multiple lines", "上限\n\n詳細", "", "// 上限\n//\n// 詳細", "/**\n * 上限\n *\n * 詳細\n */"},
+		{"Comment terminator", "a */ b", "", "// a */ b", `/** a *\/ b */`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			def := types.Definition{Doc: tt.doc}
+			if result := goDoc(def, tt.fallback); result != tt.expectedGo {
+				t.Errorf("goDoc() = %q, expected %q", result, tt.expectedGo)
+			}
+			if result := tsDoc(def, tt.fallback); result != tt.expectedTS {
+				t.Errorf("tsDoc() = %q, expected %q", result, tt.expectedTS)
+			}
+		})
+	}
+}
+
+func TestDocCommentsWithDescription(t *testing.T) {
+	tests := []struct {
+		name       string
+		def        types.Definition
+		fallback   string
+		expectedGo string
+		expectedTS string
+	}{
+		{
+			name:       "Description wins over comment",
+			def:        types.Definition{Description: "説明", Doc: "コメント"},
+			expectedGo: "// 説明",
+			expectedTS: "/** 説明 */",
+		},
+		{
+			name:       "Deprecated with message",
+			def:        types.Definition{Description: "上限", Deprecated: types.Deprecation{Deprecated: true, Message: "Use MaxAttempts instead."}},
+			expectedGo: "// 上限\n//\n// Deprecated: Use MaxAttempts instead.",
+			expectedTS: "/**\n * 上限\n * @deprecated Use MaxAttempts instead.\n */",
+		},
+		{
+			name:       "Deprecated without description",
+			def:        types.Definition{Deprecated: types.Deprecation{Deprecated: true}},
+			expectedGo: "// Deprecated: This definition is deprecated.",
+			expectedTS: "/** @deprecated */",
+		},
+		{
+			name:       "Deprecated with fallback",
+			def:        types.Definition{Deprecated: types.Deprecation{Deprecated: true}},
+			fallback:   "Status enum values",
+			expectedGo: "// Status enum values\n//\n// Deprecated: This definition is deprecated.",
+			expectedTS: "/**\n * Status enum values\n * @deprecated\n */",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := goDoc(tt.def, tt.fallback); result != tt.expectedGo {
+				t.Errorf("goDoc() = %q, expected %q", result, tt.expectedGo)
+			}
+			if result := tsDoc(tt.def, tt.fallback); result != tt.expectedTS {
+				t.Errorf("tsDoc() = %q, expected %q", result, tt.expectedTS)
+			}
+		})
+	}
+}
+
+func TestValueDocComments(t *testing.T) {
+	def := types.Definition{
+		Type:              types.DefinitionTypeEnum,
+		Values:            types.StringEnumValues("active", "inactive"),
+		ValueDescriptions: map[string]string{"active": "ログイン可能\n（既定）"},
+	}
+	if result := goValueDoc(def, def.Values[0]); result != "// ログイン可能\n\t// （既定）" {
+		t.Errorf("goValueDoc() = %q", result)
+	}
+	if result := tsValueDoc(def, def.Values[0]); result != "/**\n\t * ログイン可能\n\t * （既定）\n\t */" {
+		t.Errorf("tsValueDoc() = %q", result)
+	}
+	if goValueDoc(def, def.Values[1]) != "" || tsValueDoc(def, def.Values[1]) != "" {
+		t.Error("Expected no comment for value without description")
+	}
+}
@@ -1,10 +1,15 @@
 package template
 
 import (
+	"encoding/json"
 	"fmt"
+	"math/big"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/nantokaworks/konst/internal/types"
+	"github.com/nantokaworks/konst/internal/utils"
 )
 
 // ============================================================================
@@ -17,8 +22,11 @@ func formatTS(value any) string {
 	switch v := value.(type) {
 	case string:
 		return formatTSString(v)
-	case float64:
+	case json.Number:
 		return formatTSNumber(v)
+	case float64:
+		n, _ := utils.AsNumber(v)
+		return formatTSNumber(n)
 	case bool:
 		return formatTSBool(v)
 	case []interface{}:
@@ -36,12 +44,23 @@ func formatTSString(v string) string {
 	return fmt.Sprintf("%q", v)
 }
 
-// formatTSNumber は数値をフォーマットします
-func formatTSNumber(v float64) string {
-	if v == float64(int(v)) {
-		return fmt.Sprintf("%d", int(v))
+// formatTSNumber は数値を JSON に書かれた表記のままフォーマットします
+func formatTSNumber(v json.Number) string {
+	return v.String()
+}
+
+// formatTSNumberWithMode は tsMode に応じて数値をフォーマットします
+// bigint の場合は整数値のみ BigInt リテラル（123n）にし、string の場合は文字列にします
+func formatTSNumberWithMode(v json.Number, mode types.TSMode) string {
+	switch mode {
+	case types.ModeBigInt:
+		if literal, ok := utils.IntegerLiteral(v); ok {
+			return literal + "n"
+		}
+	case types.ModeString:
+		return fmt.Sprintf("%q", v.String())
 	}
-	return fmt.Sprintf("%f", v)
+	return formatTSNumber(v)
 }
 
 // formatTSBool はbool値をフォーマットします
@@ -65,7 +84,7 @@ func formatTSArray(v []any) string {
 	// 型の統一性をチェック
 	allNumbers, allStrings, allBools := true, true, true
 	for _, elem := range v {
-		if _, ok := elem.(float64); !ok {
+		if _, ok := utils.AsNumber(elem); !ok {
 			allNumbers = false
 		}
 		if _, ok := elem.(string); !ok {
@@ -96,12 +115,8 @@ func formatTSArray(v []any) string {
 func formatTSNumberArray(v []any) string {
 	var elems []string
 	for _, elem := range v {
-		num := elem.(float64)
-		if num == float64(int(num)) {
-			elems = append(elems, fmt.Sprintf("%d", int(num)))
-		} else {
-			elems = append(elems, fmt.Sprintf("%f", num))
-		}
+		num, _ := utils.AsNumber(elem)
+		elems = append(elems, formatTSNumber(num))
 	}
 	return "[" + strings.Join(elems, ", ") + "]"
 }
@@ -145,12 +160,17 @@ func formatTSConstValue(content any) string {
 	if !ok {
 		return formatTS(content)
 	}
+	// 参照を式のまま出力する場合
+	if def.Code != "" {
+		return def.Code
+	}
 
 	switch def.Type {
 	case types.DefinitionTypeInt, types.DefinitionTypeInt32, types.DefinitionTypeInt64,
+		types.DefinitionTypeUint, types.DefinitionTypeUint32, types.DefinitionTypeUint64,
 		types.DefinitionTypeFloat, types.DefinitionTypeFloat32, types.DefinitionTypeFloat64:
-		if num, ok := def.Value.(float64); ok {
-			return formatTSNumber(num)
+		if num, ok := utils.AsNumber(def.Value); ok {
+			return formatTSNumberWithMode(num, def.TSMode)
 		}
 		return formatTS(def.Value)
 	case types.DefinitionTypeString:
@@ -167,6 +187,14 @@ func formatTSConstValue(content any) string {
 		return formatTSDate(def)
 	case types.DefinitionTypeTimestamp:
 		return formatTSTimestamp(def)
+	case types.DefinitionTypeDuration:
+		return formatTSDuration(def)
+	case types.DefinitionTypeObject:
+		return formatTSObject(def, 0)
+	case types.DefinitionTypeTable:
+		return formatTSTable(def)
+	case types.DefinitionTypeMap:
+		return formatTSMap(def)
 	default:
 		// 配列型の場合
 		if strings.Contains(string(def.Type), "[]") {
@@ -213,7 +241,42 @@ func formatTSTimestamp(def types.Definition) string {
 	return formatTS(def.Value)
 }
 
-// formatTSDefinitionArray は配列型の値をTypeScript用にフォーマットします
+// formatTSDuration は期間型の値を tsMode に応じてミリ秒（デフォルト）または秒の数値としてフォーマットします
+func formatTSDuration(def types.Definition) string {
+	s, ok := def.Value.(string)
+	if !ok {
+		return formatTS(def.Value)
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return formatTS(def.Value)
+	}
+	unit := tsDurationNanos(def)
+	// 割り切れない場合も誤差なく10進表記にする（1500us → 1.5）
+	r := new(big.Rat).SetFrac64(int64(d), int64(unit))
+	if r.IsInt() {
+		return r.Num().String()
+	}
+	return strings.TrimRight(r.FloatString(9), "0")
+}
+
+// tsDurationUnit は期間型の値を TypeScript で出力する単位の名前を返します
+func tsDurationUnit(def types.Definition) string {
+	if def.TSMode == types.ModeSeconds {
+		return "seconds"
+	}
+	return "milliseconds"
+}
+
+// tsDurationNanos は期間型の値を TypeScript で出力する単位の長さを返します
+func tsDurationNanos(def types.Definition) time.Duration {
+	if def.TSMode == types.ModeSeconds {
+		return time.Second
+	}
+	return time.Millisecond
+}
+
+// formatTSDefinitionArray は配列型の値をTypeScript用にフォーマットします。整数型の要素は tsMode に応じてフォーマットします
 func formatTSDefinitionArray(def types.Definition) string {
 	arrayValue, ok := def.Value.([]any)
 	if !ok {
@@ -233,10 +296,250 @@ func formatTSDefinitionArray(def types.Definition) string {
 				GoMode: def.GoMode,
 			}
 			elements = append(elements, formatTSDate(tempDef))
+		case "duration":
+			elements = append(elements, formatTSDuration(types.Definition{Type: types.DefinitionTypeDuration, Value: elem, TSMode: def.TSMode}))
 		default:
+			if num, ok := utils.AsNumber(elem); ok && utils.IsIntegerType(types.DefinitionType(baseType)) {
+				elements = append(elements, formatTSNumberWithMode(num, def.TSMode))
+				continue
+			}
 			elements = append(elements, formatTS(elem))
 		}
 	}
 
 	return "[" + strings.Join(elements, ", ") + "]"
 }
+
+// formatTSObject は object 型の値をオブジェクトリテラルとしてフォーマットします。
+// 入れ子の object は depth を1つ深くしたインデントで出力します。
+func formatTSObject(def types.Definition, depth int) string {
+	obj, _ := def.Value.(map[string]any)
+	indent := strings.Repeat("\t", depth+1)
+
+	var b strings.Builder
+	b.WriteString("{\n")
+	for _, f := range def.Fields {
+		fieldDef := f.Definition(def, utils.ToPascalCase(f.Name), obj[f.Name])
+		value := formatTSConstValue(fieldDef)
+		if f.Type == types.DefinitionTypeObject {
+			value = formatTSObject(fieldDef, depth+1)
+		}
+		b.WriteString(indent + f.Name + ": " + value + ",\n")
+	}
+	b.WriteString(strings.Repeat("\t", depth) + "}")
+	return b.String()
+}
+
+// formatTSTable は table 型の行をオブジェクトの配列リテラルとしてフォーマットします
+func formatTSTable(def types.Definition) string {
+	if len(def.Rows) == 0 {
+		return "[]"
+	}
+	var b strings.Builder
+	b.WriteString("[\n")
+	for i := range def.Rows {
+		b.WriteString("\t" + formatTSObject(def.RowDefinition(i), 1) + ",\n")
+	}
+	b.WriteString("]")
+	return b.String()
+}
+
+// formatTSMap は map 型の値をキーの順に並べたオブジェクトリテラルとしてフォーマットします
+func formatTSMap(def types.Definition) string {
+	entries := mapEntries(def)
+	if len(entries) == 0 {
+		return "{}"
+	}
+	var b strings.Builder
+	b.WriteString("{\n")
+	for _, e := range entries {
+		b.WriteString("\t" + tsMapKey(def, e) + ": " + formatTSConstValue(def.ValueDefinition(e.Value)) + ",\n")
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// tsMapKey は map のキーの TypeScript のプロパティ名を返します。同じファイルの enum のキーは計算プロパティで enum を参照します。
+// 計算プロパティはモジュールの読み込み時に評価されるため、定義名の順で map より後に出力される enum は値のリテラルで出力します。
+func tsMapKey(def types.Definition, e mapEntry) string {
+	switch {
+	case e.Member != nil && isLocalKeyEnum(def) && string(def.KeyType) < def.Name:
+		return "[" + string(def.KeyType) + "." + toTitle(e.Member.Name) + "]"
+	case e.Member != nil:
+		return enumMemberLiteral(*e.Member)
+	case utils.IsIntegerType(def.KeyType):
+		return integerKeyLiteral(e.Key)
+	default:
+		return strconv.Quote(e.Key)
+	}
+}
+
+// tsMapKeyType は map のキーの TypeScript の型を返します。
+// 別のファイルの enum のキーは enum の値のリテラル型の union にします。
+func tsMapKeyType(def types.Definition) string {
+	switch {
+	case isLocalKeyEnum(def):
+		return string(def.KeyType) + "Type"
+	case def.KeyEnum != nil:
+		literals := make([]string, 0, len(def.KeyEnum.Values))
+		for _, member := range def.KeyEnum.Values {
+			literals = append(literals, enumMemberLiteral(member))
+		}
+		return strings.Join(literals, " | ")
+	case utils.IsIntegerType(def.KeyType):
+		return "number"
+	default:
+		return "string"
+	}
+}
+
+// tsObjectTypes は object 型の定義からフィールドを readonly にしたインターフェースの宣言を作ります。
+// 入れ子の object には「親の型名 + フィールド名」のインターフェースを宣言し、親の後に並べます。
+func tsObjectTypes(def types.Definition) string {
+	return strings.Join(tsInterfaces(tsType(def), "the type of "+def.Name, def.Name, def), "\n\n")
+}
+
+// tsTableTypes は table 型の定義から行のインターフェース（定義名の型）の宣言を作ります
+func tsTableTypes(def types.Definition) string {
+	row := types.Definition{Name: def.Name, Fields: def.Columns}
+	return strings.Join(tsInterfaces(def.Name, "a row of "+def.Name+"Rows", def.Name, row), "\n\n")
+}
+
+// tsInterfaces は def の Fields から型名 typeName のインターフェースと、入れ子の object のインターフェースの宣言を作ります。
+// about は型のコメントに使う説明、path は入れ子の型のコメントに使うフィールドの位置です。
+func tsInterfaces(typeName, about, path string, def types.Definition) []string {
+	var b strings.Builder
+	var nested []types.Definition
+	var paths []string
+	fmt.Fprintf(&b, "// %s is %s\n", typeName, about)
+	b.WriteString("export interface " + typeName + " {\n")
+	for _, f := range def.Fields {
+		fieldDef := f.Definition(def, utils.ToPascalCase(f.Name), nil)
+		if f.Description != "" {
+			b.WriteString("\t" + jsDoc(strings.Split(f.Description, "\n"), "\n\t") + "\n")
+		}
+		fieldType := tsType(fieldDef)
+		if strings.HasSuffix(fieldType, "[]") {
+			// 配列の要素も変更できないようにする
+			fieldType = "readonly " + fieldType
+		}
+		fmt.Fprintf(&b, "\treadonly %s: %s;\n", f.Name, fieldType)
+		if f.Type == types.DefinitionTypeObject {
+			nested = append(nested, fieldDef)
+			paths = append(paths, path+"."+f.Name)
+		}
+	}
+	b.WriteString("}")
+
+	decls := []string{b.String()}
+	for i, n := range nested {
+		decls = append(decls, tsInterfaces(tsType(n), "the type of "+paths[i], paths[i], n)...)
+	}
+	return decls
+}
+
+// tsType は定義の値の TypeScript の型名を返します。formatTSConstValue が出力するリテラルの型に合わせます。
+func tsType(def types.Definition) string {
+	switch def.Type {
+	case types.DefinitionTypeInt, types.DefinitionTypeInt32, types.DefinitionTypeInt64,
+		types.DefinitionTypeUint, types.DefinitionTypeUint32, types.DefinitionTypeUint64:
+		switch def.TSMode {
+		case types.ModeBigInt:
+			return "bigint"
+		case types.ModeString:
+			return "string"
+		}
+		return "number"
+	case types.DefinitionTypeFloat, types.DefinitionTypeFloat32, types.DefinitionTypeFloat64:
+		if def.TSMode == types.ModeString {
+			return "string"
+		}
+		return "number"
+	case types.DefinitionTypeString:
+		return "string"
+	case types.DefinitionTypeBool:
+		return "boolean"
+	case types.DefinitionTypeTimestamp, types.DefinitionTypeDuration:
+		return "number"
+	case types.DefinitionTypeDate:
+		switch def.TSMode {
+		case types.ModeString:
+			return "string"
+		case types.ModeBigInt:
+			return "bigint"
+		}
+		return "Date"
+	case types.DefinitionTypeObject:
+		return def.Name + "Type"
+	case types.DefinitionTypeMap:
+		valueType := tsType(def.ValueDefinition(nil))
+		if strings.HasSuffix(valueType, "[]") {
+			// object のフィールドと同じく、配列の値も変更できないようにする
+			valueType = "readonly " + valueType
+		}
+		return "Readonly<Record<" + tsMapKeyType(def) + ", " + valueType + ">>"
+	}
+	if strings.HasSuffix(string(def.Type), "[]") {
+		elem := arrayElementDefinition(def)
+		if elem.Type != types.DefinitionTypeDate && !utils.IsIntegerType(elem.Type) {
+			// 日付・整数以外の配列の要素は tsMode によらずそのまま出力される
+			elem.TSMode = ""
+		}
+		return tsType(elem) + "[]"
+	}
+	return "unknown"
+}
+
+// ============================================================================
+// ドキュメントコメント
+// ============================================================================
+
+// tsDoc は定義の説明を JSDoc（/** */ 形式）にします。非推奨の定義には @deprecated タグを付けます。
+// 説明がなく非推奨でもない場合は fallback を // 形式のコメントとして使い、どちらも空なら空文字列を返します。
+func tsDoc(def types.Definition, fallback string) string {
+	text := docText(def)
+	if text == "" && !def.Deprecated.Deprecated {
+		if fallback == "" {
+			return ""
+		}
+		return "// " + fallback
+	}
+	if text == "" {
+		text = fallback
+	}
+	var lines []string
+	if text != "" {
+		lines = strings.Split(text, "\n")
+	}
+	if def.Deprecated.Deprecated {
+		lines = append(lines, strings.TrimSpace("@deprecated "+def.Deprecated.Message))
+	}
+	return jsDoc(lines, "\n")
+}
+
+// tsValueDoc は enum 値の説明をオブジェクトのプロパティに付ける JSDoc にします。説明がなければ空文字列を返します
+func tsValueDoc(def types.Definition, value types.EnumValue) string {
+	desc := enumValueDescription(def, value)
+	if desc == "" {
+		return ""
+	}
+	return jsDoc(strings.Split(desc, "\n"), "\n\t")
+}
+
+// jsDoc は行を JSDoc コメントにします。1行の場合は /** ... */ の1行にまとめます
+func jsDoc(lines []string, newline string) string {
+	for i, line := range lines {
+		// コメント本文の "*/" で JSDoc が閉じないようにする
+		lines[i] = strings.ReplaceAll(line, "*/", "*\\/")
+	}
+	if len(lines) == 1 {
+		return "/** " + lines[0] + " */"
+	}
+	var b strings.Builder
+	b.WriteString("/**")
+	for _, line := range lines {
+		b.WriteString(newline + strings.TrimRight(" * "+line, " "))
+	}
+	b.WriteString(newline + " */")
+	return b.String()
+}
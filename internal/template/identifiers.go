@@ -0,0 +1,81 @@
+package template
+
+import (
+	"github.com/nantokaworks/konst/internal/types"
+	"github.com/nantokaworks/konst/internal/utils"
+)
+
+// Identifiers は内蔵テンプレートが定義 name から生成するトップレベルの識別子（Go はパッケージ、TypeScript はモジュールの識別子）を返します。
+// 定義名のほか、定義名から作る型名（XType、XRows）や関数名（IsValidX、BuildX）、Go の enum の定数名（X<値>）を含みます。
+func Identifiers(name string, def types.Definition, ts bool) []string {
+	def.Name = name
+	ids := []string{name}
+	switch def.Type {
+	case types.DefinitionTypeTemplate:
+		if ts {
+			return append(ids, name+"Template", "build"+name)
+		}
+		return append(ids, name+"Template", "Build"+name)
+	case types.DefinitionTypeEnum:
+		return append(ids, enumIdentifiers(name, def, ts)...)
+	case types.DefinitionTypeObject:
+		return append(append(ids, name+"Type"), nestedTypeNames(def)...)
+	case types.DefinitionTypeTable:
+		ids = append(ids, name+"Rows")
+		ids = append(ids, nestedTypeNames(types.Definition{Name: name, Fields: def.Columns})...)
+		for _, col := range def.Columns {
+			if !col.Key {
+				continue
+			}
+			key := utils.ToPascalCase(col.Name)
+			if ts {
+				ids = append(ids, tableIndexName(name, key), "get"+name+"By"+key)
+			} else {
+				ids = append(ids, tableIndexName(name, key), name+"By"+key)
+			}
+		}
+	}
+	return ids
+}
+
+// enumIdentifiers は enum 型の定義から生成する、定義名以外の識別子を返します
+func enumIdentifiers(name string, def types.Definition, ts bool) []string {
+	var ids []string
+	if ts {
+		ids = append(ids, name+"Type", "isValid"+name, "parse"+name, "parse"+name+"Safe", "getAll"+name+"Values")
+		if def.IsIntEnum() {
+			ids = append(ids, name+"Names", "get"+name+"Name", "parse"+name+"Number")
+		}
+		if def.Default != "" {
+			ids = append(ids, "getDefault"+name)
+		}
+		return ids
+	}
+
+	// Go の enum の値はパッケージの定数になる
+	for _, v := range def.Values {
+		ids = append(ids, name+toTitle(v.Name))
+	}
+	ids = append(ids, "IsValid"+name, "Parse"+name, "GetAll"+name+"Values")
+	if def.IsIntEnum() {
+		ids = append(ids, "Parse"+name+"Number")
+	}
+	if def.Default != "" {
+		ids = append(ids, "GetDefault"+name)
+	}
+	return ids
+}
+
+// nestedTypeNames は def の Fields のうち入れ子の object の型名（Go の構造体、TypeScript のインターフェース）を返します
+func nestedTypeNames(def types.Definition) []string {
+	var names []string
+	for _, f := range def.Fields {
+		if f.Type != types.DefinitionTypeObject {
+			continue
+		}
+		fieldDef := f.Definition(def, utils.ToPascalCase(f.Name), nil)
+		names = append(names, fieldDef.Name+"Type")
+		names = append(names, nestedTypeNames(fieldDef)...)
+	}
+	return names
+}
@@ -0,0 +1,42 @@
+package template
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nantokaworks/konst/internal/types"
+)
+
+func TestIdentifiers(t *testing.T) {
+	enum := types.Definition{Type: types.DefinitionTypeEnum, Values: []types.EnumValue{{Name: "active"}, {Name: "in-review"}}, Default: "active"}
+	table := types.Definition{
+		Type: types.DefinitionTypeTable,
+		Columns: []types.Field{
+			{Name: "code", Type: types.DefinitionTypeString, Key: true},
+			{Name: "limits", Type: types.DefinitionTypeObject, Fields: []types.Field{{Name: "max", Type: types.DefinitionTypeInt}}},
+		},
+	}
+	tests := []struct {
+		name     string
+		def      types.Definition
+		ts       bool
+		expected []string
+	}{
+		{"Constant", types.Definition{Type: types.DefinitionTypeInt}, false, []string{"X"}},
+		{"Go template", types.Definition{Type: types.DefinitionTypeTemplate}, false, []string{"X", "XTemplate", "BuildX"}},
+		{"TypeScript template", types.Definition{Type: types.DefinitionTypeTemplate}, true, []string{"X", "XTemplate", "buildX"}},
+		{"Go enum", enum, false, []string{"X", "XActive", "XInReview", "IsValidX", "ParseX", "GetAllXValues", "GetDefaultX"}},
+		{"TypeScript enum", enum, true, []string{"X", "XType", "isValidX", "parseX", "parseXSafe", "getAllXValues", "getDefaultX"}},
+		{"Object", types.Definition{Type: types.DefinitionTypeObject, Fields: []types.Field{{Name: "db", Type: types.DefinitionTypeObject}}}, false, []string{"X", "XType", "XDBType"}},
+		{"Go table", table, false, []string{"X", "XRows", "XLimitsType", "xByCodeIndex", "XByCode"}},
+		{"TypeScript table", table, true, []string{"X", "XRows", "XLimitsType", "xByCodeIndex", "getXByCode"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := Identifiers("X", tt.def, tt.ts); !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Identifiers() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
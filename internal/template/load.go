@@ -8,22 +8,15 @@ import (
 	"text/template"
 )
 
-func Load(outputFile *string, templateDir *string, spaces *int) (*template.Template, error) {
-	// テンプレート関数を定義
-	funcMap := createMap(spaces)
-
-	ext := filepath.Ext(*outputFile)
-	tmplText, err := loadTemplate(ext, *templateDir)
-	if err != nil {
-		return nil, err
-	}
-
-	tmpl, err := template.New("output").Funcs(funcMap).Parse(tmplText)
-	if err != nil {
-		return nil, err
-	}
+// Parse は Source で読み込んだテンプレートの内容 tmplText をテンプレート関数と一緒に解析します。
+// 解析したテンプレートは同じ出力モードの全ての定義ファイルの生成に使えます。
+func Parse(tmplText string, spaces *int) (*template.Template, error) {
+	return template.New("output").Funcs(createMap(spaces)).Parse(tmplText)
+}
 
-	return tmpl.New("output").Funcs(funcMap).Parse(tmplText)
+// Source は拡張子 ext（".go" または ".ts"）のファイルを生成するテンプレートの内容を返します
+func Source(ext string, templateDir *string) (string, error) {
+	return loadTemplate(ext, *templateDir)
 }
 
 // LoadTemplate は、指定されたテンプレートディレクトリから、
@@ -19,17 +19,31 @@ func createMap(spaces *int) map[string]interface{} {
 		"formatTS":         formatTS,
 		"formatTSConstValue": formatTSConstValue,
 		"formatConstValue": formatConstValue,
+		"goType":           goType,
+		"tsType":           tsType,
+		"goObjectTypes":    goObjectTypes,
+		"tsObjectTypes":    tsObjectTypes,
+		"goTableTypes":     goTableTypes,
+		"tsTableTypes":     tsTableTypes,
+		"tableKeys":        tableKeys,
+		"goDoc":            goDoc,
+		"tsDoc":            tsDoc,
+		"tsDurationUnit":   tsDurationUnit,
+		"goValueDoc":       goValueDoc,
+		"tsValueDoc":       tsValueDoc,
 		"convertTSType":    utils.ConvertTSType,
 		"indent":           indentLevel,
 		"sortedKeys":       sortedKeys,
-		"title":            toTitle, // strings.Title から toTitle 関数へ
-		"toTitle":          toTitle, // 追加: toTitle関数
+		"title":            toTitleValue, // strings.Title から toTitle 関数へ
+		"toTitle":          toTitleValue, // 追加: toTitle関数
 		"toCamel":          toCamel, // 追加: toCamel関数
 		"asString":         utils.AsString,
 		"hasDate":          utils.HasDate,
 		"hasEnum":          hasEnum,          // 追加: hasEnum関数
 		"hasTemplate":      hasTemplate,      // 追加: hasTemplate関数
+		"hasIntEnum":       hasIntEnum,
 		"contains":         strings.Contains, // 追加: contains関数
+		"join":             strings.Join,
 		"printf":           fmt.Sprintf,      // 追加: printf関数
 	}
 }
@@ -0,0 +1,74 @@
+package template
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+
+	"github.com/nantokaworks/konst/internal/types"
+	"github.com/nantokaworks/konst/internal/utils"
+)
+
+// mapEntry は map 型の1エントリーです
+type mapEntry struct {
+	Key    string           // 定義ファイルに書かれたキー
+	Value  any              // 値
+	Member *types.EnumValue // キーが enum の場合の対応するメンバー
+}
+
+// mapEntries は map 型のエントリーを出力順に返します。
+// キーが enum の場合は enum の定義順、整数型の場合は数値順、それ以外は文字列順に並べます。
+func mapEntries(def types.Definition) []mapEntry {
+	values, _ := def.Value.(map[string]any)
+	var entries []mapEntry
+
+	if def.KeyEnum != nil {
+		for i, member := range def.KeyEnum.Values {
+			if value, ok := values[member.Name]; ok {
+				entries = append(entries, mapEntry{Key: member.Name, Value: value, Member: &def.KeyEnum.Values[i]})
+			}
+		}
+		return entries
+	}
+
+	for key, value := range values {
+		entries = append(entries, mapEntry{Key: key, Value: value})
+	}
+	if utils.IsIntegerType(def.KeyType) {
+		sort.Slice(entries, func(i, j int) bool {
+			a, _ := utils.NumberRat(json.Number(entries[i].Key))
+			b, _ := utils.NumberRat(json.Number(entries[j].Key))
+			if a == nil || b == nil {
+				return entries[i].Key < entries[j].Key
+			}
+			return a.Cmp(b) < 0
+		})
+	} else {
+		sort.Slice(entries, func(i, j int) bool {
+			return entries[i].Key < entries[j].Key
+		})
+	}
+	return entries
+}
+
+// isLocalKeyEnum は map のキーの enum が同じ定義ファイルにあり、生成コードで enum の型と定数を参照できるかどうかを返します。
+// 別のファイルの enum は参照できないため、enum の値のリテラルで出力します。
+func isLocalKeyEnum(def types.Definition) bool {
+	return def.KeyEnum != nil && def.KeyEnum.Pos.File == def.Pos.File
+}
+
+// integerKeyLiteral は整数型のキーを10進の整数リテラルにします
+func integerKeyLiteral(key string) string {
+	if literal, ok := utils.IntegerLiteral(json.Number(key)); ok {
+		return literal
+	}
+	return key
+}
+
+// enumMemberLiteral は enum のメンバーの値のリテラル（文字列の enum では名前の文字列、整数の enum では番号）を返します
+func enumMemberLiteral(member types.EnumValue) string {
+	if member.IsInt() {
+		return integerKeyLiteral(member.Value.String())
+	}
+	return strconv.Quote(member.Name)
+}
@@ -0,0 +1,64 @@
+package template
+
+import (
+	"errors"
+
+	"github.com/nantokaworks/konst/internal/expr"
+	"github.com/nantokaworks/konst/internal/types"
+	"github.com/nantokaworks/konst/internal/utils"
+)
+
+// ============================================================================
+// 参照を式のまま出力する（--symbolic）
+// ============================================================================
+
+// SymbolRef は式から参照する定義です
+type SymbolRef struct {
+	Def  types.Definition // 解決済みの定義
+	Code string           // 生成コードで定義を参照する書き方（BaseRetries、network.BaseRetries など）
+}
+
+// SymbolResolver は定義名を生成コードから参照できる定義にします。
+// 参照できない場合（import すると循環するなど）は ok が false になります。
+type SymbolResolver func(name string) (SymbolRef, bool)
+
+// errUnresolvable は参照できない定義を式の評価で使ったことを示します
+var errUnresolvable = errors.New("reference cannot be resolved")
+
+// symbolic は式を生成コードの式に変換する処理の共通部分です
+type symbolic struct {
+	def     types.Definition
+	resolve SymbolResolver
+}
+
+// eval は式のノードの値を計算します。
+// 各ノードの値から、生成コードの式が計算した値と同じ値になるかを確認します。
+func (s *symbolic) eval(n expr.Node) (expr.Value, bool) {
+	v, err := expr.Eval(n, func(ident *expr.Ident) (expr.Value, error) {
+		ref, ok := s.resolve(ident.Name)
+		if !ok {
+			return expr.Value{}, errUnresolvable
+		}
+		return utils.DefinitionValue(ident.Name, ref.Def)
+	})
+	return v, err == nil
+}
+
+// isDateString は日付として出力される文字列（Go の time.Date(...)、TypeScript の new Date(...)）かどうかを返します
+func isDateString(def types.Definition) bool {
+	s, ok := def.Value.(string)
+	if !ok {
+		return false
+	}
+	_, ok = tryParseDate(s)
+	return ok
+}
+
+// operand は二項演算子の優先順位 prec の位置に置く式に、必要なら括弧を付けます。
+// 右辺では同じ優先順位の式も括弧で囲みます（左結合のため）。
+func operand(code string, codePrec, prec int, right bool) string {
+	if codePrec < prec || (right && codePrec == prec) {
+		return "(" + code + ")"
+	}
+	return code
+}
@@ -0,0 +1,260 @@
+package template
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/nantokaworks/konst/internal/expr"
+	"github.com/nantokaworks/konst/internal/types"
+	"github.com/nantokaworks/konst/internal/utils"
+)
+
+// goBinaryPrec は Go の二項演算子の優先順位です（式の構文の優先順位と同じ）
+var goBinaryPrec = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"==": 3, "!=": 3, "<": 3, "<=": 3, ">": 3, ">=": 3,
+	"+": 4, "-": 4,
+	"*": 5, "/": 5, "%": 5, "<<": 5, ">>": 5,
+}
+
+const (
+	goPrecUnary  = 6 // 単項演算子の式
+	goPrecAtomic = 7 // 括弧が不要な式（リテラル・定義名・括弧や変換で囲んだ式）
+)
+
+// goExpr は Go の定数式です
+type goExpr struct {
+	code string
+	typ  string     // Go の型。型なし定数の場合は空文字列
+	val  expr.Value // 式の値
+	prec int        // 最も外側の演算子の優先順位
+}
+
+// goSymbolic は式を Go の定数式に変換します
+type goSymbolic struct {
+	symbolic
+}
+
+// SymbolicGo は定義 def の式（def.Expression）を、参照先の定数を使った Go の定数式にします。
+// Go の定数式で表せない場合や、計算した値と異なる値になる可能性がある場合は ok が false になります。
+func SymbolicGo(def types.Definition, resolve SymbolResolver) (string, bool) {
+	target := goConstType(def)
+	if def.Expression == "" || target == "" {
+		return "", false
+	}
+	parts, err := utils.ExpressionParts(def.Expression, def.Type)
+	if err != nil {
+		return "", false
+	}
+	g := &goSymbolic{symbolic{def: def, resolve: resolve}}
+	if def.Type == types.DefinitionTypeString {
+		return g.concat(parts)
+	}
+	e, ok := g.translate(parts[0].Node)
+	if !ok {
+		return "", false
+	}
+	return g.convert(e, target)
+}
+
+// goConstType は Go で const として出力され、式で使える定義の型を返します。対象外の定義は空文字列を返します
+func goConstType(def types.Definition) string {
+	switch {
+	case utils.IsIntegerType(def.Type), def.Type == types.DefinitionTypeFloat,
+		def.Type == types.DefinitionTypeFloat32, def.Type == types.DefinitionTypeFloat64,
+		def.Type == types.DefinitionTypeBool, def.Type == types.DefinitionTypeDuration:
+		return goType(def)
+	case def.Type == types.DefinitionTypeString && !isDateString(def):
+		return goType(def)
+	}
+	return ""
+}
+
+// concat は string の値の文字列の部分と {{式}} を + で連結します。{{式}} は文字列の式だけを使えます
+func (g *goSymbolic) concat(parts []utils.ExpressionPart) (string, bool) {
+	var terms []string
+	for _, part := range parts {
+		if part.Node == nil {
+			terms = append(terms, strconv.Quote(part.Text))
+			continue
+		}
+		e, ok := g.translate(part.Node)
+		if !ok || e.val.Kind != expr.KindString {
+			return "", false
+		}
+		terms = append(terms, operand(e.code, e.prec, goBinaryPrec["+"], len(terms) > 0))
+	}
+	return strings.Join(terms, " + "), true
+}
+
+// translate は式のノードを Go の定数式にします
+func (g *goSymbolic) translate(n expr.Node) (goExpr, bool) {
+	v, ok := g.eval(n)
+	if !ok || v.Kind == expr.KindFloat {
+		// 小数は Go の型付き定数の丸めで値が変わる可能性があるため対象外
+		return goExpr{}, false
+	}
+
+	switch n := n.(type) {
+	case *expr.NumberLit:
+		return goExpr{code: numberCode(n, v), val: v, prec: goPrecAtomic}, true
+	case *expr.DurationLit:
+		// time パッケージを import しているのは期間型の定義のファイルだけのため、期間型の定義の式でのみ使う
+		if g.def.Type != types.DefinitionTypeDuration {
+			return goExpr{}, false
+		}
+		code := utils.GoDuration(v.Duration)
+		prec := goPrecAtomic
+		if strings.Contains(code, " + ") {
+			prec = goBinaryPrec["+"]
+		} else if strings.Contains(code, "*") {
+			prec = goBinaryPrec["*"]
+		}
+		return goExpr{code: code, typ: "time.Duration", val: v, prec: prec}, true
+	case *expr.StringLit:
+		return goExpr{code: strconv.Quote(n.Value), val: v, prec: goPrecAtomic}, true
+	case *expr.BoolLit:
+		return goExpr{code: strconv.FormatBool(n.Value), val: v, prec: goPrecAtomic}, true
+	case *expr.Ident:
+		ref, ok := g.resolve(n.Name)
+		if !ok {
+			return goExpr{}, false
+		}
+		typ := goConstType(ref.Def)
+		if typ == "" {
+			return goExpr{}, false
+		}
+		return goExpr{code: ref.Code, typ: typ, val: v, prec: goPrecAtomic}, true
+	case *expr.Paren:
+		x, ok := g.translate(n.X)
+		if !ok {
+			return goExpr{}, false
+		}
+		if x.prec == goPrecAtomic {
+			// {{Name}} は括弧で囲んだ式として解析されるため、括弧が不要な式の括弧は出力しない
+			return x, true
+		}
+		return goExpr{code: "(" + x.code + ")", typ: x.typ, val: v, prec: goPrecAtomic}, true
+	case *expr.Unary:
+		x, ok := g.translate(n.X)
+		if !ok {
+			return goExpr{}, false
+		}
+		e := goExpr{code: n.Op + operand(x.code, x.prec, goPrecAtomic, false), typ: x.typ, val: v, prec: goPrecUnary}
+		return e, goFits(e)
+	case *expr.Binary:
+		return g.binary(n, v)
+	}
+	// 関数呼び出しは Go の定数式で表せない（min・max は Go 1.21 以降のみ）
+	return goExpr{}, false
+}
+
+// binary は二項演算子の式を Go の定数式にします。
+// Go の型付き定数は両辺の型が同じでなければならないため、型の異なる整数は変換して揃えます。
+func (g *goSymbolic) binary(n *expr.Binary, v expr.Value) (goExpr, bool) {
+	x, ok := g.translate(n.X)
+	if !ok {
+		return goExpr{}, false
+	}
+	y, ok := g.translate(n.Y)
+	if !ok {
+		return goExpr{}, false
+	}
+
+	var typ string
+	switch n.Op {
+	case "<<", ">>":
+		// シフトの結果は左辺の型になる
+		typ = x.typ
+	default:
+		if x, y, typ, ok = unifyGo(x, y); !ok {
+			return goExpr{}, false
+		}
+		if goBinaryPrec[n.Op] == goBinaryPrec["=="] {
+			typ = "" // 比較の結果は型なしの真偽値
+		}
+	}
+	prec := goBinaryPrec[n.Op]
+	e := goExpr{
+		code: operand(x.code, x.prec, prec, false) + " " + n.Op + " " + operand(y.code, y.prec, prec, true),
+		typ:  typ,
+		val:  v,
+		prec: prec,
+	}
+	return e, goFits(e)
+}
+
+// unifyGo は二項演算子の両辺の型を揃え、演算の型を返します。
+// 期間と整数の演算は整数を time.Duration に変換し、型の異なる整数はもう一方の型で表せる側を変換します。
+func unifyGo(x, y goExpr) (goExpr, goExpr, string, bool) {
+	switch {
+	case x.typ == y.typ:
+		return x, y, x.typ, true
+	case x.typ == "":
+		return x, y, y.typ, true
+	case y.typ == "":
+		return x, y, x.typ, true
+	case x.typ == "time.Duration" && isGoInteger(y.typ):
+		return x, convertGo(y, x.typ), x.typ, true
+	case isGoInteger(x.typ) && y.typ == "time.Duration":
+		return convertGo(x, y.typ), y, y.typ, true
+	case isGoInteger(x.typ) && isGoInteger(y.typ):
+		if converted := convertGo(y, x.typ); goFits(converted) {
+			return x, converted, x.typ, true
+		}
+		if converted := convertGo(x, y.typ); goFits(converted) {
+			return converted, y, y.typ, true
+		}
+	}
+	return x, y, "", false
+}
+
+// convert は式を宣言された型 target の定数の値にします。型付きの整数・期間の式は target に変換します
+func (g *goSymbolic) convert(e goExpr, target string) (string, bool) {
+	switch {
+	case e.typ == "" || e.typ == target:
+		return e.code, true
+	case isGoInteger(e.typ) || e.typ == "time.Duration":
+		if isGoInteger(target) || target == "float32" || target == "float64" {
+			return convertGo(e, target).code, true
+		}
+	}
+	return "", false
+}
+
+// convertGo は式を型 typ に変換します
+func convertGo(e goExpr, typ string) goExpr {
+	return goExpr{code: typ + "(" + e.code + ")", typ: typ, val: e.val, prec: goPrecAtomic}
+}
+
+// isGoInteger は Go の整数型かどうかを返します
+func isGoInteger(typ string) bool {
+	return utils.IsIntegerType(types.DefinitionType(typ))
+}
+
+// goFits は型付きの整数の式の値がその型で表せるかどうかを返します。
+// Go の型付き定数は途中の計算結果も型の範囲に収まる必要があります。
+func goFits(e goExpr) bool {
+	if e.val.Kind != expr.KindInt {
+		return true
+	}
+	typ := e.typ
+	if typ == "time.Duration" {
+		typ = string(types.DefinitionTypeInt64)
+	}
+	if !isGoInteger(typ) {
+		return true
+	}
+	return utils.CheckNumber(types.DefinitionType(typ), json.Number(e.val.Int.String())) == nil
+}
+
+// numberCode は数値リテラルを出力する表記を返します。
+// 16進数はそのまま、それ以外は10進数にします（先頭の 0 が Go・TypeScript で8進数として扱われないように）。
+func numberCode(n *expr.NumberLit, v expr.Value) string {
+	if strings.HasPrefix(n.Text, "0x") || strings.HasPrefix(n.Text, "0X") {
+		return n.Text
+	}
+	return v.Int.String()
+}
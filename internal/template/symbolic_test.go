@@ -0,0 +1,99 @@
+package template
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/nantokaworks/konst/internal/types"
+)
+
+// symbolDefs は式から参照する定義です。Far は別パッケージ・別ファイルの定義として参照します
+var symbolDefs = map[string]types.Definition{
+	"Retries": {Type: types.DefinitionTypeInt, Value: json.Number("3")},
+	"Small":   {Type: types.DefinitionTypeInt32, Value: json.Number("7")},
+	"Big":     {Type: types.DefinitionTypeInt64, Value: json.Number("9007199254740993")},
+	"Ratio":   {Type: types.DefinitionTypeFloat64, Value: json.Number("1.5")},
+	"Env":     {Type: types.DefinitionTypeString, Value: "prod"},
+	"Debug":   {Type: types.DefinitionTypeBool, Value: false},
+	"Timeout": {Type: types.DefinitionTypeDuration, Value: "30s"},
+	"Minutes": {Type: types.DefinitionTypeDuration, Value: "1m", TSMode: types.ModeSeconds},
+	"Ports":   {Type: "int[]", Value: []interface{}{json.Number("80")}},
+	"Far":     {Type: types.DefinitionTypeInt, Value: json.Number("5")},
+}
+
+func symbolResolver(name string) (SymbolRef, bool) {
+	def, ok := symbolDefs[name]
+	if !ok {
+		return SymbolRef{}, false
+	}
+	if name == "Far" {
+		return SymbolRef{Def: def, Code: "other.Far"}, true
+	}
+	return SymbolRef{Def: def, Code: name}, true
+}
+
+func TestSymbolicGo(t *testing.T) {
+	tests := []struct {
+		name     string
+		def      types.Definition
+		expected string
+		ok       bool
+	}{
+		{"Reference without parens", types.Definition{Type: types.DefinitionTypeInt, Expression: "{{Retries}} * 2"}, "Retries * 2", true},
+		{"Qualified reference", types.Definition{Type: types.DefinitionTypeInt, Expression: "{{Far}} + 1"}, "other.Far + 1", true},
+		{"Parens kept where needed", types.Definition{Type: types.DefinitionTypeInt, Expression: "({{Retries}} + 1) * 2"}, "(Retries + 1) * 2", true},
+		{"Typed integers unified", types.Definition{Type: types.DefinitionTypeInt64, Expression: "{{Retries}} * {{Small}}"}, "int64(Retries * int(Small))", true},
+		{"Duration times int", types.Definition{Type: types.DefinitionTypeDuration, Expression: "{{Timeout}} * {{Retries}} + 1m30s"}, "Timeout * time.Duration(Retries) + (1*time.Minute + 30*time.Second)", true},
+		{"Duration ratio", types.Definition{Type: types.DefinitionTypeInt, Expression: "{{Minutes}} / {{Timeout}}"}, "int(Minutes / Timeout)", true},
+		{"Comparison", types.Definition{Type: types.DefinitionTypeBool, Expression: "{{Retries}} > 2 && !{{Debug}}"}, "Retries > 2 && !Debug", true},
+		{"String concatenation", types.Definition{Type: types.DefinitionTypeString, Expression: "https://{{Env}}.example.com"}, `"https://" + Env + ".example.com"`, true},
+		{"Hex literal", types.Definition{Type: types.DefinitionTypeInt, Expression: "0xFF & {{Retries}}"}, "", false},
+		{"Float reference", types.Definition{Type: types.DefinitionTypeFloat64, Expression: "{{Ratio}} * 2"}, "", false},
+		{"Int in string", types.Definition{Type: types.DefinitionTypeString, Expression: "v{{Retries}}"}, "", false},
+		{"Function call", types.Definition{Type: types.DefinitionTypeInt, Expression: "max({{Retries}}, 2)"}, "", false},
+		{"Non-scalar reference", types.Definition{Type: types.DefinitionTypeString, Expression: "{{Ports}}"}, "", false},
+		{"Unresolvable reference", types.Definition{Type: types.DefinitionTypeInt, Expression: "{{Missing}} + 1"}, "", false},
+		{"Overflow in typed constant", types.Definition{Type: types.DefinitionTypeInt64, Expression: "{{Small}} << 40"}, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, ok := SymbolicGo(tt.def, symbolResolver)
+			if ok != tt.ok || result != tt.expected {
+				t.Errorf("SymbolicGo() = %q, %v, expected %q, %v", result, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+func TestSymbolicTS(t *testing.T) {
+	tests := []struct {
+		name     string
+		def      types.Definition
+		expected string
+		ok       bool
+	}{
+		{"Reference without parens", types.Definition{Type: types.DefinitionTypeInt, Expression: "{{Retries}} * 2"}, "Retries * 2", true},
+		{"Strict equality", types.Definition{Type: types.DefinitionTypeBool, Expression: "{{Env}} == \"prod\" || {{Debug}}"}, `Env === "prod" || Debug`, true},
+		{"Relational binds tighter than equality", types.Definition{Type: types.DefinitionTypeBool, Expression: "{{Retries}} > 2 == {{Debug}}"}, "Retries > 2 === Debug", true},
+		{"Exact division", types.Definition{Type: types.DefinitionTypeInt, Expression: "{{Retries}} * 2 / 3"}, "Retries * 2 / 3", true},
+		{"Truncated division", types.Definition{Type: types.DefinitionTypeInt, Expression: "{{Retries}} / 2"}, "Math.trunc(Retries / 2)", true},
+		{"Min and max", types.Definition{Type: types.DefinitionTypeInt, Expression: "max({{Retries}}, 2)"}, "Math.max(Retries, 2)", true},
+		{"Duration in same unit", types.Definition{Type: types.DefinitionTypeDuration, Expression: "{{Timeout}} * 2 + 1s"}, "Timeout * 2 + 1000", true},
+		{"Template literal", types.Definition{Type: types.DefinitionTypeString, Expression: "`{{Env}}`-{{Retries}}"}, "`\\`${Env}\\`-${Retries}`", true},
+		{"Duration in seconds", types.Definition{Type: types.DefinitionTypeDuration, Expression: "{{Minutes}} * 2"}, "", false},
+		{"Unsafe integer", types.Definition{Type: types.DefinitionTypeInt64, Expression: "{{Big}} + 1"}, "", false},
+		{"Bigint mode", types.Definition{Type: types.DefinitionTypeInt64, Expression: "{{Retries}} + 1", TSMode: types.ModeBigInt}, "", false},
+		{"Shift", types.Definition{Type: types.DefinitionTypeInt, Expression: "1 << {{Retries}}"}, "", false},
+		{"Upper", types.Definition{Type: types.DefinitionTypeString, Expression: "{{upper(Env)}}"}, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, ok := SymbolicTS(tt.def, symbolResolver)
+			if ok != tt.ok || result != tt.expected {
+				t.Errorf("SymbolicTS() = %q, %v, expected %q, %v", result, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
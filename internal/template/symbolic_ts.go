@@ -0,0 +1,233 @@
+package template
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/nantokaworks/konst/internal/expr"
+	"github.com/nantokaworks/konst/internal/types"
+	"github.com/nantokaworks/konst/internal/utils"
+)
+
+// tsBinaryPrec は TypeScript の二項演算子の優先順位です。
+// 式の構文と異なり、大小比較は等価比較より先に結合します。シフトは number が32ビット整数として扱われるため変換しません。
+var tsBinaryPrec = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"==": 3, "!=": 3,
+	"<": 4, "<=": 4, ">": 4, ">=": 4,
+	"+": 5, "-": 5,
+	"*": 6, "/": 6, "%": 6,
+}
+
+// tsOperators は式の演算子と表記の異なる TypeScript の演算子です
+var tsOperators = map[string]string{"==": "===", "!=": "!=="}
+
+const (
+	tsPrecUnary  = 7 // 単項演算子の式
+	tsPrecAtomic = 8 // 括弧が不要な式（リテラル・定義名・括弧で囲んだ式・関数呼び出し）
+)
+
+// tsMaxSafeInteger は number で誤差なく表せる整数の最大値（Number.MAX_SAFE_INTEGER）です
+var tsMaxSafeInteger = big.NewInt(1<<53 - 1)
+
+// tsExpr は TypeScript の式です
+type tsExpr struct {
+	code string
+	val  expr.Value // 式の値
+	prec int        // 最も外側の演算子の優先順位
+}
+
+// tsSymbolic は式を TypeScript の式に変換します
+type tsSymbolic struct {
+	symbolic
+	unit time.Duration // 期間を number にするときの単位（期間型の定義の式のみ）
+}
+
+// SymbolicTS は定義 def の式（def.Expression）を、参照先の定数を使った TypeScript の式にします。
+// TypeScript の number の計算で計算した値と異なる値になる可能性がある場合は ok が false になります。
+func SymbolicTS(def types.Definition, resolve SymbolResolver) (string, bool) {
+	if def.Expression == "" || !isTSSymbolicType(def) {
+		return "", false
+	}
+	parts, err := utils.ExpressionParts(def.Expression, def.Type)
+	if err != nil {
+		return "", false
+	}
+	t := &tsSymbolic{symbolic: symbolic{def: def, resolve: resolve}}
+	if def.Type == types.DefinitionTypeDuration {
+		t.unit = tsDurationNanos(def)
+	}
+	if def.Type == types.DefinitionTypeString {
+		return t.template(parts)
+	}
+	e, ok := t.translate(parts[0].Node)
+	return e.code, ok
+}
+
+// isTSSymbolicType は TypeScript で式のまま出力できる定義の型かどうかを返します。
+// 整数と float64 は tsMode を指定しない number、string は日付として出力されないものに限ります。
+func isTSSymbolicType(def types.Definition) bool {
+	switch {
+	case utils.IsIntegerType(def.Type), def.Type == types.DefinitionTypeFloat64:
+		return def.TSMode == ""
+	case def.Type == types.DefinitionTypeString:
+		return def.TSMode == "" && !isDateString(def)
+	}
+	return def.Type == types.DefinitionTypeBool || def.Type == types.DefinitionTypeDuration
+}
+
+// template は string の値をテンプレートリテラルにします。{{式}} は文字列・整数・真偽値の式を使えます
+func (t *tsSymbolic) template(parts []utils.ExpressionPart) (string, bool) {
+	if len(parts) == 1 && parts[0].Node != nil {
+		e, ok := t.translate(parts[0].Node)
+		return e.code, ok && e.val.Kind == expr.KindString
+	}
+	var b strings.Builder
+	b.WriteString("`")
+	for _, part := range parts {
+		if part.Node == nil {
+			b.WriteString(escapeTemplateLiteral(part.Text))
+			continue
+		}
+		e, ok := t.translate(part.Node)
+		if !ok || (e.val.Kind != expr.KindString && e.val.Kind != expr.KindInt && e.val.Kind != expr.KindBool) {
+			return "", false
+		}
+		b.WriteString("${" + e.code + "}")
+	}
+	b.WriteString("`")
+	return b.String(), true
+}
+
+// escapeTemplateLiteral はテンプレートリテラルの中で特別な意味を持つ文字をエスケープします
+func escapeTemplateLiteral(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "`", "\\`", "${", "\\${", "\r", `\r`).Replace(s)
+}
+
+// translate は式のノードを TypeScript の式にします
+func (t *tsSymbolic) translate(n expr.Node) (tsExpr, bool) {
+	v, ok := t.eval(n)
+	if !ok || !t.exact(v) {
+		return tsExpr{}, false
+	}
+
+	switch n := n.(type) {
+	case *expr.NumberLit:
+		return tsExpr{code: numberCode(n, v), val: v, prec: tsPrecAtomic}, true
+	case *expr.DurationLit:
+		if t.unit == 0 {
+			return tsExpr{}, false
+		}
+		return tsExpr{code: t.number(v).String(), val: v, prec: tsPrecAtomic}, true
+	case *expr.StringLit:
+		return tsExpr{code: fmt.Sprintf("%q", n.Value), val: v, prec: tsPrecAtomic}, true
+	case *expr.BoolLit:
+		return tsExpr{code: formatTSBool(n.Value), val: v, prec: tsPrecAtomic}, true
+	case *expr.Ident:
+		ref, ok := t.resolve(n.Name)
+		if !ok || !isTSSymbolicType(ref.Def) {
+			return tsExpr{}, false
+		}
+		// 期間は同じ単位の number として出力されている場合だけ参照できる
+		if ref.Def.Type == types.DefinitionTypeDuration && (t.unit == 0 || tsDurationNanos(ref.Def) != t.unit) {
+			return tsExpr{}, false
+		}
+		return tsExpr{code: ref.Code, val: v, prec: tsPrecAtomic}, true
+	case *expr.Paren:
+		x, ok := t.translate(n.X)
+		if !ok {
+			return tsExpr{}, false
+		}
+		if x.prec == tsPrecAtomic {
+			// {{Name}} は括弧で囲んだ式として解析されるため、括弧が不要な式の括弧は出力しない
+			return x, true
+		}
+		return tsExpr{code: "(" + x.code + ")", val: v, prec: tsPrecAtomic}, true
+	case *expr.Unary:
+		x, ok := t.translate(n.X)
+		if !ok {
+			return tsExpr{}, false
+		}
+		return tsExpr{code: n.Op + operand(x.code, x.prec, tsPrecAtomic, false), val: v, prec: tsPrecUnary}, true
+	case *expr.Binary:
+		return t.binary(n, v)
+	case *expr.Call:
+		return t.call(n, v)
+	}
+	return tsExpr{}, false
+}
+
+// binary は二項演算子の式を TypeScript の式にします。
+// 整数の除算は割り切れる場合はそのまま、割り切れない場合は Math.trunc で0方向に切り捨てます。
+func (t *tsSymbolic) binary(n *expr.Binary, v expr.Value) (tsExpr, bool) {
+	prec, ok := tsBinaryPrec[n.Op]
+	if !ok {
+		return tsExpr{}, false
+	}
+	x, ok := t.translate(n.X)
+	if !ok {
+		return tsExpr{}, false
+	}
+	y, ok := t.translate(n.Y)
+	if !ok {
+		return tsExpr{}, false
+	}
+	op := n.Op
+	if o, ok := tsOperators[op]; ok {
+		op = o
+	}
+	code := operand(x.code, x.prec, prec, false) + " " + op + " " + operand(y.code, y.prec, prec, true)
+
+	if n.Op == "/" {
+		if new(big.Int).Rem(t.number(x.val), t.number(y.val)).Sign() != 0 {
+			// 割り切れない期間の除算はナノ秒単位の切り捨てになるため表せない
+			if v.Kind != expr.KindInt {
+				return tsExpr{}, false
+			}
+			return tsExpr{code: "Math.trunc(" + code + ")", val: v, prec: tsPrecAtomic}, true
+		}
+	}
+	return tsExpr{code: code, val: v, prec: prec}, true
+}
+
+// call は関数呼び出しを TypeScript の式にします。数値・期間の min・max だけを Math.min・Math.max にします
+func (t *tsSymbolic) call(n *expr.Call, v expr.Value) (tsExpr, bool) {
+	if n.Func != "min" && n.Func != "max" {
+		// len は文字数の数え方（UTF-16）、upper は大文字への変換規則が Go と異なる
+		return tsExpr{}, false
+	}
+	args := make([]string, len(n.Args))
+	for i, arg := range n.Args {
+		a, ok := t.translate(arg)
+		if !ok || (a.val.Kind != expr.KindInt && a.val.Kind != expr.KindDuration) {
+			return tsExpr{}, false
+		}
+		args[i] = a.code
+	}
+	return tsExpr{code: "Math." + n.Func + "(" + strings.Join(args, ", ") + ")", val: v, prec: tsPrecAtomic}, true
+}
+
+// exact は値を number で誤差なく表せるかどうかを返します。
+// 整数は安全な整数の範囲、期間は出力する単位で割り切れる場合に限ります。小数は対象外です。
+func (t *tsSymbolic) exact(v expr.Value) bool {
+	switch v.Kind {
+	case expr.KindInt:
+		return new(big.Int).Abs(v.Int).Cmp(tsMaxSafeInteger) <= 0
+	case expr.KindDuration:
+		return t.unit != 0 && v.Duration%t.unit == 0
+	case expr.KindFloat:
+		return false
+	}
+	return true
+}
+
+// number は整数・期間の値を TypeScript で出力する number の値にします（期間は単位で割った値）
+func (t *tsSymbolic) number(v expr.Value) *big.Int {
+	if v.Kind == expr.KindDuration {
+		return big.NewInt(int64(v.Duration / t.unit))
+	}
+	return v.Int
+}
@@ -0,0 +1,69 @@
+package template
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/nantokaworks/konst/internal/types"
+	"github.com/nantokaworks/konst/internal/utils"
+)
+
+// tableKey は table 型のキー列1つ分の検索関数を生成するための情報です
+type tableKey struct {
+	Column string        // 列名
+	Name   string        // 関数名に使う列名（パスカルケース）
+	Index  string        // キーから行番号を引くマップの変数名
+	GoType string        // Go のキーの型
+	TSType string        // TypeScript のキーの型
+	Rows   []tableKeyRow // 行ごとのキー
+}
+
+// tableKeyRow は1行分のキーのリテラルと行番号です
+type tableKeyRow struct {
+	Go  string
+	TS  string
+	Row int
+}
+
+// tableKeys は table 型の定義から key が指定された列の検索用の情報を列の順に返します
+func tableKeys(def types.Definition) []tableKey {
+	var keys []tableKey
+	for _, col := range def.Columns {
+		if !col.Key {
+			continue
+		}
+		name := utils.ToPascalCase(col.Name)
+		colDef := col.Definition(def, name, nil)
+		key := tableKey{
+			Column: col.Name,
+			Name:   name,
+			Index:  tableIndexName(def.Name, name),
+			GoType: goType(colDef),
+			TSType: tsType(colDef),
+		}
+		for i, row := range def.Rows {
+			colDef.Value = row[col.Name]
+			key.Rows = append(key.Rows, tableKeyRow{
+				Go:  formatConstValue(colDef),
+				TS:  formatTSConstValue(colDef),
+				Row: i,
+			})
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// tableIndexName は table 型の定義 table のキー列 key（パスカルケース）の値から行番号を引くマップの変数名を返します
+func tableIndexName(table, key string) string {
+	return lowerFirst(table) + "By" + key + "Index"
+}
+
+// lowerFirst は先頭の文字を小文字にします（Go の非公開の識別子に使います）
+func lowerFirst(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+	return string(unicode.ToLower(r)) + s[size:]
+}
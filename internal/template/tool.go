@@ -1,18 +1,26 @@
 package template
 
 import (
+	"fmt"
 	"sort"
 	"strings"
 	"time"
 
 	"github.com/nantokaworks/konst/internal/types"
+	"github.com/nantokaworks/konst/internal/utils"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 )
 
-// toTitle は文字列をタイトルケースに変換します
+// toTitle は enum の値を定数名に使う形（単語の先頭を大文字にし、識別子に使えない文字を取り除いた形）に変換します
 func toTitle(s string) string {
-	return cases.Title(language.Und, cases.NoLower).String(s)
+	return utils.EnumMemberName(s)
+}
+
+// toTitleValue はテンプレート用の toTitle です。
+// enum の値（types.EnumValue）もそのまま渡せるよう、文字列以外は文字列に変換してから処理します。
+func toTitleValue(v any) string {
+	return toTitle(fmt.Sprint(v))
 }
 
 // toCamel は文字列をキャメルケースに変換します（twitch_id -> twitchId）
@@ -63,6 +71,30 @@ func tryParseDate(s string) (time.Time, bool) {
 	return t, true
 }
 
+// docText は定義の説明を返します。description がなければ定義ファイル内のコメントを使います
+func docText(def types.Definition) string {
+	if def.Description != "" {
+		return def.Description
+	}
+	return def.Doc
+}
+
+// enumValueDescription は enum 値の説明を返します。値に書かれた description を valueDescriptions より優先します
+func enumValueDescription(def types.Definition, value types.EnumValue) string {
+	if value.Description != "" {
+		return value.Description
+	}
+	return def.ValueDescriptions[value.Name]
+}
+
+// deprecationMessage は GoDoc の "Deprecated:" 段落に書く非推奨の理由を返します
+func deprecationMessage(def types.Definition) string {
+	if def.Deprecated.Message != "" {
+		return def.Deprecated.Message
+	}
+	return "This definition is deprecated."
+}
+
 // hasEnum は定義の中にenum型があるかチェックします
 func hasEnum(definitions map[string]types.Definition) bool {
 	for _, def := range definitions {
@@ -73,6 +105,16 @@ func hasEnum(definitions map[string]types.Definition) bool {
 	return false
 }
 
+// hasIntEnum は定義の中に整数の enum があるかチェックします
+func hasIntEnum(definitions map[string]types.Definition) bool {
+	for _, def := range definitions {
+		if def.IsIntEnum() {
+			return true
+		}
+	}
+	return false
+}
+
 // hasTemplate は定義の中にtemplate型があるかチェックします
 func hasTemplate(definitions map[string]types.Definition) bool {
 	for _, def := range definitions {
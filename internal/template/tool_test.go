@@ -17,6 +17,8 @@ func TestToTitle(t *testing.T) {
 		{"a", "A"},
 		{"TEST", "TEST"},
 		{"test_case", "Test_case"},
+		{"pending-review", "PendingReview"},
+		{"on hold", "OnHold"},
 	}
 
 	for _, tt := range tests {
@@ -147,3 +149,35 @@ func TestTryParseDate(t *testing.T) {
 		}
 	}
 }
+
+func TestHasIntEnum(t *testing.T) {
+	tests := []struct {
+		name        string
+		definitions map[string]types.Definition
+		expected    bool
+	}{
+		{
+			name: "Has int enum",
+			definitions: map[string]types.Definition{
+				"Level": {Type: types.DefinitionTypeEnum, Values: []types.EnumValue{{Name: "Low", Value: "1"}, {Name: "High", Value: "10"}}},
+			},
+			expected: true,
+		},
+		{
+			name: "String enum only",
+			definitions: map[string]types.Definition{
+				"Status": {Type: types.DefinitionTypeEnum, Values: types.StringEnumValues("active")},
+			},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := hasIntEnum(tt.definitions)
+			if result != tt.expected {
+				t.Errorf("hasIntEnum() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
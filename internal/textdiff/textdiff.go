@@ -0,0 +1,240 @@
+// Package textdiff はテキストの行単位の差分を unified diff 形式で出力します。
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// context は変更箇所の前後に出力する変更のない行数です
+const context = 3
+
+// opKind は編集操作の種類です
+type opKind byte
+
+const (
+	opEqual  opKind = ' '
+	opDelete opKind = '-'
+	opInsert opKind = '+'
+)
+
+// op は1行の編集操作です。a, b は操作の直前の変更前・変更後の行番号（0始まり）です
+type op struct {
+	kind opKind
+	line string
+	a, b int
+}
+
+// Unified は変更前 before（名前 oldName）から変更後 after（名前 newName）への unified diff を返します。
+// 差分がない場合は空文字列を返します。
+func Unified(oldName, newName, before, after string) string {
+	if before == after {
+		return ""
+	}
+	ops := diff(splitLines(before), splitLines(after))
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", oldName, newName)
+	for start := 0; start < len(ops); {
+		// 次の変更箇所を探す
+		first := start
+		for first < len(ops) && ops[first].kind == opEqual {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+		// 変更のない行が context*2 行以下しか挟まらない変更箇所は同じハンクにまとめる
+		last := first
+		for i := first + 1; i < len(ops) && i-last-1 <= context*2; i++ {
+			if ops[i].kind != opEqual {
+				last = i
+			}
+		}
+		from := max(first-context, start)
+		to := min(last+context+1, len(ops))
+		writeHunk(&sb, ops[from:to])
+		start = to
+	}
+	return sb.String()
+}
+
+// writeHunk は1つのハンクを出力します
+func writeHunk(sb *strings.Builder, ops []op) {
+	oldCount, newCount := 0, 0
+	for _, o := range ops {
+		if o.kind != opInsert {
+			oldCount++
+		}
+		if o.kind != opDelete {
+			newCount++
+		}
+	}
+	fmt.Fprintf(sb, "@@ -%s +%s @@\n", hunkRange(ops[0].a, oldCount), hunkRange(ops[0].b, newCount))
+	for _, o := range ops {
+		sb.WriteByte(byte(o.kind))
+		sb.WriteString(o.line)
+		if !strings.HasSuffix(o.line, "\n") {
+			sb.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange はハンクの行範囲を GNU diff と同じ形式で返します。
+// 行数が 0 の場合の開始行は直前の行、1 の場合は行数を省略します。
+func hunkRange(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// splitLines は s を改行を含めた行に分割します
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diff は Myers の差分アルゴリズムで a から b への最短の編集操作を返します。
+// 編集距離ごとの到達位置を全て記録せず、両端から探索して見つけた中間の一致で分割するため、使うメモリは行数に比例します。
+func diff(a, b []string) []op {
+	d := &differ{a: a, b: b}
+	d.compare(0, len(a), 0, len(b))
+	return d.ops
+}
+
+// differ は a から b への編集操作を集めます
+type differ struct {
+	a, b []string
+	ops  []op
+}
+
+// compare は a[aLo:aHi] から b[bLo:bHi] への編集操作を追加します
+func (d *differ) compare(aLo, aHi, bLo, bHi int) {
+	// 先頭と末尾の一致する行を除く
+	for aLo < aHi && bLo < bHi && d.a[aLo] == d.b[bLo] {
+		d.ops = append(d.ops, op{opEqual, d.a[aLo], aLo, bLo})
+		aLo++
+		bLo++
+	}
+	suffix := 0
+	for aLo < aHi-suffix && bLo < bHi-suffix && d.a[aHi-suffix-1] == d.b[bHi-suffix-1] {
+		suffix++
+	}
+	aHi -= suffix
+	bHi -= suffix
+
+	switch {
+	case aLo == aHi:
+		for y := bLo; y < bHi; y++ {
+			d.ops = append(d.ops, op{opInsert, d.b[y], aLo, y})
+		}
+	case bLo == bHi:
+		for x := aLo; x < aHi; x++ {
+			d.ops = append(d.ops, op{opDelete, d.a[x], x, bLo})
+		}
+	default:
+		if x, y, ok := d.bisect(aLo, aHi, bLo, bHi); ok {
+			d.compare(aLo, x, bLo, y)
+			d.compare(x, aHi, y, bHi)
+		} else {
+			// 一致する行がない場合は全て置き換える
+			for x := aLo; x < aHi; x++ {
+				d.ops = append(d.ops, op{opDelete, d.a[x], x, bLo})
+			}
+			for y := bLo; y < bHi; y++ {
+				d.ops = append(d.ops, op{opInsert, d.b[y], aHi, y})
+			}
+		}
+	}
+
+	for i := 0; i < suffix; i++ {
+		d.ops = append(d.ops, op{opEqual, d.a[aHi+i], aHi + i, bHi + i})
+	}
+}
+
+// bisect は a[aLo:aHi] と b[bLo:bHi] の最短の編集の経路を前後から同時に探索し、経路が重なる位置（分割する位置）を返します。
+// 経路が重ならない（一致する行がない）場合は ok が false になります。
+func (d *differ) bisect(aLo, aHi, bLo, bHi int) (x, y int, ok bool) {
+	n, m := aHi-aLo, bHi-bLo
+	maxD := (n + m + 1) / 2
+	offset := maxD + 1
+	// 対角線 k → 到達した行数（後ろからの探索では末尾からの行数）。未到達は -1
+	forward := make([]int, 2*offset+1)
+	backward := make([]int, 2*offset+1)
+	for i := range forward {
+		forward[i], backward[i] = -1, -1
+	}
+	forward[offset+1], backward[offset+1] = 0, 0
+	delta := n - m
+	// 対角線の数の偶奇で、前後どちらの探索で重なりを確認するかが決まる
+	front := delta%2 != 0
+	// 範囲外に出た対角線を次の編集距離から除く
+	kStart, kEnd, rStart, rEnd := 0, 0, 0, 0
+
+	for e := 0; e < maxD; e++ {
+		for k := -e + kStart; k <= e-kEnd; k += 2 {
+			var fx int
+			if k == -e || (k != e && forward[offset+k-1] < forward[offset+k+1]) {
+				fx = forward[offset+k+1]
+			} else {
+				fx = forward[offset+k-1] + 1
+			}
+			fy := fx - k
+			for fx < n && fy < m && d.a[aLo+fx] == d.b[bLo+fy] {
+				fx++
+				fy++
+			}
+			forward[offset+k] = fx
+			switch {
+			case fx > n:
+				kEnd += 2
+			case fy > m:
+				kStart += 2
+			case front:
+				if r := offset + delta - k; r >= 0 && r < len(backward) && backward[r] != -1 && fx >= n-backward[r] {
+					return aLo + fx, bLo + fy, true
+				}
+			}
+		}
+
+		for k := -e + rStart; k <= e-rEnd; k += 2 {
+			var rx int
+			if k == -e || (k != e && backward[offset+k-1] < backward[offset+k+1]) {
+				rx = backward[offset+k+1]
+			} else {
+				rx = backward[offset+k-1] + 1
+			}
+			ry := rx - k
+			for rx < n && ry < m && d.a[aHi-rx-1] == d.b[bHi-ry-1] {
+				rx++
+				ry++
+			}
+			backward[offset+k] = rx
+			switch {
+			case rx > n:
+				rEnd += 2
+			case ry > m:
+				rStart += 2
+			case !front:
+				if f := offset + delta - k; f >= 0 && f < len(forward) && forward[f] != -1 {
+					fx := forward[f]
+					fy := fx - (f - offset)
+					if fx >= n-rx {
+						return aLo + fx, bLo + fy, true
+					}
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,138 @@
+package textdiff
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   string
+		after    string
+		expected string
+	}{
+		{"No change", "a\nb\n", "a\nb\n", ""},
+		{
+			"Changed line",
+			"a\nb\nc\n",
+			"a\nB\nc\n",
+			"--- old\n+++ new\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			"Created file",
+			"",
+			"a\nb\n",
+			"--- old\n+++ new\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			"Insert only",
+			"a\nb\n",
+			"a\nx\nb\n",
+			"--- old\n+++ new\n@@ -1,2 +1,3 @@\n a\n+x\n b\n",
+		},
+		{
+			"Separate hunks",
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			"one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ntwelve\n",
+			"--- old\n+++ new\n@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+twelve\n",
+		},
+		{
+			"Merged hunks",
+			"1\n2\n3\n4\n5\n6\n7\n8\n",
+			"one\n2\n3\n4\n5\n6\n7\neight\n",
+			"--- old\n+++ new\n@@ -1,8 +1,8 @@\n-1\n+one\n 2\n 3\n 4\n 5\n 6\n 7\n-8\n+eight\n",
+		},
+		{
+			"No newline at end of file",
+			"a\nb",
+			"a\nb\n",
+			"--- old\n+++ new\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := Unified("old", "new", tt.before, tt.after); result != tt.expected {
+				t.Errorf("Unified() =\n%s\nexpected\n%s", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	large := func(prefix string, n int) []string {
+		lines := make([]string, n)
+		for i := range lines {
+			lines[i] = fmt.Sprintf("%s%d", prefix, i)
+		}
+		return lines
+	}
+	rng := rand.New(rand.NewSource(1))
+	random := func() []string {
+		lines := make([]string, rng.Intn(12))
+		for i := range lines {
+			lines[i] = string(rune('a' + rng.Intn(3)))
+		}
+		return lines
+	}
+
+	type pair struct{ a, b []string }
+	pairs := []pair{
+		// 全ての行が変わる大きなファイルも、編集距離の2乗のメモリを使わずに比較する
+		{large("old", 5000), large("new", 5000)},
+		{large("line", 5000), append(large("line", 2500), large("new", 2500)...)},
+	}
+	for i := 0; i < 200; i++ {
+		pairs = append(pairs, pair{random(), random()})
+	}
+
+	for i, p := range pairs {
+		ops := diff(p.a, p.b)
+		var a, b []string
+		edits := 0
+		for _, o := range ops {
+			if o.a != len(a) || o.b != len(b) {
+				t.Fatalf("pair %d: op at (%d, %d), expected (%d, %d)", i, o.a, o.b, len(a), len(b))
+			}
+			switch o.kind {
+			case opEqual:
+				a = append(a, o.line)
+				b = append(b, o.line)
+			case opDelete:
+				a = append(a, o.line)
+				edits++
+			case opInsert:
+				b = append(b, o.line)
+				edits++
+			}
+		}
+		if fmt.Sprint(a) != fmt.Sprint(p.a) || fmt.Sprint(b) != fmt.Sprint(p.b) {
+			t.Fatalf("pair %d: ops do not reproduce the inputs", i)
+		}
+		if len(p.a) <= 12 && len(p.b) <= 12 {
+			if expected := len(p.a) + len(p.b) - 2*lcs(p.a, p.b); edits != expected {
+				t.Errorf("pair %d: %d edits, expected %d", i, edits, expected)
+			}
+		}
+	}
+}
+
+// lcs は a と b の最長共通部分列の長さを返します
+func lcs(a, b []string) int {
+	table := make([][]int, len(a)+1)
+	for i := range table {
+		table[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				table[i][j] = table[i+1][j+1] + 1
+			} else {
+				table[i][j] = max(table[i+1][j], table[i][j+1])
+			}
+		}
+	}
+	return table[0][0]
+}
@@ -12,4 +12,10 @@ type CommandOption struct {
 	Watch        *bool   // ウォッチモード
 	NamingStyle  *string // ファイル命名規則 (kebab, camel, snake)
 	Locale       *string // 言語設定 (ja, en)
+	Format       *string // 診断の出力形式 (text, json, sarif)
+	Extensions   *string // 読み込む定義ファイルの拡張子 (json,yaml,yml,toml のカンマ区切り)
+	Symbolic     *bool   // {{参照}} を生成コードでも式のまま出力する
+	Diff         *bool   // ドライランで既存のファイルとの差分を表示する
+	Check        *bool   // 生成済みのコードが最新か確認する
+	Prune        *bool   // 生成されなくなったファイルを削除する
 }
@@ -17,19 +17,76 @@ const (
 	DefinitionTypeBool      DefinitionType = "bool"
 	DefinitionTypeDate      DefinitionType = "date"
 	DefinitionTypeTimestamp DefinitionType = "timestamp" // 日付のtimestamp型
+	DefinitionTypeDuration  DefinitionType = "duration"  // 期間（"30s"、"1h30m" など）
 	DefinitionTypeEnum      DefinitionType = "enum"      // 列挙型
 	DefinitionTypeTemplate  DefinitionType = "template"  // テンプレート文字列
+	DefinitionTypeObject    DefinitionType = "object"    // フィールドを持つ構造体
+	DefinitionTypeTable     DefinitionType = "table"     // 列の定義と行のリスト
+	DefinitionTypeMap       DefinitionType = "map"       // キーと値の型を宣言した辞書
 )
 
 // Definition は各定義の情報を表します。
 type Definition struct {
-	Type       DefinitionType `json:"type"`
-	Value      interface{}    `json:"value,omitempty"`
-	Values     []string       `json:"values,omitempty"`     // enum型の場合の値リスト
-	Default    string         `json:"default,omitempty"`    // enum型の場合のデフォルト値
-	Template   string         `json:"template,omitempty"`   // template型の場合のテンプレート文字列
-	Parameters []string       `json:"parameters,omitempty"` // template型の場合のパラメータ名リスト
-	TSMode     TSMode         `json:"tsMode,omitempty"`
-	GoMode     GoMode         `json:"goMode,omitempty"`
+	Type       DefinitionType           `json:"type"`
+	Value      interface{}              `json:"value,omitempty"`
+	Values     []EnumValue              `json:"values,omitempty"`     // enum型の場合の値リスト
+	Default    string                   `json:"default,omitempty"`    // enum型の場合のデフォルト値
+	Template   string                   `json:"template,omitempty"`   // template型の場合のテンプレート文字列
+	Parameters []string                 `json:"parameters,omitempty"` // template型の場合のパラメータ名リスト
+	Fields     []Field                  `json:"fields,omitempty"`     // object型の場合のフィールドリスト
+	Columns    []Field                  `json:"columns,omitempty"`    // table型の場合の列リスト
+	Rows       []map[string]interface{} `json:"rows,omitempty"`       // table型の場合の行リスト
+	KeyType    DefinitionType           `json:"keyType,omitempty"`    // map型の場合のキーの型（string・整数型・enum の定義名）
+	ValueType  DefinitionType           `json:"valueType,omitempty"`  // map型の場合の値の型
+	TSMode     TSMode                   `json:"tsMode,omitempty"`
+	GoMode     GoMode                   `json:"goMode,omitempty"`
+
+	Description       string            `json:"description,omitempty"`       // 生成コードのドキュメントコメントに出力する説明
+	Deprecated        Deprecation       `json:"deprecated"`                  // 非推奨の指定（true または理由の文字列）
+	ValueDescriptions map[string]string `json:"valueDescriptions,omitempty"` // enum型の場合の値ごとの説明
 	// DateMode フィールドを廃止し、TSModeで統一します。
+
+	Name    string      `json:"-"` // 定義名（パース時に設定）。object 型の Go の型名に使います
+	KeyEnum *Definition `json:"-"` // map型のキーが enum の場合の enum の定義（依存関係の解決時に設定）
+	Pos     Position    `json:"-"` // 定義ファイル内の位置（パース時に設定）
+	Doc     string      `json:"-"` // 定義の直前に書かれたコメント（JSONC / JSON5 / YAML のみ、パース時に設定）
+
+	Expression string `json:"-"` // {{}} で他の定義を参照する値の元の式（依存関係の解決時に設定）
+	Code       string `json:"-"` // 生成コードで値の代わりに出力する式（参照を式のまま出力する場合に設定）
+	KeyEnumRef string `json:"-"` // map型のキーが別のファイルの enum の場合に Go の生成コードで enum の型を参照する名前（pkg.Enum。生成時に設定）
+}
+
+// IsIntEnum は整数の値を持つ enum（name と value のオブジェクトで値を指定した enum）かどうかを返します
+func (d Definition) IsIntEnum() bool {
+	if d.Type != DefinitionTypeEnum {
+		return false
+	}
+	for _, v := range d.Values {
+		if v.IsInt() {
+			return true
+		}
+	}
+	return false
+}
+
+// RowDefinition は table 型の i 番目の行を、列をフィールドとする object 型の定義として返します
+func (d Definition) RowDefinition(i int) Definition {
+	return Definition{
+		Name:   d.Name,
+		Type:   DefinitionTypeObject,
+		Value:  d.Rows[i],
+		Fields: d.Columns,
+		Pos:    d.Pos,
+	}
+}
+
+// ValueDefinition は map 型の値1つ分を、値の型を持つ定義として返します
+func (d Definition) ValueDefinition(value interface{}) Definition {
+	return Definition{
+		Type:   d.ValueType,
+		Value:  value,
+		TSMode: d.TSMode,
+		GoMode: d.GoMode,
+		Pos:    d.Pos,
+	}
 }
@@ -58,7 +58,7 @@ func TestDefinitionUnmarshal(t *testing.T) {
 			}`,
 			expected: Definition{
 				Type:    DefinitionTypeEnum,
-				Values:  []string{"active", "inactive"},
+				Values:  StringEnumValues("active", "inactive"),
 				Default: "active",
 			},
 		},
@@ -162,3 +162,52 @@ func TestSchemaUnmarshal(t *testing.T) {
 		t.Errorf("Expected enum type, got %s", testEnum.Type)
 	}
 }
+
+func TestDeprecationUnmarshal(t *testing.T) {
+	tests := []struct {
+		jsonData string
+		expected Deprecation
+		wantErr  bool
+	}{
+		{`{"type": "int"}`, Deprecation{}, false},
+		{`{"type": "int", "deprecated": true}`, Deprecation{Deprecated: true}, false},
+		{`{"type": "int", "deprecated": false}`, Deprecation{}, false},
+		{`{"type": "int", "deprecated": "Use MaxAttempts instead."}`, Deprecation{Deprecated: true, Message: "Use MaxAttempts instead."}, false},
+		{`{"type": "int", "deprecated": 1}`, Deprecation{}, true},
+	}
+
+	for _, tt := range tests {
+		var def Definition
+		err := json.Unmarshal([]byte(tt.jsonData), &def)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Unmarshal(%s) error = %v, wantErr %v", tt.jsonData, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && def.Deprecated != tt.expected {
+			t.Errorf("Unmarshal(%s) deprecated = %+v, expected %+v", tt.jsonData, def.Deprecated, tt.expected)
+		}
+	}
+}
+
+func TestSchemaUsesGoEnumInterface(t *testing.T) {
+	tests := []struct {
+		jsonData string
+		expected map[GoEnumInterface]bool
+	}{
+		{`{"definitions": {}}`, map[GoEnumInterface]bool{GoEnumInterfaceStringer: true, GoEnumInterfaceJSON: true, GoEnumInterfaceText: true, GoEnumInterfaceSQL: true}},
+		{`{"goEnumInterfaces": ["json"], "definitions": {}}`, map[GoEnumInterface]bool{GoEnumInterfaceJSON: true}},
+		{`{"goEnumInterfaces": [], "definitions": {}}`, map[GoEnumInterface]bool{}},
+	}
+
+	for _, tt := range tests {
+		var schema Schema
+		if err := json.Unmarshal([]byte(tt.jsonData), &schema); err != nil {
+			t.Fatalf("Unmarshal(%s) failed: %v", tt.jsonData, err)
+		}
+		for _, i := range DefaultGoEnumInterfaces {
+			if got := schema.UsesGoEnumInterface(i); got != tt.expected[i] {
+				t.Errorf("Unmarshal(%s) UsesGoEnumInterface(%s) = %v, expected %v", tt.jsonData, i, got, tt.expected[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Deprecation は定義が非推奨かどうかを表します。
+// 定義ファイルでは true / false、または移行先などを書いた非推奨の理由の文字列で指定します。
+type Deprecation struct {
+	Deprecated bool   // 非推奨かどうか
+	Message    string // 非推奨の理由（true で指定した場合は空）
+}
+
+// UnmarshalJSON は true / false または文字列を受け付けます
+func (d *Deprecation) UnmarshalJSON(data []byte) error {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case nil:
+		*d = Deprecation{}
+	case bool:
+		*d = Deprecation{Deprecated: v}
+	case string:
+		*d = Deprecation{Deprecated: true, Message: v}
+	default:
+		return fmt.Errorf("deprecated must be a boolean or a message, got %s", data)
+	}
+	return nil
+}
+
+// MarshalJSON は理由があれば文字列、なければ true / false として出力します
+func (d Deprecation) MarshalJSON() ([]byte, error) {
+	if d.Deprecated && d.Message != "" {
+		return json.Marshal(d.Message)
+	}
+	return json.Marshal(d.Deprecated)
+}
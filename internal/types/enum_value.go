@@ -0,0 +1,80 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// EnumValue は enum の値の1つです。
+// 定義ファイルでは文字列（文字列の enum）、または name と整数の value を持つオブジェクト（整数の enum）で指定します。
+// オブジェクトでは description で値ごとの説明も書けます。
+type EnumValue struct {
+	Name        string      `json:"name"`                  // 値の名前（文字列の enum では値そのもの）
+	Value       json.Number `json:"value,omitempty"`       // 整数の enum の値（文字列の enum では空）
+	Description string      `json:"description,omitempty"` // 値の説明
+}
+
+// String は値の名前を返します。テンプレートで {{ $value }} と書いた場合も名前が出力されます。
+func (v EnumValue) String() string {
+	return v.Name
+}
+
+// IsInt は整数の値を持つかどうかを返します
+func (v EnumValue) IsInt() bool {
+	return v.Value != ""
+}
+
+// UnmarshalJSON は文字列、または name / value / description を持つオブジェクトを受け付けます
+func (v *EnumValue) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '"' {
+		var name string
+		if err := json.Unmarshal(data, &name); err != nil {
+			return err
+		}
+		*v = EnumValue{Name: name}
+		return nil
+	}
+	if len(data) == 0 || data[0] != '{' {
+		return fmt.Errorf("enum value must be a string or an object with name and value, got %s", data)
+	}
+
+	type plain EnumValue
+	var p plain
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&p); err != nil {
+		return fmt.Errorf("invalid enum value %s: %v", data, err)
+	}
+	*v = EnumValue(p)
+	return nil
+}
+
+// MarshalJSON は名前だけの値を文字列、それ以外をオブジェクトとして出力します
+func (v EnumValue) MarshalJSON() ([]byte, error) {
+	if v.Value == "" && v.Description == "" {
+		return json.Marshal(v.Name)
+	}
+	type plain EnumValue
+	return json.Marshal(plain(v))
+}
+
+// EnumNames は enum の値の名前を定義順に返します
+func EnumNames(values []EnumValue) []string {
+	names := make([]string, 0, len(values))
+	for _, v := range values {
+		names = append(names, v.Name)
+	}
+	return names
+}
+
+// StringEnumValues は文字列の一覧から文字列の enum の値を作ります
+func StringEnumValues(names ...string) []EnumValue {
+	values := make([]EnumValue, 0, len(names))
+	for _, name := range names {
+		values = append(values, EnumValue{Name: name})
+	}
+	return values
+}
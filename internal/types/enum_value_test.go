@@ -0,0 +1,50 @@
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestEnumValueUnmarshal(t *testing.T) {
+	tests := []struct {
+		jsonData string
+		expected []EnumValue
+		isInt    bool
+		wantErr  bool
+	}{
+		{`["active", "inactive"]`, StringEnumValues("active", "inactive"), false, false},
+		{`[{"name": "Low", "value": 1}, {"name": "High", "value": 10, "description": "Urgent"}]`,
+			[]EnumValue{{Name: "Low", Value: "1"}, {Name: "High", Value: "10", Description: "Urgent"}}, true, false},
+		{`[1]`, nil, false, true},
+		{`[{"name": "Low", "number": 1}]`, nil, false, true},
+	}
+
+	for _, tt := range tests {
+		var def Definition
+		err := json.Unmarshal([]byte(`{"type": "enum", "values": `+tt.jsonData+`}`), &def)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Unmarshal(%s) error = %v, wantErr %v", tt.jsonData, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if !reflect.DeepEqual(def.Values, tt.expected) {
+			t.Errorf("Unmarshal(%s) values = %+v, expected %+v", tt.jsonData, def.Values, tt.expected)
+		}
+		if def.IsIntEnum() != tt.isInt {
+			t.Errorf("Unmarshal(%s) IsIntEnum = %v, expected %v", tt.jsonData, def.IsIntEnum(), tt.isInt)
+		}
+
+		// 文字列の値は文字列のまま書き戻される
+		data, err := json.Marshal(def.Values)
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+		var again []EnumValue
+		if err := json.Unmarshal(data, &again); err != nil || !reflect.DeepEqual(again, tt.expected) {
+			t.Errorf("Round trip of %s = %s (%v)", tt.jsonData, data, err)
+		}
+	}
+}
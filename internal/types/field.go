@@ -0,0 +1,27 @@
+package types
+
+// Field は object 型のフィールド、table 型の列の定義です。
+type Field struct {
+	Name        string         `json:"name"`
+	Type        DefinitionType `json:"type"`
+	Fields      []Field        `json:"fields,omitempty"` // object 型のフィールドの場合の入れ子のフィールド
+	TSMode      TSMode         `json:"tsMode,omitempty"`
+	GoMode      GoMode         `json:"goMode,omitempty"`
+	Description string         `json:"description,omitempty"` // 生成コードのフィールドに付けるコメント
+	Key         bool           `json:"key,omitempty"`         // table 型の列の場合、検索関数を生成するキー列かどうか
+}
+
+// Definition はフィールドを値 value を持つ定義として返します。
+// parent は親の定義で、入れ子の object の型名（親の名前 + フィールド名）と位置の決定に使います。
+func (f Field) Definition(parent Definition, goName string, value interface{}) Definition {
+	return Definition{
+		Name:        parent.Name + goName,
+		Type:        f.Type,
+		Value:       value,
+		Fields:      f.Fields,
+		TSMode:      f.TSMode,
+		GoMode:      f.GoMode,
+		Description: f.Description,
+		Pos:         parent.Pos,
+	}
+}
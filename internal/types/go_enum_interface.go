@@ -0,0 +1,29 @@
+package types
+
+// GoEnumInterface は Go の enum 型に実装するインターフェースを示す列挙型です。
+type GoEnumInterface string
+
+const (
+	GoEnumInterfaceStringer GoEnumInterface = "stringer" // fmt.Stringer
+	GoEnumInterfaceJSON     GoEnumInterface = "json"     // json.Marshaler / json.Unmarshaler
+	GoEnumInterfaceText     GoEnumInterface = "text"     // encoding.TextMarshaler / encoding.TextUnmarshaler
+	GoEnumInterfaceSQL      GoEnumInterface = "sql"      // sql.Scanner / driver.Valuer
+)
+
+// DefaultGoEnumInterfaces は goEnumInterfaces を省略した場合に実装するインターフェースです
+var DefaultGoEnumInterfaces = []GoEnumInterface{
+	GoEnumInterfaceStringer,
+	GoEnumInterfaceJSON,
+	GoEnumInterfaceText,
+	GoEnumInterfaceSQL,
+}
+
+// IsKnown は konst が生成できるインターフェースかどうかを返します
+func (i GoEnumInterface) IsKnown() bool {
+	for _, known := range DefaultGoEnumInterfaces {
+		if i == known {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,26 @@
+package types
+
+import "fmt"
+
+// Position は定義ファイル内の位置を表します。Line と Column は 1 始まりです。
+type Position struct {
+	File   string
+	Line   int
+	Column int
+}
+
+// IsValid は行番号を持つ位置かどうかを返します
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+// String は "file:line:col" 形式の文字列を返します。行番号がない場合はファイル名のみを返します。
+func (p Position) String() string {
+	if !p.IsValid() {
+		return p.File
+	}
+	if p.Column > 0 {
+		return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+	}
+	return fmt.Sprintf("%s:%d", p.File, p.Line)
+}
@@ -5,4 +5,41 @@ type Schema struct {
 	Version     string                `json:"version"`
 	GoPackage   string                `json:"goPackage"`
 	Definitions map[string]Definition `json:"definitions"`
+
+	// GoEnumInterfaces は Go の enum 型に実装するインターフェースです。
+	// 省略した場合は DefaultGoEnumInterfaces の全てを実装し、空の配列を指定するとどれも実装しません。
+	GoEnumInterfaces []GoEnumInterface `json:"goEnumInterfaces,omitempty"`
+
+	File    string              `json:"-"` // 読み込み元のファイルパス
+	Keys    map[string]Position `json:"-"` // トップレベルのキーごとの位置
+	Imports []Import            `json:"-"` // 他の定義ファイルの定数や map のキーの enum を参照するための import（生成時に設定）
+}
+
+// Import は生成コードが他の定義ファイルから生成したパッケージ・モジュールを参照するための import です。
+type Import struct {
+	Path  string   // Go の import パス、または TypeScript のモジュールの相対パス（./network など）
+	Name  string   // Go でパッケージを参照する名前（import パスの最後の要素と異なる場合のみ）
+	Names []string // TypeScript で import する定義名
+}
+
+// KeyPos はトップレベルのキーの位置を返します。キーが見つからない場合はファイルのみを示す位置を返します。
+func (s *Schema) KeyPos(key string) Position {
+	if pos, ok := s.Keys[key]; ok {
+		return pos
+	}
+	return Position{File: s.File}
+}
+
+// UsesGoEnumInterface は Go の enum 型に指定したインターフェースを実装するかどうかを返します
+func (s *Schema) UsesGoEnumInterface(i GoEnumInterface) bool {
+	list := s.GoEnumInterfaces
+	if list == nil {
+		list = DefaultGoEnumInterfaces
+	}
+	for _, v := range list {
+		if v == i {
+			return true
+		}
+	}
+	return false
 }
@@ -1,12 +1,14 @@
 package types
 
-// TSMode は、数値および日付出力用のモードを示す列挙型です。
+// TSMode は、数値・日付・期間の出力用のモードを示す列挙型です。
 type TSMode string
 
 const (
-	ModeNumber    TSMode = "number"
-	ModeBigInt    TSMode = "bigint"
-	ModeString    TSMode = "string"
-	ModeDate      TSMode = "date"
-	ModeTimestamp TSMode = "timestamp"
+	ModeNumber       TSMode = "number"
+	ModeBigInt       TSMode = "bigint"
+	ModeString       TSMode = "string"
+	ModeDate         TSMode = "date"
+	ModeTimestamp    TSMode = "timestamp"
+	ModeMilliseconds TSMode = "milliseconds" // duration をミリ秒の数値で出力（デフォルト）
+	ModeSeconds      TSMode = "seconds"      // duration を秒の数値で出力
 )
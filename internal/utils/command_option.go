@@ -43,6 +43,12 @@ func GetCommandOption() (*types.CommandOption, error) {
 	watchFlag := flag.Bool("watch", false, i18n.GetHelpMessage(i18n.HelpWatch))
 	namingStyleFlag := flag.String("naming", "", i18n.GetHelpMessage(i18n.HelpNaming))
 	localeFlag := flag.String("locale", "", i18n.GetHelpMessage(i18n.HelpLocale))
+	formatFlag := flag.String("format", "text", i18n.GetHelpMessage(i18n.HelpFormat))
+	extFlag := flag.String("ext", "", i18n.GetHelpMessage(i18n.HelpExtensions))
+	symbolicFlag := flag.Bool("symbolic", false, i18n.GetHelpMessage(i18n.HelpSymbolic))
+	diffFlag := flag.Bool("diff", false, i18n.GetHelpMessage(i18n.HelpDiff))
+	checkFlag := flag.Bool("check", false, i18n.GetHelpMessage(i18n.HelpCheck))
+	pruneFlag := flag.Bool("prune", false, i18n.GetHelpMessage(i18n.HelpPrune))
 	flag.Parse()
 
 	// バージョン表示処理
@@ -106,5 +112,11 @@ func GetCommandOption() (*types.CommandOption, error) {
 		Watch:       watchFlag,
 		NamingStyle: namingStyleFlag,
 		Locale:      &finalLocale,
+		Format:      formatFlag,
+		Extensions:  extFlag,
+		Symbolic:    symbolicFlag,
+		Diff:        diffFlag,
+		Check:       checkFlag,
+		Prune:       pruneFlag,
 	}, nil
 }
@@ -0,0 +1,394 @@
+package utils
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/nantokaworks/konst/internal/diag"
+	"github.com/nantokaworks/konst/internal/types"
+)
+
+// decodeJSONCSchema は JSONC（コメントと末尾カンマを許す JSON）の定義ファイルをパースします
+func decodeJSONCSchema(path string, data []byte) (*types.Schema, error) {
+	return decodeRelaxedJSONSchema(path, data, false)
+}
+
+// decodeJSON5Schema は JSON5 の定義ファイルをパースします。
+// コメント・末尾カンマに加え、クォートなしのキー、シングルクォート文字列、16進数などの数値表記を受け付けます。
+func decodeJSON5Schema(path string, data []byte) (*types.Schema, error) {
+	return decodeRelaxedJSONSchema(path, data, true)
+}
+
+// decodeRelaxedJSONSchema は JSONC / JSON5 を標準の JSON に変換してからデコードします。
+// 位置は変換前のファイルの位置で報告し、定義の直前のコメントは Definition.Doc に設定します。
+func decodeRelaxedJSONSchema(path string, data []byte, json5 bool) (*types.Schema, error) {
+	c := &jsoncConverter{path: path, src: data, json5: json5, docs: make(map[int64]string), lastToken: -1}
+	if err := c.convert(); err != nil {
+		return nil, err
+	}
+	posAt := c.sourcePosition
+
+	var schema types.Schema
+	if err := decodeJSON(c.out, &schema); err != nil {
+		return nil, diag.FromJSONErrorAt(path, c.out, err, posAt)
+	}
+	top, defs, err := schemaKeyOffsets(c.out)
+	if err != nil {
+		return nil, diag.FromJSONErrorAt(path, c.out, err, posAt)
+	}
+	setSchemaPositions(&schema, path, offsetsToPositions(top, posAt), offsetsToPositions(defs, posAt))
+
+	docs := make(map[string]string)
+	for name, offset := range defs {
+		if doc, ok := c.docs[offset]; ok {
+			docs[name] = doc
+		}
+	}
+	setSchemaDocs(&schema, docs)
+	return &schema, nil
+}
+
+// setSchemaDocs は定義ファイルのコメントを各定義の Doc に設定します
+func setSchemaDocs(schema *types.Schema, docs map[string]string) {
+	for name, doc := range docs {
+		if def, ok := schema.Definitions[name]; ok {
+			def.Doc = doc
+			schema.Definitions[name] = def
+		}
+	}
+}
+
+// jsoncConverter は JSONC / JSON5 を標準の JSON に変換します。
+// 出力の各バイトが元のファイルのどこに対応するかを記録し、エラーや定義の位置を元のファイルの位置で報告できるようにします。
+type jsoncConverter struct {
+	path  string
+	src   []byte
+	json5 bool
+	pos   int // src の読み取り位置
+
+	out     []byte
+	offsets []int64          // out の各バイトに対応する src のオフセット
+	docs    map[int64]string // トークンの直前のコメント（out 内のトークン開始オフセット → コメント本文）
+
+	pending    []string // まだトークンに結び付けていないコメントの行
+	pendingEnd int      // 最後に読んだコメントの終了位置
+	lastToken  int      // 直前のトークンの開始位置（まだなければ -1）
+}
+
+// sourcePosition は out 内のオフセットを元のファイルの位置に変換します
+func (c *jsoncConverter) sourcePosition(offset int64) types.Position {
+	if len(c.offsets) == 0 {
+		return diag.PositionAt(c.path, c.src, int64(len(c.src)))
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= int64(len(c.offsets)) {
+		return diag.PositionAt(c.path, c.src, int64(len(c.src)))
+	}
+	return diag.PositionAt(c.path, c.src, c.offsets[offset])
+}
+
+// errorAt は元のファイルの位置に構文エラーを作成します
+func (c *jsoncConverter) errorAt(offset int, format string, args ...any) error {
+	return diag.New(diag.CodeJSONSyntax, diag.PositionAt(c.path, c.src, int64(offset)), "", format, args...)
+}
+
+// emit は src の from の位置に対応する文字列を出力します
+func (c *jsoncConverter) emit(s string, from int) {
+	for i := 0; i < len(s); i++ {
+		c.out = append(c.out, s[i])
+		c.offsets = append(c.offsets, int64(from))
+	}
+}
+
+func (c *jsoncConverter) convert() error {
+	for c.pos < len(c.src) {
+		ch := c.src[c.pos]
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\r' || ch == '\n':
+			c.emit(string(ch), c.pos)
+			c.pos++
+		case ch == '/' && c.pos+1 < len(c.src) && (c.src[c.pos+1] == '/' || c.src[c.pos+1] == '*'):
+			if err := c.comment(); err != nil {
+				return err
+			}
+		case ch == ',':
+			c.beginToken()
+			if !c.isTrailingComma() {
+				c.emit(",", c.pos)
+			}
+			c.pos++
+		case ch == '"', ch == '\'' && c.json5:
+			if err := c.str(ch); err != nil {
+				return err
+			}
+		case c.json5 && (ch == '+' || ch == '-' || ch == '.' || (ch >= '0' && ch <= '9')):
+			if err := c.number(); err != nil {
+				return err
+			}
+		case c.json5 && isIdentifierStart(c.src[c.pos:]):
+			if err := c.identifier(); err != nil {
+				return err
+			}
+		default:
+			c.beginToken()
+			c.emit(string(ch), c.pos)
+			c.pos++
+		}
+	}
+	return nil
+}
+
+// beginToken はトークンの開始時に呼び、直前の行にあるコメントをそのトークンに結び付けます
+func (c *jsoncConverter) beginToken() {
+	if len(c.pending) > 0 && bytes.Count(c.src[c.pendingEnd:c.pos], []byte("\n")) <= 1 {
+		c.docs[int64(len(c.out))] = strings.Join(c.pending, "\n")
+	}
+	c.pending = nil
+	c.lastToken = c.pos
+}
+
+// comment は // または /* */ のコメントを読み飛ばし、本文を記録します。
+// 同じ行のトークンの後ろに書かれたコメントや、空行で区切られたコメントは次のトークンに結び付けません。
+func (c *jsoncConverter) comment() error {
+	start := c.pos
+	var lines []string
+	if c.src[c.pos+1] == '/' {
+		end := bytes.IndexByte(c.src[start:], '\n')
+		if end < 0 {
+			end = len(c.src)
+		} else {
+			end += start
+		}
+		text := strings.TrimRight(string(c.src[start+2:end]), " \t\r")
+		lines = []string{strings.TrimPrefix(text, " ")}
+		c.pos = end
+	} else {
+		end := bytes.Index(c.src[start+2:], []byte("*/"))
+		if end < 0 {
+			return c.errorAt(start, "unterminated comment")
+		}
+		end += start + 2
+		lines = blockCommentLines(string(c.src[start+2 : end]))
+		c.pos = end + 2
+	}
+
+	trailing := c.lastToken >= 0 && !bytes.Contains(c.src[c.lastToken:start], []byte("\n"))
+	if trailing {
+		c.pending = nil
+		return nil
+	}
+	if len(c.pending) > 0 && bytes.Count(c.src[c.pendingEnd:start], []byte("\n")) > 1 {
+		c.pending = nil
+	}
+	c.pending = append(c.pending, lines...)
+	c.pendingEnd = c.pos
+	return nil
+}
+
+// blockCommentLines は /* */ コメントの本文を行に分け、各行の先頭の "*" を取り除きます
+func blockCommentLines(body string) []string {
+	var lines []string
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		line = strings.TrimPrefix(line, "*")
+		lines = append(lines, strings.TrimPrefix(strings.TrimRight(line, " \t\r"), " "))
+	}
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// isTrailingComma は現在位置のカンマの後ろ（空白とコメントを除く）が閉じ括弧かどうかを返します
+func (c *jsoncConverter) isTrailingComma() bool {
+	i := c.pos + 1
+	for i < len(c.src) {
+		switch {
+		case c.src[i] == ' ' || c.src[i] == '\t' || c.src[i] == '\r' || c.src[i] == '\n':
+			i++
+		case bytes.HasPrefix(c.src[i:], []byte("//")):
+			end := bytes.IndexByte(c.src[i:], '\n')
+			if end < 0 {
+				return false
+			}
+			i += end
+		case bytes.HasPrefix(c.src[i:], []byte("/*")):
+			end := bytes.Index(c.src[i+2:], []byte("*/"))
+			if end < 0 {
+				return false
+			}
+			i += end + 4
+		default:
+			return c.src[i] == '}' || c.src[i] == ']'
+		}
+	}
+	return false
+}
+
+// str は文字列を読み、ダブルクォートの JSON 文字列として出力します。
+// JSON5 では、シングルクォート、\' や \x41 などのエスケープ、行末の \ による継続行を変換します。
+func (c *jsoncConverter) str(quote byte) error {
+	start := c.pos
+	c.beginToken()
+	c.emit(`"`, start)
+	c.pos++
+	for c.pos < len(c.src) {
+		ch := c.src[c.pos]
+		switch {
+		case ch == quote:
+			c.emit(`"`, c.pos)
+			c.pos++
+			return nil
+		case ch == '\\' && c.pos+1 < len(c.src):
+			if c.json5 && c.json5Escape() {
+				continue
+			}
+			c.emit(string(c.src[c.pos:c.pos+2]), c.pos)
+			c.pos += 2
+		case ch == '"':
+			// シングルクォート文字列の中のダブルクォート
+			c.emit(`\"`, c.pos)
+			c.pos++
+		case ch == '\n':
+			return c.errorAt(start, "unterminated string")
+		default:
+			c.emit(string(ch), c.pos)
+			c.pos++
+		}
+	}
+	return c.errorAt(start, "unterminated string")
+}
+
+// json5Escape は JSON にないエスケープを変換して出力し、変換した場合は true を返します
+func (c *jsoncConverter) json5Escape() bool {
+	at := c.pos
+	switch c.src[at+1] {
+	case '\n':
+		c.pos += 2
+	case '\r':
+		c.pos += 2
+		if c.pos < len(c.src) && c.src[c.pos] == '\n' {
+			c.pos++
+		}
+	case '\'':
+		c.emit("'", at)
+		c.pos += 2
+	case 'x':
+		if at+4 > len(c.src) || !isHex(c.src[at+2]) || !isHex(c.src[at+3]) {
+			return false
+		}
+		c.emit(`\u00`+string(c.src[at+2:at+4]), at)
+		c.pos += 4
+	case '0':
+		if at+2 < len(c.src) && c.src[at+2] >= '0' && c.src[at+2] <= '9' {
+			return false
+		}
+		c.emit(`\u0000`, at)
+		c.pos += 2
+	default:
+		return false
+	}
+	return true
+}
+
+// number は JSON5 の数値を読み、JSON の数値リテラルとして出力します
+func (c *jsoncConverter) number() error {
+	start := c.pos
+	c.beginToken()
+	end := start
+	for end < len(c.src) {
+		ch := c.src[end]
+		if ch == '+' || ch == '-' || ch == '.' || (ch >= '0' && ch <= '9') || (ch|0x20 >= 'a' && ch|0x20 <= 'z') {
+			end++
+			continue
+		}
+		break
+	}
+	literal := string(c.src[start:end])
+	converted, ok := json5Number(literal)
+	if !ok {
+		trimmed := strings.TrimLeft(literal, "+-")
+		if trimmed == "Infinity" || trimmed == "NaN" {
+			return c.errorAt(start, "%s is not supported in definition files", literal)
+		}
+		return c.errorAt(start, "invalid number %q", literal)
+	}
+	c.emit(converted, start)
+	c.pos = end
+	return nil
+}
+
+// json5Number は JSON5 の数値表記（+1, .5, 5., 0x1F など）を JSON の数値リテラルに変換します
+func json5Number(literal string) (string, bool) {
+	sign := ""
+	body := literal
+	if strings.HasPrefix(body, "+") || strings.HasPrefix(body, "-") {
+		if body[0] == '-' {
+			sign = "-"
+		}
+		body = body[1:]
+	}
+
+	if strings.HasPrefix(body, "0x") || strings.HasPrefix(body, "0X") {
+		n, ok := new(big.Int).SetString(body[2:], 16)
+		if !ok {
+			return "", false
+		}
+		if sign == "-" {
+			n.Neg(n)
+		}
+		return n.String(), true
+	}
+
+	if strings.HasPrefix(body, ".") {
+		body = "0" + body
+	}
+	if i := strings.Index(body, "."); i >= 0 && (i+1 == len(body) || body[i+1] < '0' || body[i+1] > '9') {
+		body = body[:i] + body[i+1:]
+	}
+	result := sign + body
+	return result, IsNumberLiteral(result)
+}
+
+// identifier は JSON5 のクォートなしのキー、または true / false / null を読みます
+func (c *jsoncConverter) identifier() error {
+	start := c.pos
+	c.beginToken()
+	end := start
+	for end < len(c.src) {
+		r, size := utf8.DecodeRune(c.src[end:])
+		if r != '_' && r != '$' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			break
+		}
+		end += size
+	}
+	word := string(c.src[start:end])
+	switch word {
+	case "true", "false", "null":
+		c.emit(word, start)
+	case "Infinity", "NaN":
+		return c.errorAt(start, "%s is not supported in definition files", word)
+	default:
+		c.emit(`"`+word+`"`, start)
+	}
+	c.pos = end
+	return nil
+}
+
+// isIdentifierStart は data の先頭が JSON5 の識別子の開始文字かどうかを返します
+func isIdentifierStart(data []byte) bool {
+	r, _ := utf8.DecodeRune(data)
+	return r == '_' || r == '$' || unicode.IsLetter(r)
+}
+
+func isHex(ch byte) bool {
+	return (ch >= '0' && ch <= '9') || (ch|0x20 >= 'a' && ch|0x20 <= 'f')
+}
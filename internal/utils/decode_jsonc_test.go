@@ -0,0 +1,161 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nantokaworks/konst/internal/diag"
+)
+
+func TestParseSchemaFileJSON5(t *testing.T) {
+	testJSON5 := `// ファイル全体のコメント
+{
+  version: '1.0',
+  goPackage: "test",
+  definitions: {
+    // リトライ回数の上限
+    // サーバー側の制限に合わせる
+    MaxRetries: { type: 'int', value: 0x1F, }, // 行末のコメント
+    /*
+     * 倍率
+     */
+    Ratio: { type: "float64", value: .5 },
+    Name: { type: "string", value: 'it\'s "ok"' },
+
+    // 空行で離れたコメントは結び付けない
+
+    Level: { type: "enum", values: ["low", "high",], },
+  },
+}
+`
+	testFile := filepath.Join(t.TempDir(), "test.json5")
+	if err := os.WriteFile(testFile, []byte(testJSON5), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	schema, err := ParseSchemaFile(&testFile)
+	if err != nil {
+		t.Fatalf("ParseSchemaFile failed: %v", err)
+	}
+
+	values := map[string]any{
+		"MaxRetries": json.Number("31"),
+		"Ratio":      json.Number("0.5"),
+		"Name":       `it's "ok"`,
+	}
+	for name, value := range values {
+		if got := schema.Definitions[name].Value; got != value {
+			t.Errorf("Expected %s value %#v, got %#v", name, value, got)
+		}
+	}
+	if levels := schema.Definitions["Level"].Values; len(levels) != 2 {
+		t.Errorf("Expected 2 enum values, got %v", levels)
+	}
+
+	docs := map[string]string{
+		"MaxRetries": "リトライ回数の上限\nサーバー側の制限に合わせる",
+		"Ratio":      "倍率",
+		"Name":       "",
+		"Level":      "",
+	}
+	for name, doc := range docs {
+		if got := schema.Definitions[name].Doc; got != doc {
+			t.Errorf("Expected %s doc %q, got %q", name, doc, got)
+		}
+	}
+
+	if pos := schema.Definitions["Ratio"].Pos; pos.Line != 12 || pos.Column != 5 {
+		t.Errorf("Expected Ratio at 12:5, got %s", pos)
+	}
+	if pos := schema.KeyPos("goPackage"); pos.Line != 4 || pos.Column != 3 {
+		t.Errorf("Expected goPackage at 4:3, got %s", pos)
+	}
+}
+
+func TestParseSchemaFileJSONC(t *testing.T) {
+	testJSONC := `{
+  "version": "1.0",
+  "definitions": {
+    /* URL の上限 */
+    "MaxURLLength": {"type": "int", "value": 2048},
+    "Path": {"type": "string", "value": "http://example.com/*"},
+  },
+}
+`
+	testFile := filepath.Join(t.TempDir(), "test.jsonc")
+	if err := os.WriteFile(testFile, []byte(testJSONC), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	schema, err := ParseSchemaFile(&testFile)
+	if err != nil {
+		t.Fatalf("ParseSchemaFile failed: %v", err)
+	}
+	if doc := schema.Definitions["MaxURLLength"].Doc; doc != "URL の上限" {
+		t.Errorf("Expected doc, got %q", doc)
+	}
+	if value := schema.Definitions["Path"].Value; value != "http://example.com/*" {
+		t.Errorf("Expected comment markers in strings to be kept, got %#v", value)
+	}
+}
+
+func TestParseSchemaFileRelaxedJSONErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		file   string
+		data   string
+		line   int
+		column int
+	}{
+		{"Unquoted key in JSONC", "test.jsonc", "{\n  // c\n  definitions: {}\n}", 3, 3},
+		{"Syntax error after comment", "test.json5", "{\n  /* c */ version: '1.0'\n  goPackage: 'x'\n}", 3, 3},
+		{"Unterminated comment", "test.json5", "{\n  /* c\n}", 2, 3},
+		{"Infinity", "test.json5", "{\n  definitions: { X: { type: 'float64', value: -Infinity } }\n}", 2, 47},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testFile := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(testFile, []byte(tt.data), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+
+			_, err := ParseSchemaFile(&testFile)
+			var d *diag.Diagnostic
+			if !errors.As(err, &d) {
+				t.Fatalf("Expected diagnostic, got %v", err)
+			}
+			if d.Pos.File != testFile || d.Pos.Line != tt.line || d.Pos.Column != tt.column {
+				t.Errorf("Expected position %d:%d, got %s (%s)", tt.line, tt.column, d.Pos, d.Message)
+			}
+		})
+	}
+}
+
+func TestJSON5Number(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		ok       bool
+	}{
+		{"42", "42", true},
+		{"+1.5", "1.5", true},
+		{"-.5", "-0.5", true},
+		{"5.", "5", true},
+		{"5.e3", "5e3", true},
+		{"0x1F", "31", true},
+		{"-0xFF", "-255", true},
+		{"0xZZ", "", false},
+		{"Infinity", "", false},
+	}
+
+	for _, tt := range tests {
+		result, ok := json5Number(tt.input)
+		if ok != tt.ok || (ok && result != tt.expected) {
+			t.Errorf("json5Number(%q) = %q, %v, expected %q, %v", tt.input, result, ok, tt.expected, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/nantokaworks/konst/internal/diag"
+	"github.com/nantokaworks/konst/internal/types"
+)
+
+// decodeTOMLSchema は TOML の定義ファイルをパースします。
+// 構造は JSON と同じで、定義は [definitions.Name] テーブルまたは [definitions] 内のインラインテーブルで書きます。
+// TOML の整数は仕様上 int64 の範囲に限られます。日時は RFC3339 の文字列として扱います。
+func decodeTOMLSchema(path string, data []byte) (*types.Schema, error) {
+	var raw map[string]any
+	if _, err := toml.Decode(string(data), &raw); err != nil {
+		return nil, tomlError(path, err)
+	}
+	// デコーダは浮動小数点数を float64 で返し、float64 で表せない桁を失うため、
+	// 浮動小数点数を文字列に書き換えてもう一度デコードし、書かれた表記を取り出す
+	var literals map[string]any
+	if _, err := toml.Decode(string(quoteTOMLFloats(data)), &literals); err != nil {
+		return nil, tomlError(path, err)
+	}
+	top, defs := tomlKeyPositions(path, data)
+	return schemaFromValue(path, tomlValue(raw, literals), top, defs)
+}
+
+// tomlValue は TOML のデコード結果 v を JSON 相当の値（数値は json.Number）に変換します。
+// literal は浮動小数点数を表記の文字列に書き換えてデコードした、v と同じ位置の値です。
+func tomlValue(v, literal any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		literals, _ := literal.(map[string]any)
+		m := make(map[string]any, len(val))
+		for k, elem := range val {
+			m[k] = tomlValue(elem, literals[k])
+		}
+		return m
+	case []map[string]any:
+		literals, _ := literal.([]map[string]any)
+		s := make([]any, 0, len(val))
+		for i, elem := range val {
+			var l any
+			if i < len(literals) {
+				l = literals[i]
+			}
+			s = append(s, tomlValue(elem, l))
+		}
+		return s
+	case []any:
+		literals, _ := literal.([]any)
+		s := make([]any, 0, len(val))
+		for i, elem := range val {
+			var l any
+			if i < len(literals) {
+				l = literals[i]
+			}
+			s = append(s, tomlValue(elem, l))
+		}
+		return s
+	case int64:
+		return json.Number(strconv.FormatInt(val, 10))
+	case float64:
+		if text, ok := literal.(string); ok {
+			if n, ok := floatLiteral(text); ok {
+				return n
+			}
+		}
+		// inf と nan は数値リテラルにならない
+		n, _ := AsNumber(val)
+		return n
+	case time.Time:
+		return val.Format(time.RFC3339Nano)
+	default:
+		return val
+	}
+}
+
+// quoteTOMLFloats は TOML の値の位置にある浮動小数点数（1.5、1e3、1_000.5 など）を、その表記の文字列に書き換えます。
+// キーやテーブル見出し、文字列、コメントの中は書き換えません。
+func quoteTOMLFloats(data []byte) []byte {
+	var out bytes.Buffer
+	var stack []byte // 値の中で開いている [ と {
+	expectValue := false
+	for i := 0; i < len(data); {
+		c := data[i]
+		var open byte
+		if len(stack) > 0 {
+			open = stack[len(stack)-1]
+		}
+		switch {
+		case c == '#':
+			end := bytes.IndexByte(data[i:], '\n')
+			if end < 0 {
+				end = len(data) - i
+			}
+			out.Write(data[i : i+end])
+			i += end
+			continue
+		case c == '"' || c == '\'':
+			end := tomlStringEnd(data, i)
+			out.Write(data[i:end])
+			i = end
+			expectValue = false
+			continue
+		case expectValue && c == '[':
+			stack = append(stack, c)
+		case expectValue && c == '{':
+			stack = append(stack, c)
+			expectValue = false
+		case (c == ']' && open == '[') || (c == '}' && open == '{'):
+			stack = stack[:len(stack)-1]
+			expectValue = false
+		case c == ',' && open == '[':
+			expectValue = true
+		case c == '=' && open != '[':
+			expectValue = true
+		case expectValue && !strings.ContainsRune(" \t\r\n", rune(c)):
+			end := i
+			for end < len(data) && !strings.ContainsRune(" \t\r\n,]}#", rune(data[end])) {
+				end++
+			}
+			token := string(data[i:end])
+			if _, ok := floatLiteral(token); ok && strings.ContainsAny(token, ".eE") {
+				out.WriteString(strconv.Quote(token))
+			} else {
+				out.WriteString(token)
+			}
+			i = end
+			expectValue = false
+			continue
+		}
+		out.WriteByte(c)
+		i++
+	}
+	return out.Bytes()
+}
+
+// tomlStringEnd は data[start] から始まる TOML の文字列（複数行の文字列を含む）の直後の位置を返します
+func tomlStringEnd(data []byte, start int) int {
+	quote := data[start]
+	delim := []byte{quote}
+	if bytes.HasPrefix(data[start:], []byte{quote, quote, quote}) {
+		delim = []byte{quote, quote, quote}
+	}
+	for i := start + len(delim); i < len(data); i++ {
+		switch {
+		case quote == '"' && data[i] == '\\':
+			i++
+		case bytes.HasPrefix(data[i:], delim):
+			end := i + len(delim)
+			// 複数行の文字列は閉じる引用符の直前に引用符を2つまで含められる
+			for n := 0; len(delim) == 3 && n < 2 && end < len(data) && data[end] == quote; n++ {
+				end++
+			}
+			return end
+		case len(delim) == 1 && data[i] == '\n':
+			return i
+		}
+	}
+	return len(data)
+}
+
+// tomlKeyPositions は TOML を行単位で走査し、トップレベルのキーと定義の位置を返します。
+// 定義の位置は [definitions.Name] のテーブル見出し、または [definitions] 内の "Name = ..." の行です。
+func tomlKeyPositions(path string, data []byte) (top, defs map[string]types.Position) {
+	top = make(map[string]types.Position)
+	defs = make(map[string]types.Position)
+
+	var table []string // 現在のテーブル見出しのキー
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		trimmed := strings.TrimSpace(text)
+		indent := len(text) - len(strings.TrimLeft(text, " \t"))
+		column := indent + 1
+
+		switch {
+		case trimmed == "" || strings.HasPrefix(trimmed, "#"):
+			continue
+		case strings.HasPrefix(trimmed, "["):
+			header := strings.Trim(strings.TrimSpace(strings.SplitN(trimmed, "#", 2)[0]), "[]")
+			table = splitTOMLKey(header)
+			if len(table) == 0 {
+				continue
+			}
+			if _, ok := top[table[0]]; !ok {
+				top[table[0]] = types.Position{File: path, Line: line, Column: column}
+			}
+			if len(table) >= 2 && table[0] == "definitions" {
+				if _, ok := defs[table[1]]; !ok {
+					defs[table[1]] = types.Position{File: path, Line: line, Column: column}
+				}
+			}
+		default:
+			eq := strings.Index(trimmed, "=")
+			if eq < 0 {
+				continue
+			}
+			key := splitTOMLKey(strings.TrimSpace(trimmed[:eq]))
+			if len(key) == 0 {
+				continue
+			}
+			full := append(append([]string{}, table...), key...)
+			pos := types.Position{File: path, Line: line, Column: column}
+			if len(table) == 0 {
+				if _, ok := top[full[0]]; !ok {
+					top[full[0]] = pos
+				}
+			}
+			if len(full) >= 2 && full[0] == "definitions" {
+				if _, ok := defs[full[1]]; !ok {
+					defs[full[1]] = pos
+				}
+			}
+		}
+	}
+	return top, defs
+}
+
+// splitTOMLKey はドット区切りのキー（"definitions.Name" や 'definitions."My.Name"'）を分割します
+func splitTOMLKey(key string) []string {
+	var parts []string
+	var current strings.Builder
+	var quote rune
+	for _, r := range key {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '.':
+			parts = append(parts, strings.TrimSpace(current.String()))
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if s := strings.TrimSpace(current.String()); s != "" || len(parts) > 0 {
+		parts = append(parts, s)
+	}
+	return parts
+}
+
+// tomlError は TOML のパースエラーを位置付きの Diagnostic に変換します
+func tomlError(path string, err error) error {
+	var parseErr toml.ParseError
+	if errors.As(err, &parseErr) {
+		pos := types.Position{File: path, Line: parseErr.Position.Line, Column: parseErr.Position.Col}
+		return diag.New(diag.CodeSyntax, pos, "", "%s", parseErr.Message)
+	}
+	return diag.New(diag.CodeSyntax, types.Position{File: path}, "", "%v", err)
+}
@@ -0,0 +1,183 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/nantokaworks/konst/internal/diag"
+	"github.com/nantokaworks/konst/internal/types"
+	"gopkg.in/yaml.v3"
+)
+
+// yamlErrorLine は yaml.v3 のエラーメッセージから行番号を取り出します
+var yamlErrorLine = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// decodeYAMLSchema は YAML の定義ファイルをパースします。
+// 構造は JSON と同じで、数値はYAMLに書かれた値を json.Number として保持します。
+func decodeYAMLSchema(path string, data []byte) (*types.Schema, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, yamlError(path, err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, diag.New(diag.CodeSyntax, types.Position{File: path}, "", "empty YAML document")
+	}
+
+	root := doc.Content[0]
+	value, err := yamlValue(path, root)
+	if err != nil {
+		return nil, err
+	}
+	top, defs, docs := yamlKeyPositions(path, root)
+	schema, err := schemaFromValue(path, value, top, defs)
+	if err != nil {
+		return nil, err
+	}
+	setSchemaDocs(schema, docs)
+	return schema, nil
+}
+
+// yamlValue は YAML のノードを JSON 相当の値（map[string]any, []any, json.Number など）に変換します
+func yamlValue(path string, node *yaml.Node) (any, error) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil, nil
+		}
+		return yamlValue(path, node.Content[0])
+	case yaml.AliasNode:
+		return yamlValue(path, node.Alias)
+	case yaml.MappingNode:
+		m := make(map[string]any, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, val := node.Content[i], node.Content[i+1]
+			if key.Kind != yaml.ScalarNode {
+				return nil, diag.New(diag.CodeSyntax, yamlPosition(path, key), "", "mapping key must be a scalar")
+			}
+			if key.Tag == "!!merge" {
+				// "<<: *anchor" は参照先のキーを取り込む（明示されたキーを優先）
+				merged, err := yamlValue(path, val)
+				if err != nil {
+					return nil, err
+				}
+				if mm, ok := merged.(map[string]any); ok {
+					for k, v := range mm {
+						if _, exists := m[k]; !exists {
+							m[k] = v
+						}
+					}
+				}
+				continue
+			}
+			v, err := yamlValue(path, val)
+			if err != nil {
+				return nil, err
+			}
+			m[key.Value] = v
+		}
+		return m, nil
+	case yaml.SequenceNode:
+		s := make([]any, 0, len(node.Content))
+		for _, item := range node.Content {
+			v, err := yamlValue(path, item)
+			if err != nil {
+				return nil, err
+			}
+			s = append(s, v)
+		}
+		return s, nil
+	default:
+		return yamlScalar(path, node)
+	}
+}
+
+// yamlScalar はスカラーノードをタグに応じた値に変換します。
+// 日時（!!timestamp）は書かれた文字列のまま返します。
+func yamlScalar(path string, node *yaml.Node) (any, error) {
+	switch node.ShortTag() {
+	case "!!null":
+		return nil, nil
+	case "!!bool":
+		var b bool
+		if err := node.Decode(&b); err != nil {
+			return nil, diag.New(diag.CodeSyntax, yamlPosition(path, node), "", "%v", err)
+		}
+		return b, nil
+	case "!!int":
+		// 0x1F や 1_000 などの表記も10進の整数に直す
+		n, ok := new(big.Int).SetString(node.Value, 0)
+		if !ok {
+			return nil, diag.New(diag.CodeSyntax, yamlPosition(path, node), "", "invalid integer %q", node.Value)
+		}
+		return json.Number(n.String()), nil
+	case "!!float":
+		// float64 を経由せず、書かれた桁のまま数値にする
+		n, ok := floatLiteral(node.Value)
+		if !ok {
+			return nil, diag.New(diag.CodeSyntax, yamlPosition(path, node), "", "unsupported float %q", node.Value)
+		}
+		return n, nil
+	default:
+		return node.Value, nil
+	}
+}
+
+// yamlKeyPositions はトップレベルのキーと definitions 直下のキーの位置、および定義の直前のコメントを返します
+func yamlKeyPositions(path string, root *yaml.Node) (top, defs map[string]types.Position, docs map[string]string) {
+	top = make(map[string]types.Position)
+	defs = make(map[string]types.Position)
+	docs = make(map[string]string)
+	if root.Kind != yaml.MappingNode {
+		return top, defs, docs
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, val := root.Content[i], root.Content[i+1]
+		top[key.Value] = yamlPosition(path, key)
+		if key.Value != "definitions" || val.Kind != yaml.MappingNode {
+			continue
+		}
+		for j := 0; j+1 < len(val.Content); j += 2 {
+			key := val.Content[j]
+			defs[key.Value] = yamlPosition(path, key)
+			if doc := yamlComment(key.HeadComment); doc != "" {
+				docs[key.Value] = doc
+			}
+		}
+	}
+	return top, defs, docs
+}
+
+// yamlComment は "# " で始まるコメント行から本文を取り出します
+func yamlComment(comment string) string {
+	if comment == "" {
+		return ""
+	}
+	var lines []string
+	for _, line := range strings.Split(comment, "\n") {
+		line = strings.TrimPrefix(strings.TrimSpace(line), "#")
+		lines = append(lines, strings.TrimPrefix(line, " "))
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// yamlPosition はノードの位置を返します
+func yamlPosition(path string, node *yaml.Node) types.Position {
+	return types.Position{File: path, Line: node.Line, Column: node.Column}
+}
+
+// yamlError は yaml.v3 のエラーを位置付きの Diagnostic に変換します
+func yamlError(path string, err error) error {
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		return diag.New(diag.CodeSyntax, types.Position{File: path}, "", "%s", strings.Join(typeErr.Errors, "; "))
+	}
+	if m := yamlErrorLine.FindStringSubmatch(err.Error()); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return diag.New(diag.CodeSyntax, types.Position{File: path, Line: line, Column: 1}, "", "%s", m[2])
+	}
+	return diag.New(diag.CodeSyntax, types.Position{File: path}, "", "%s", strings.TrimPrefix(err.Error(), "yaml: "))
+}
@@ -3,165 +3,267 @@ package utils
 import (
 	"errors"
 	"fmt"
-	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 
+	"github.com/nantokaworks/konst/internal/diag"
+	"github.com/nantokaworks/konst/internal/expr"
 	"github.com/nantokaworks/konst/internal/types"
 )
 
-// ResolveDependencies は定義間の依存関係を解決して値を展開します
-func ResolveDependencies(definitions map[string]types.Definition) (map[string]types.Definition, error) {
-	resolved := make(map[string]types.Definition)
-	processing := make(map[string]bool)
+// ResolveDependencies は1つの定義の集まりの定義間の依存関係を解決して値を展開します。
+// 全ての定義の問題を集めてエラー（diag.List）として返し、生成を続けられる問題は警告として返します。
+func ResolveDependencies(definitions map[string]types.Definition) (map[string]types.Definition, diag.List, error) {
+	resolution, warnings, err := ResolveScope(NewScope([]Source{{Schema: &types.Schema{Definitions: definitions}}}))
+	if err != nil {
+		return nil, warnings, err
+	}
+	return resolution.Definitions[""], warnings, nil
+}
+
+// Resolution は定義ファイルをまたいで依存関係を解決した結果です
+type Resolution struct {
+	Definitions  map[string]map[string]types.Definition // 定義ファイルのパス → 定義名 → 解決済みの定義
+	Dependencies map[Symbol][]Symbol                    // 定義 → {{}} で参照している定義（出現順、重複なし）と map の keyType の enum
+}
 
-	var resolve func(string) error
-	resolve = func(name string) error {
-		if _, ok := resolved[name]; ok {
-			return nil // すでに解決済み
+// ResolveScope は定義ファイルをまたいで定義間の依存関係を解決して値を展開します。
+// 全ての定義の問題を集めてエラー（diag.List）として返し、生成を続けられる問題は警告として返します。
+func ResolveScope(scope *Scope) (*Resolution, diag.List, error) {
+	resolved := make(map[Symbol]types.Definition)
+	dependencies := make(map[Symbol][]Symbol)
+	processing := make(map[Symbol]bool)
+	failed := make(map[Symbol]bool) // エラーを報告済み、または参照先のエラーで解決できなかった定義
+	var errs, warnings diag.List
+
+	var resolve func(Symbol) bool
+	resolve = func(sym Symbol) bool {
+		if _, ok := resolved[sym]; ok {
+			return true // すでに解決済み
 		}
-		if processing[name] {
-			return fmt.Errorf("circular dependency detected: %s", name)
+		if failed[sym] {
+			return false
 		}
-
-		def, exists := definitions[name]
-		if !exists {
-			return fmt.Errorf("undefined dependency: %s", name)
+		name := sym.Name
+		def, _ := scope.Definition(sym)
+		// fail は定義の解決の失敗を記録します。d が nil の場合は報告済みのエラー（参照先のエラーなど）による失敗です
+		fail := func(d *diag.Diagnostic) bool {
+			if d != nil {
+				errs = append(errs, d)
+			}
+			failed[sym] = true
+			return false
 		}
+		if processing[sym] {
+			return fail(diag.New(diag.CodeCircular, def.Pos, name, "circular dependency detected"))
+		}
+		processing[sym] = true
+		defer delete(processing, sym)
 
-		processing[name] = true
-
-		// 値が文字列で依存関係を含む場合、展開する
-		if strValue, ok := def.Value.(string); ok {
-			expandedValue, err := expandDependencies(strValue, definitions, resolve)
+		// 値が {{式}} を含む場合、参照先を解決してから評価する
+		if strValue, ok := def.Value.(string); ok && HasReference(strValue) {
+			refs, err := valueReferences(strValue, def.Type)
 			if err != nil {
-				return err
+				return fail(diag.New(diag.CodeInvalidExpression, def.Pos, name, "%v", err))
+			}
+			targets := make(map[string]Symbol) // 参照 → 参照先
+			undefined := false
+			for _, ref := range refs {
+				target, err := scope.Lookup(sym.File, ref.Ident.Name)
+				if err != nil {
+					errs = append(errs, referenceDiagnostic(def, name, err))
+					undefined = true
+					continue
+				}
+				if _, seen := targets[ref.Ident.Name]; !seen {
+					dependencies[sym] = append(dependencies[sym], target)
+				}
+				targets[ref.Ident.Name] = target
+				if dep, _ := scope.Definition(target); ref.Embedded && !isScalarType(dep.Type) {
+					warnings = append(warnings, diag.NewWarning(diag.CodeNonScalarReference, def.Pos, name,
+						"{{%s}} embeds the %s definition %s into the value as text", ref.Ident.Name, dep.Type, ref.Ident.Name))
+				}
+			}
+			if undefined {
+				return fail(nil)
+			}
+			for _, ref := range refs {
+				if !resolve(targets[ref.Ident.Name]) {
+					return fail(nil)
+				}
 			}
 
-			// 展開された値を適切な型に変換
-			convertedValue, err := convertToTargetType(expandedValue, def.Type)
+			lookup := func(ref string) (types.Definition, bool) {
+				target, ok := targets[ref]
+				if !ok {
+					return types.Definition{}, false
+				}
+				return scope.Definition(target)
+			}
+			value, err := evaluateValue(strValue, def.Type, lookup, func(ident *expr.Ident) (expr.Value, error) {
+				v, err := DefinitionValue(ident.Name, resolved[targets[ident.Name]])
+				if err != nil {
+					return expr.Value{}, &expr.Error{Offset: ident.Offset, Msg: err.Error()}
+				}
+				return v, nil
+			})
 			if err != nil {
-				return err
+				return fail(diag.New(diag.CodeInvalidExpression, def.Pos, name, "%v", err))
 			}
+			def.Value = value
+			def.Expression = strValue
+		}
 
-			def.Value = convertedValue
+		// 値が宣言された型で表現できない場合はエラー
+		if err := checkDefinitionNumbers(def); err != nil {
+			return fail(diag.New(diag.CodeValueType, def.Pos, name, "%v", err))
 		}
 
-		resolved[name] = def
-		delete(processing, name)
-		return nil
+		// map のキーが enum の場合は enum の全メンバーに対応しているかを確認する
+		if def.Type == types.DefinitionTypeMap {
+			enumSym, err := resolveMapKeyEnum(&def, scope, sym.File)
+			if err != nil {
+				return fail(diag.New(diag.CodeInvalidMap, def.Pos, name, "%v", err))
+			}
+			// キーの enum が変わると生成コードも変わるため依存関係に含める
+			if def.KeyEnum != nil && !slices.Contains(dependencies[sym], enumSym) {
+				dependencies[sym] = append(dependencies[sym], enumSym)
+			}
+		}
+
+		resolved[sym] = def
+		return true
 	}
 
-	// すべての定義を解決
-	for name := range definitions {
-		if err := resolve(name); err != nil {
-			return nil, err
+	// 定義ファイルの読み込み順、定義名の順に解決（エラーの報告順を一定にするため）
+	for _, src := range scope.Sources() {
+		for _, name := range sortedNames(src.Schema.Definitions) {
+			resolve(Symbol{File: src.Path, Name: name})
 		}
 	}
+	warnings.Sort()
+	if len(errs) > 0 {
+		errs.Sort()
+		return nil, warnings, errs
+	}
 
-	return resolved, nil
+	files := make(map[string]map[string]types.Definition)
+	for _, src := range scope.Sources() {
+		files[src.Path] = make(map[string]types.Definition)
+	}
+	for sym, def := range resolved {
+		files[sym.File][sym.Name] = def
+	}
+	return &Resolution{Definitions: files, Dependencies: dependencies}, warnings, nil
 }
 
-// expandDependencies は文字列内の依存関係を展開します
-func expandDependencies(value string, definitions map[string]types.Definition, resolve func(string) error) (string, error) {
-	re := regexp.MustCompile(`\{\{([^}]+)\}\}`)
-	
-	result := re.ReplaceAllStringFunc(value, func(match string) string {
-		// {{name}} から name を抽出
-		depName := strings.TrimSpace(match[2 : len(match)-2])
-		
-		// 依存関係を再帰的に解決
-		if err := resolve(depName); err != nil {
-			return match // エラーの場合は元の値を返す
-		}
-		
-		// 依存する定義の値を取得
-		if dep, exists := definitions[depName]; exists {
-			return fmt.Sprintf("%v", dep.Value)
-		}
-		
-		return match // 見つからない場合は元の値を返す
-	})
-	
-	return result, nil
+// referenceDiagnostic は参照先を決められない参照を含む定義 name の診断を作成します
+func referenceDiagnostic(def types.Definition, name string, err error) *diag.Diagnostic {
+	if e, ok := err.(*referenceError); ok {
+		return diag.New(e.code, def.Pos, name, "%s", e.msg)
+	}
+	return diag.New(diag.CodeUndefinedReference, def.Pos, name, "%v", err)
 }
 
-// convertToTargetType は文字列を指定された型に変換します
-func convertToTargetType(value string, targetType types.DefinitionType) (interface{}, error) {
-	switch targetType {
-	case types.DefinitionTypeInt, types.DefinitionTypeInt32, types.DefinitionTypeInt64:
-		// 数式の評価（簡単な計算のみサポート）
-		result, err := evaluateSimpleExpression(value)
-		if err != nil {
-			return value, nil // 計算できない場合は文字列のまま
+// resolveMapKeyEnum は keyType が enum の定義名の map について、キーが enum のメンバーと過不足なく対応しているかを検証し、
+// def.KeyEnum に enum の定義を設定して enum の定義を返します。keyType が組み込みの型の場合は何もしません。
+func resolveMapKeyEnum(def *types.Definition, scope *Scope, file string) (Symbol, error) {
+	if def.KeyType == types.DefinitionTypeString || IsIntegerType(def.KeyType) {
+		return Symbol{}, nil
+	}
+	sym, err := scope.Lookup(file, string(def.KeyType))
+	if e, ok := err.(*referenceError); ok && e.code == diag.CodeAmbiguousReference {
+		return Symbol{}, fmt.Errorf("keyType %q: %v", def.KeyType, err)
+	}
+	enum, ok := scope.Definition(sym)
+	if err != nil || !ok {
+		return Symbol{}, fmt.Errorf("keyType %q is neither a built-in type nor a defined enum", def.KeyType)
+	}
+	if enum.Type != types.DefinitionTypeEnum {
+		return Symbol{}, fmt.Errorf("keyType %q must be an enum definition, but it is %q", def.KeyType, enum.Type)
+	}
+
+	entries, _ := def.Value.(map[string]interface{})
+	members := make(map[string]bool)
+	var missing []string
+	for _, v := range enum.Values {
+		members[v.Name] = true
+		if _, ok := entries[v.Name]; !ok {
+			missing = append(missing, strconv.Quote(v.Name))
 		}
-		return result, nil
-	case types.DefinitionTypeFloat, types.DefinitionTypeFloat32, types.DefinitionTypeFloat64:
-		result, err := evaluateSimpleExpression(value)
-		if err != nil {
-			return value, nil
+	}
+	var unknown []string
+	for key := range entries {
+		if !members[key] {
+			unknown = append(unknown, strconv.Quote(key))
 		}
-		return float64(result), nil
-	case types.DefinitionTypeString:
-		return value, nil
-	default:
-		return value, nil
 	}
+	sort.Strings(unknown)
+
+	var problems []string
+	if len(unknown) > 0 {
+		problems = append(problems, fmt.Sprintf("keys %s are not members of enum %s", strings.Join(unknown, ", "), def.KeyType))
+	}
+	if len(missing) > 0 {
+		problems = append(problems, fmt.Sprintf("enum %s members %s are missing from the map", def.KeyType, strings.Join(missing, ", ")))
+	}
+	if len(problems) > 0 {
+		return Symbol{}, errors.New(strings.Join(problems, "; "))
+	}
+
+	def.KeyEnum = &enum
+	return sym, nil
 }
 
-// evaluateSimpleExpression は簡単な数式を評価します（+ - * / のみサポート）
-func evaluateSimpleExpression(expr string) (int, error) {
-	expr = strings.ReplaceAll(expr, " ", "")
-	
-	// 乗算と除算を先に処理
-	for strings.Contains(expr, "*") || strings.Contains(expr, "/") {
-		re := regexp.MustCompile(`(\d+)\s*([*/])\s*(\d+)`)
-		match := re.FindStringSubmatch(expr)
-		if len(match) != 4 {
-			break
-		}
-		
-		left, _ := strconv.Atoi(match[1])
-		op := match[2]
-		right, _ := strconv.Atoi(match[3])
-		
-		var result int
-		switch op {
-		case "*":
-			result = left * right
-		case "/":
-			if right == 0 {
-				return 0, errors.New("division by zero")
+// checkDefinitionNumbers は数値型（および数値型の配列、object のフィールド、table の各行）の値が宣言された型に収まるかを検証します
+func checkDefinitionNumbers(def types.Definition) error {
+	if def.Type == types.DefinitionTypeTable {
+		for i := range def.Rows {
+			if err := checkDefinitionNumbers(def.RowDefinition(i)); err != nil {
+				return fmt.Errorf("row %d: %w", i, err)
 			}
-			result = left / right
 		}
-		
-		expr = strings.Replace(expr, match[0], strconv.Itoa(result), 1)
-	}
-	
-	// 加算と減算を処理
-	for strings.Contains(expr, "+") || strings.Contains(expr, "-") {
-		re := regexp.MustCompile(`(\d+)\s*([+-])\s*(\d+)`)
-		match := re.FindStringSubmatch(expr)
-		if len(match) != 4 {
-			break
+		return nil
+	}
+	if def.Type == types.DefinitionTypeObject {
+		obj, _ := def.Value.(map[string]interface{})
+		for _, f := range def.Fields {
+			value, ok := obj[f.Name]
+			if !ok {
+				continue // 値のないフィールドはバリデーターが報告する
+			}
+			if err := checkDefinitionNumbers(f.Definition(def, "", value)); err != nil {
+				return fmt.Errorf("field %q: %w", f.Name, err)
+			}
 		}
-		
-		left, _ := strconv.Atoi(match[1])
-		op := match[2]
-		right, _ := strconv.Atoi(match[3])
-		
-		var result int
-		switch op {
-		case "+":
-			result = left + right
-		case "-":
-			result = left - right
+		return nil
+	}
+	baseType := types.DefinitionType(strings.TrimSuffix(string(def.Type), "[]"))
+	if !IsIntegerType(baseType) && !isFloatType(baseType) {
+		return nil
+	}
+	if baseType == def.Type {
+		return checkNumberValue(baseType, def.Value)
+	}
+	elems, ok := def.Value.([]interface{})
+	if !ok {
+		return nil
+	}
+	for i, elem := range elems {
+		if err := checkNumberValue(baseType, elem); err != nil {
+			return fmt.Errorf("element %d: %w", i, err)
 		}
-		
-		expr = strings.Replace(expr, match[0], strconv.Itoa(result), 1)
 	}
-	
-	// 最終的に数値のみが残っているかチェック
-	return strconv.Atoi(expr)
-}
\ No newline at end of file
+	return nil
+}
+
+// checkNumberValue は1つの値が数値型 t の値として出力できるかを検証します
+func checkNumberValue(t types.DefinitionType, value interface{}) error {
+	n, ok := AsNumber(value)
+	if !ok {
+		return fmt.Errorf("value %v cannot be used as type %q", value, t)
+	}
+	return CheckNumber(t, n)
+}
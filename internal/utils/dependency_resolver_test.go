@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nantokaworks/konst/internal/diag"
+	"github.com/nantokaworks/konst/internal/types"
+)
+
+func TestResolveDependenciesChecksDeclaredType(t *testing.T) {
+	tests := []struct {
+		name     string
+		def      types.Definition
+		expected string
+	}{
+		{"Int32 overflow", types.Definition{Type: types.DefinitionTypeInt32, Value: "{{Base}} * 1000"}, "out of range"},
+		{"Float32 precision loss", types.Definition{Type: types.DefinitionTypeFloat32, Value: json.Number("16777217")}, "loses precision"},
+		{"Negative unsigned element", types.Definition{Type: "uint[]", Value: []any{json.Number("1"), json.Number("-1")}}, "element 1"},
+		{"Not a number", types.Definition{Type: types.DefinitionTypeInt, Value: "three"}, `cannot be used as type "int"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defs := map[string]types.Definition{
+				"Base":  {Type: types.DefinitionTypeInt, Value: json.Number("3000000")},
+				"Value": tt.def,
+			}
+			_, _, err := ResolveDependencies(defs)
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Fatalf("Expected error containing %q, got %v", tt.expected, err)
+			}
+			var d *diag.Diagnostic
+			if !errors.As(err, &d) || d.Code != diag.CodeValueType || d.Name != "Value" {
+				t.Errorf("Expected value-type diagnostic for Value, got %#v", err)
+			}
+		})
+	}
+}
+
+func TestResolveDependenciesDuration(t *testing.T) {
+	defs := map[string]types.Definition{
+		"BaseTimeout": {Type: types.DefinitionTypeDuration, Value: "30s"},
+		"LongTimeout": {Type: types.DefinitionTypeDuration, Value: "{{BaseTimeout}} * 2"},
+		"PollTimeout": {Type: types.DefinitionTypeDuration, Value: "{{LongTimeout}} + 500ms"},
+	}
+	resolved, _, err := ResolveDependencies(defs)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if v := resolved["LongTimeout"].Value; v != "1m0s" {
+		t.Errorf("LongTimeout = %v, expected 1m0s", v)
+	}
+	if v := resolved["PollTimeout"].Value; v != "1m0.5s" {
+		t.Errorf("PollTimeout = %v, expected 1m0.5s", v)
+	}
+
+	defs["Broken"] = types.Definition{Type: types.DefinitionTypeDuration, Value: "{{BaseTimeout}} * {{BaseTimeout}}x"}
+	_, _, err = ResolveDependencies(defs)
+	var d *diag.Diagnostic
+	if !errors.As(err, &d) || d.Code != diag.CodeInvalidExpression || d.Name != "Broken" {
+		t.Errorf("Expected invalid-expression diagnostic for Broken, got %#v", err)
+	}
+}
+
+func TestResolveDependenciesMapEnumKeys(t *testing.T) {
+	status := types.Definition{Type: types.DefinitionTypeEnum, Values: types.StringEnumValues("active", "inactive", "pending")}
+	tests := []struct {
+		name     string
+		value    map[string]any
+		keyType  types.DefinitionType
+		expected string
+	}{
+		{"Complete", map[string]any{"active": "A", "inactive": "I", "pending": "P"}, "Status", ""},
+		{"Missing members", map[string]any{"active": "A"}, "Status", `enum Status members "inactive", "pending" are missing from the map`},
+		{"Unknown key", map[string]any{"active": "A", "inactive": "I", "pending": "P", "deleted": "D"}, "Status", `keys "deleted" are not members of enum Status`},
+		{"Not an enum", map[string]any{}, "Base", `keyType "Base" must be an enum definition`},
+		{"Undefined", map[string]any{}, "Missing", `keyType "Missing" is neither a built-in type nor a defined enum`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defs := map[string]types.Definition{
+				"Base":   {Type: types.DefinitionTypeInt, Value: json.Number("1")},
+				"Status": status,
+				"Labels": {Type: types.DefinitionTypeMap, KeyType: tt.keyType, ValueType: types.DefinitionTypeString, Value: tt.value},
+			}
+			resolved, _, err := ResolveDependencies(defs)
+			if tt.expected == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if resolved["Labels"].KeyEnum == nil || len(resolved["Labels"].KeyEnum.Values) != 3 {
+					t.Errorf("Expected KeyEnum to be set, got %+v", resolved["Labels"].KeyEnum)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Fatalf("Expected error containing %q, got %v", tt.expected, err)
+			}
+			var d *diag.Diagnostic
+			if !errors.As(err, &d) || d.Code != diag.CodeInvalidMap || d.Name != "Labels" {
+				t.Errorf("Expected invalid-map diagnostic for Labels, got %#v", err)
+			}
+		})
+	}
+}
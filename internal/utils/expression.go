@@ -0,0 +1,249 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/nantokaworks/konst/internal/expr"
+	"github.com/nantokaworks/konst/internal/types"
+)
+
+// referencePattern は値の中の {{式}} にマッチします
+var referencePattern = regexp.MustCompile(`\{\{([^}]+)\}\}`)
+
+// HasReference は値が {{式}} を含むかどうかを返します
+func HasReference(value string) bool {
+	return referencePattern.MatchString(value)
+}
+
+// isExpressionType は値全体を1つの式として評価する型（数値型・bool・duration）かどうかを返します。
+// それ以外の型（string、date など）では {{式}} の部分だけを評価して文字列に埋め込みます。
+func isExpressionType(t types.DefinitionType) bool {
+	return IsIntegerType(t) || isFloatType(t) || t == types.DefinitionTypeBool || t == types.DefinitionTypeDuration
+}
+
+// isScalarType は式の値として使えるスカラー型かどうかを返します
+func isScalarType(t types.DefinitionType) bool {
+	switch t {
+	case types.DefinitionTypeString, types.DefinitionTypeBool, types.DefinitionTypeDate,
+		types.DefinitionTypeTimestamp, types.DefinitionTypeDuration:
+		return true
+	}
+	return IsIntegerType(t) || isFloatType(t)
+}
+
+// CheckExpression は {{式}} を含む値の構文を検証します。参照先の解決や評価は行いません。
+func CheckExpression(value string, t types.DefinitionType) error {
+	_, err := valueReferences(value, t)
+	return err
+}
+
+// reference は値の中の定義名の参照です
+type reference struct {
+	Ident    *expr.Ident
+	Embedded bool // {{Name}} だけで文字列に埋め込まれている（string・date などの値の {{Name}}）
+}
+
+// valueReferences は {{式}} を含む値を解析し、定義名の参照を出現順に返します
+func valueReferences(value string, t types.DefinitionType) ([]reference, error) {
+	var refs []reference
+	if isExpressionType(t) {
+		node, err := parseValueExpression(value)
+		if err != nil {
+			return nil, err
+		}
+		for _, ident := range expr.Idents(node) {
+			refs = append(refs, reference{Ident: ident})
+		}
+		return refs, nil
+	}
+	for _, m := range referencePattern.FindAllStringSubmatchIndex(value, -1) {
+		node, err := expr.Parse(value[m[2]:m[3]])
+		if err != nil {
+			return nil, expressionError(value, m[2], err)
+		}
+		_, embedded := node.(*expr.Ident)
+		for _, ident := range expr.Idents(node) {
+			// 位置を値の中の位置にする
+			refs = append(refs, reference{Ident: &expr.Ident{Offset: m[2] + ident.Offset, Name: ident.Name}, Embedded: embedded})
+		}
+	}
+	return refs, nil
+}
+
+// ExpressionPart は {{式}} を含む値の一部です。Node が nil の部分は Text をそのまま使います。
+type ExpressionPart struct {
+	Text string
+	Node expr.Node
+}
+
+// ExpressionParts は {{式}} を含む値を解析します。
+// 数値型・bool・duration では値全体を1つの式として返し、それ以外の型では文字列の部分と {{式}} の部分を出現順に返します。
+func ExpressionParts(value string, t types.DefinitionType) ([]ExpressionPart, error) {
+	if isExpressionType(t) {
+		node, err := parseValueExpression(value)
+		if err != nil {
+			return nil, err
+		}
+		return []ExpressionPart{{Node: node}}, nil
+	}
+	var parts []ExpressionPart
+	last := 0
+	for _, m := range referencePattern.FindAllStringSubmatchIndex(value, -1) {
+		if m[0] > last {
+			parts = append(parts, ExpressionPart{Text: value[last:m[0]]})
+		}
+		last = m[1]
+		node, err := expr.Parse(value[m[2]:m[3]])
+		if err != nil {
+			return nil, expressionError(value, m[2], err)
+		}
+		parts = append(parts, ExpressionPart{Node: node})
+	}
+	if last < len(value) {
+		parts = append(parts, ExpressionPart{Text: value[last:]})
+	}
+	return parts, nil
+}
+
+// parseValueExpression は数値型などの値全体を1つの式として解析します。
+// {{式}} は括弧で囲んだ式として扱い、定義名の参照は {{}} の中だけに書けます。
+func parseValueExpression(value string) (expr.Node, error) {
+	// {{ と }} を同じ長さの " (" と ") " に置き換え、エラーの位置が元の値と一致するようにする
+	matches := referencePattern.FindAllStringIndex(value, -1)
+	src := []byte(value)
+	for _, m := range matches {
+		copy(src[m[0]:], " (")
+		copy(src[m[1]-2:], ") ")
+	}
+	node, err := expr.Parse(string(src))
+	if err != nil {
+		return nil, expressionError(value, 0, err)
+	}
+	for _, ident := range expr.Idents(node) {
+		inside := false
+		for _, m := range matches {
+			if m[0] < ident.Offset && ident.Offset < m[1] {
+				inside = true
+			}
+		}
+		if !inside {
+			return nil, fmt.Errorf("invalid expression %q: reference %q must be written as {{%s}}", value, ident.Name, ident.Name)
+		}
+	}
+	return node, nil
+}
+
+// expressionError は式のエラーに元の値と値の中での位置を付けます
+func expressionError(value string, offset int, err error) error {
+	if e, ok := err.(*expr.Error); ok {
+		return fmt.Errorf("invalid expression %q: column %d: %s", value, offset+e.Offset+1, e.Msg)
+	}
+	return fmt.Errorf("invalid expression %q: %w", value, err)
+}
+
+// evaluateValue は {{式}} を含む値を評価し、宣言された型 t の値にします。
+// 数値型・bool・duration では値全体を式として評価し、それ以外の型では {{式}} を評価結果の文字列に置き換えます。
+// 文字列に埋め込む {{Name}} の参照先（lookup で取得）が配列などスカラーでない定義の場合は、値をそのまま埋め込みます。
+func evaluateValue(value string, t types.DefinitionType, lookup func(ref string) (types.Definition, bool), env expr.Env) (interface{}, error) {
+	if isExpressionType(t) {
+		node, err := parseValueExpression(value)
+		if err != nil {
+			return nil, err
+		}
+		v, err := expr.Eval(node, env)
+		if err != nil {
+			return nil, expressionError(value, 0, err)
+		}
+		return convertExpressionValue(v, t)
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, m := range referencePattern.FindAllStringSubmatchIndex(value, -1) {
+		b.WriteString(value[last:m[0]])
+		last = m[1]
+		inner := value[m[2]:m[3]]
+		node, err := expr.Parse(inner)
+		if err != nil {
+			return nil, expressionError(value, m[2], err)
+		}
+		if ident, ok := node.(*expr.Ident); ok {
+			if dep, exists := lookup(ident.Name); exists && !isScalarType(dep.Type) {
+				// 配列や object などは Go の %v の表記のまま埋め込む（依存関係の解決時に警告する）
+				b.WriteString(fmt.Sprintf("%v", dep.Value))
+				continue
+			}
+		}
+		v, err := expr.Eval(node, env)
+		if err != nil {
+			return nil, expressionError(value, m[2], err)
+		}
+		b.WriteString(v.String())
+	}
+	b.WriteString(value[last:])
+	return b.String(), nil
+}
+
+// DefinitionValue は解決済みの定義の値を式の値にします
+func DefinitionValue(name string, def types.Definition) (expr.Value, error) {
+	switch {
+	case IsIntegerType(def.Type), isFloatType(def.Type):
+		n, ok := AsNumber(def.Value)
+		if !ok {
+			return expr.Value{}, fmt.Errorf("value of %q is not a number", name)
+		}
+		r, ok := NumberRat(n)
+		if !ok {
+			return expr.Value{}, fmt.Errorf("value of %q is not a number", name)
+		}
+		if IsIntegerType(def.Type) {
+			if !r.IsInt() {
+				return expr.Value{}, fmt.Errorf("value of %q is not an integer", name)
+			}
+			return expr.IntValue(r.Num()), nil
+		}
+		return expr.FloatValue(r), nil
+	case def.Type == types.DefinitionTypeBool:
+		if b, ok := def.Value.(bool); ok {
+			return expr.BoolValue(b), nil
+		}
+	case def.Type == types.DefinitionTypeDuration:
+		if s, ok := def.Value.(string); ok {
+			if d, err := time.ParseDuration(s); err == nil {
+				return expr.DurationValue(d), nil
+			}
+		}
+	case def.Type == types.DefinitionTypeString, def.Type == types.DefinitionTypeDate, def.Type == types.DefinitionTypeTimestamp:
+		if s, ok := def.Value.(string); ok {
+			return expr.StringValue(s), nil
+		}
+	default:
+		return expr.Value{}, fmt.Errorf("%q is a %s definition and cannot be used in an expression", name, def.Type)
+	}
+	return expr.Value{}, fmt.Errorf("value of %q cannot be used as type %q", name, def.Type)
+}
+
+// convertExpressionValue は式の評価結果を宣言された型 t の値（定義ファイルの値と同じ表現）にします
+func convertExpressionValue(v expr.Value, t types.DefinitionType) (interface{}, error) {
+	switch {
+	case IsIntegerType(t) && v.Kind == expr.KindInt:
+		return json.Number(v.Int.String()), nil
+	case isFloatType(t) && v.Kind == expr.KindInt:
+		return json.Number(v.Int.String()), nil
+	case isFloatType(t) && v.Kind == expr.KindFloat:
+		bitSize := 64
+		if t != types.DefinitionTypeFloat64 {
+			bitSize = 32
+		}
+		return json.Number(v.FormatFloat(bitSize)), nil
+	case t == types.DefinitionTypeBool && v.Kind == expr.KindBool:
+		return v.Bool, nil
+	case t == types.DefinitionTypeDuration && v.Kind == expr.KindDuration:
+		return v.Duration.String(), nil
+	}
+	return nil, fmt.Errorf("expression of type %s cannot be used as type %q", v.Kind, t)
+}
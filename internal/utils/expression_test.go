@@ -0,0 +1,177 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nantokaworks/konst/internal/diag"
+	"github.com/nantokaworks/konst/internal/types"
+)
+
+// expressionBase は式のテストで参照する定義です
+var expressionBase = map[string]types.Definition{
+	"BaseRetries": {Type: types.DefinitionTypeInt, Value: json.Number("3")},
+	"Ratio":       {Type: types.DefinitionTypeFloat64, Value: json.Number("1.5")},
+	"Env":         {Type: types.DefinitionTypeString, Value: "prod"},
+	"Debug":       {Type: types.DefinitionTypeBool, Value: false},
+	"Timeout":     {Type: types.DefinitionTypeDuration, Value: "30s"},
+	"Ports":       {Type: "int[]", Value: []any{json.Number("80"), json.Number("443")}},
+}
+
+func TestResolveDependenciesExpressions(t *testing.T) {
+	tests := []struct {
+		name     string
+		def      types.Definition
+		expected any
+	}{
+		{"Integer", types.Definition{Type: types.DefinitionTypeInt, Value: "({{BaseRetries}} + 1) * -2"}, json.Number("-8")},
+		{"Expression inside braces", types.Definition{Type: types.DefinitionTypeInt, Value: "{{BaseRetries * 2}} % 4"}, json.Number("2")},
+		{"Shift", types.Definition{Type: types.DefinitionTypeUint64, Value: "1 << {{BaseRetries}}"}, json.Number("8")},
+		{"Float", types.Definition{Type: types.DefinitionTypeFloat64, Value: "{{Ratio}} / 3"}, json.Number("0.5")},
+		{"Float32 rounding", types.Definition{Type: types.DefinitionTypeFloat32, Value: "{{BaseRetries}} / 7.0"}, json.Number("0.42857143")},
+		{"Integer into float", types.Definition{Type: types.DefinitionTypeFloat64, Value: "{{BaseRetries}} * 2"}, json.Number("6")},
+		{"Functions", types.Definition{Type: types.DefinitionTypeInt, Value: "max({{BaseRetries}}, len({{Env}}))"}, json.Number("4")},
+		{"Comparison", types.Definition{Type: types.DefinitionTypeBool, Value: "{{Env}} == \"prod\" && !{{Debug}}"}, true},
+		{"Duration", types.Definition{Type: types.DefinitionTypeDuration, Value: "min({{Timeout}} * {{BaseRetries}}, 1m)"}, "1m0s"},
+		{"String interpolation", types.Definition{Type: types.DefinitionTypeString, Value: "{{upper(Env)}}-{{BaseRetries + 1}}-{{Ratio}}"}, "PROD-4-1.5"},
+		{"Array in string", types.Definition{Type: types.DefinitionTypeString, Value: "ports: {{Ports}}"}, "ports: [80 443]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defs := map[string]types.Definition{"Value": tt.def}
+			for name, def := range expressionBase {
+				defs[name] = def
+			}
+			resolved, _, err := ResolveDependencies(defs)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if v := resolved["Value"].Value; v != tt.expected {
+				t.Errorf("Value = %#v, expected %#v", v, tt.expected)
+			}
+		})
+	}
+}
+
+func TestResolveDependenciesExpressionErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		def      types.Definition
+		expected string
+	}{
+		{"Float into integer", types.Definition{Type: types.DefinitionTypeInt, Value: "{{Ratio}} * 2"}, `expression of type float cannot be used as type "int"`},
+		{"String into integer", types.Definition{Type: types.DefinitionTypeInt, Value: "{{Env}}"}, `expression of type string cannot be used as type "int"`},
+		{"Reference outside braces", types.Definition{Type: types.DefinitionTypeInt, Value: "{{BaseRetries}} * Factor"}, `reference "Factor" must be written as {{Factor}}`},
+		{"Syntax error", types.Definition{Type: types.DefinitionTypeInt, Value: "{{BaseRetries}} ** 2"}, `column 18: unexpected "*"`},
+		{"Division by zero", types.Definition{Type: types.DefinitionTypeInt, Value: "{{BaseRetries}} / ({{BaseRetries}} - 3)"}, "division by zero"},
+		{"Non-scalar in expression", types.Definition{Type: types.DefinitionTypeInt, Value: "{{Ports}} + 1"}, `"Ports" is a int[] definition and cannot be used in an expression`},
+		{"Bad expression in string", types.Definition{Type: types.DefinitionTypeString, Value: "v{{BaseRetries +}}"}, "column 17: unexpected end of expression"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defs := map[string]types.Definition{"Value": tt.def}
+			for name, def := range expressionBase {
+				defs[name] = def
+			}
+			_, _, err := ResolveDependencies(defs)
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Fatalf("Expected error containing %q, got %v", tt.expected, err)
+			}
+			var d *diag.Diagnostic
+			if !errors.As(err, &d) || d.Code != diag.CodeInvalidExpression || d.Name != "Value" {
+				t.Errorf("Expected invalid-expression diagnostic for Value, got %#v", err)
+			}
+		})
+	}
+}
+
+func TestResolveDependenciesUndefinedReferences(t *testing.T) {
+	defs := map[string]types.Definition{
+		"MaxRetries": {Type: types.DefinitionTypeInt, Value: json.Number("3")},
+		"Greeting":   {Type: types.DefinitionTypeString, Value: "Hello {{name}}"},
+		"Limit":      {Type: types.DefinitionTypeInt, Value: "{{MaxRetires}} * 2"},
+		"Total":      {Type: types.DefinitionTypeInt, Value: "{{Limit}} + {{Missing}}"},
+		"Derived":    {Type: types.DefinitionTypeInt, Value: "{{Limit}} + 1"},
+	}
+	_, _, err := ResolveDependencies(defs)
+	var list diag.List
+	if !errors.As(err, &list) {
+		t.Fatalf("Expected diagnostics, got %#v", err)
+	}
+
+	// 参照先のエラーで解決できない Derived は報告しない
+	expected := []string{
+		"Greeting: undefined reference {{name}}",
+		"Limit: undefined reference {{MaxRetires}} (did you mean {{MaxRetries}}?)",
+		"Total: undefined reference {{Missing}}",
+	}
+	if len(list) != len(expected) {
+		t.Fatalf("Expected %d diagnostics, got %d: %v", len(expected), len(list), list)
+	}
+	for i, d := range list {
+		if d.Code != diag.CodeUndefinedReference || d.Error() != expected[i] {
+			t.Errorf("diagnostic %d = %s (%s), expected %s", i, d.Error(), d.Code, expected[i])
+		}
+	}
+}
+
+func TestResolveDependenciesNonScalarWarning(t *testing.T) {
+	defs := map[string]types.Definition{
+		"Ports":   {Type: "int[]", Value: []any{json.Number("80"), json.Number("443")}},
+		"Summary": {Type: types.DefinitionTypeString, Value: "ports: {{Ports}}"},
+	}
+	resolved, warnings, err := ResolveDependencies(defs)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if v := resolved["Summary"].Value; v != "ports: [80 443]" {
+		t.Errorf("Summary = %#v, expected %#v", v, "ports: [80 443]")
+	}
+	if len(warnings) != 1 || warnings[0].Code != diag.CodeNonScalarReference ||
+		warnings[0].Severity != diag.SeverityWarning || warnings[0].Name != "Summary" {
+		t.Errorf("Expected one non-scalar-reference warning for Summary, got %v", warnings)
+	}
+}
+
+func TestResolveDependenciesReportsReferencedDefinition(t *testing.T) {
+	defs := map[string]types.Definition{
+		"A": {Type: types.DefinitionTypeInt, Value: "{{B}} + 1"},
+		"B": {Type: types.DefinitionTypeInt, Value: "{{A}} + 1"},
+	}
+	_, _, err := ResolveDependencies(defs)
+	var d *diag.Diagnostic
+	if !errors.As(err, &d) || d.Code != diag.CodeCircular {
+		t.Errorf("Expected circular-dependency diagnostic, got %#v", err)
+	}
+}
+
+func TestCheckExpression(t *testing.T) {
+	tests := []struct {
+		value    string
+		defType  types.DefinitionType
+		expected string // 空ならエラーなし、それ以外はエラーメッセージの断片
+	}{
+		{"{{Base}} * 2", types.DefinitionTypeInt, ""},
+		{"{{Host}}:{{Port}}", types.DefinitionTypeString, ""},
+		{"{{Base}} *", types.DefinitionTypeInt, "column 11: unexpected end of expression"},
+		{"{{Host}}:{{Port +}}", types.DefinitionTypeString, "column 18: unexpected end of expression"},
+		{"{{Base}} * Other", types.DefinitionTypeInt, `reference "Other" must be written as {{Other}}`},
+	}
+
+	for _, tt := range tests {
+		err := CheckExpression(tt.value, tt.defType)
+		if tt.expected == "" {
+			if err != nil {
+				t.Errorf("CheckExpression(%q) unexpected error: %v", tt.value, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("CheckExpression(%q) error = %v, expected %q", tt.value, err, tt.expected)
+		}
+	}
+}
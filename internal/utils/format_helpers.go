@@ -3,6 +3,7 @@ package utils
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/nantokaworks/konst/internal/types"
 )
@@ -24,19 +25,82 @@ func MonthConst(m int) string {
 	return months[m]
 }
 
-// HasDate は定義の中に日付型があるかチェックします
+// HasDate は定義の中に time パッケージを使う型（time.Time として出力される日付型、time.Duration の期間型）があるかチェックします。
+// goMode が string や int64 の日付は time パッケージを使わないため対象外です。
 func HasDate(defs map[string]types.Definition) bool {
 	if defs == nil {
 		return false
 	}
 	for _, def := range defs {
-		if def.Type == "date" {
+		if usesTime(def.Type, def.GoMode) || usesTime(def.ValueType, def.GoMode) || fieldsHaveDate(def.Fields) || fieldsHaveDate(def.Columns) {
 			return true
 		}
 	}
 	return false
 }
 
+// fieldsHaveDate は object のフィールド・table の列（入れ子を含む）に time パッケージを使う型があるかチェックします
+func fieldsHaveDate(fields []types.Field) bool {
+	for _, f := range fields {
+		if usesTime(f.Type, f.GoMode) || fieldsHaveDate(f.Fields) {
+			return true
+		}
+	}
+	return false
+}
+
+// usesTime は time.Time として出力される日付型・time.Duration として出力される期間型（またはその配列）かどうかを返します
+func usesTime(t types.DefinitionType, mode types.GoMode) bool {
+	if t == types.DefinitionTypeDuration || t == "duration[]" {
+		return true
+	}
+	if t != types.DefinitionTypeDate && t != "date[]" {
+		return false
+	}
+	return mode != types.GoModeString && mode != types.GoModeInt64
+}
+
+// goDurationUnits は期間を Go の式にするときに使う time パッケージの単位（大きい順）です
+var goDurationUnits = []struct {
+	unit time.Duration
+	name string
+}{
+	{time.Hour, "time.Hour"},
+	{time.Minute, "time.Minute"},
+	{time.Second, "time.Second"},
+	{time.Millisecond, "time.Millisecond"},
+	{time.Microsecond, "time.Microsecond"},
+	{time.Nanosecond, "time.Nanosecond"},
+}
+
+// GoDuration は期間を time パッケージの単位を使った Go の式（30 * time.Second、1*time.Hour + 30*time.Minute など）にします
+func GoDuration(d time.Duration) string {
+	if d == 0 {
+		return "0"
+	}
+
+	sign := ""
+	rest := uint64(d)
+	if d < 0 {
+		sign = "-"
+		rest = -rest
+	}
+	var terms []string
+	for _, u := range goDurationUnits {
+		if n := rest / uint64(u.unit); n > 0 {
+			terms = append(terms, fmt.Sprintf("%d*%s", n, u.name))
+			rest %= uint64(u.unit)
+		}
+	}
+	if len(terms) == 1 {
+		return sign + strings.Replace(terms[0], "*", " * ", 1)
+	}
+	if sign != "" {
+		return sign + "(" + strings.Join(terms, " + ") + ")"
+	}
+	return strings.Join(terms, " + ")
+}
+
 // ConvertTSType は Go の型名を TypeScript の型名に変換します。
 func ConvertTSType(goType string) string {
 	switch goType {
@@ -62,6 +62,30 @@ func TestHasDate(t *testing.T) {
 			},
 			expected: true,
 		},
+		{
+			name: "Date array",
+			definitions: map[string]types.Definition{
+				"Holidays": {Type: "date[]"},
+			},
+			expected: true,
+		},
+		{
+			name: "Date in nested object field",
+			definitions: map[string]types.Definition{
+				"Policy": {Type: types.DefinitionTypeObject, Fields: []types.Field{
+					{Name: "retry", Type: types.DefinitionTypeObject, Fields: []types.Field{{Name: "since", Type: types.DefinitionTypeDate}}},
+				}},
+			},
+			expected: true,
+		},
+		{
+			name: "Date without time.Time",
+			definitions: map[string]types.Definition{
+				"ReleasedAt": {Type: types.DefinitionTypeDate, GoMode: types.GoModeString},
+				"ExpiresAt":  {Type: types.DefinitionTypeDate, GoMode: types.GoModeInt64},
+			},
+			expected: false,
+		},
 		{
 			name: "No date",
 			definitions: map[string]types.Definition{
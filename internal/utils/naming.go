@@ -4,6 +4,9 @@ import (
 	"path/filepath"
 	"strings"
 	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
 )
 
 // ToSnakeCase converts a string to snake_case
@@ -84,6 +87,53 @@ func ToCamelCase(s string) string {
 	return result
 }
 
+// commonInitialisms are parts written in all caps in Go identifiers (e.g. "id" -> "ID")
+var commonInitialisms = map[string]bool{
+	"API": true, "DB": true, "HTTP": true, "HTTPS": true, "ID": true, "IP": true,
+	"JSON": true, "SQL": true, "TTL": true, "UI": true, "URI": true, "URL": true, "UUID": true,
+}
+
+// ToPascalCase converts a string to PascalCase, keeping the case of letters after the first of each part.
+// Parts that are common initialisms are written in all caps
+// (e.g. "max_burst" -> "MaxBurst", "userID" -> "UserID", "user_id" -> "UserID")
+func ToPascalCase(s string) string {
+	parts := strings.FieldsFunc(s, func(r rune) bool {
+		return r == '_' || r == '-'
+	})
+
+	var result strings.Builder
+	for _, part := range parts {
+		if upper := strings.ToUpper(part); commonInitialisms[upper] && (part == upper || part == strings.ToLower(part)) {
+			result.WriteString(upper)
+			continue
+		}
+		runes := []rune(part)
+		runes[0] = unicode.ToUpper(runes[0])
+		result.WriteString(string(runes))
+	}
+	return result.String()
+}
+
+// EnumMemberName converts an enum value to the name used for its constant in generated code.
+// The first letter of each word is capitalized, and characters that cannot be used in identifiers
+// are removed, capitalizing the letter after them (e.g. "active" -> "Active", "pending-review" -> "PendingReview").
+func EnumMemberName(s string) string {
+	var result strings.Builder
+	upper := false
+	for _, r := range cases.Title(language.Und, cases.NoLower).String(s) {
+		if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		result.WriteRune(r)
+	}
+	return result.String()
+}
+
 // ConvertFileName converts a file name to the specified naming style
 func ConvertFileName(fileName string, namingStyle string, isTS bool) string {
 	// Extract base name without extension
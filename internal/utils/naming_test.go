@@ -82,6 +82,58 @@ func TestToCamelCase(t *testing.T) {
 	}
 }
 
+func TestToPascalCase(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"empty string", "", ""},
+		{"camelCase", "userID", "UserID"},
+		{"snake_case", "max_burst", "MaxBurst"},
+		{"kebab-case", "retry-after", "RetryAfter"},
+		{"PascalCase", "HelloWorld", "HelloWorld"},
+		{"initialism", "id", "ID"},
+		{"initialism part", "api_base_url", "APIBaseURL"},
+		{"not an initialism", "idle", "Idle"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ToPascalCase(tt.input)
+			if result != tt.expected {
+				t.Errorf("ToPascalCase(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEnumMemberName(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"lowercase", "active", "Active"},
+		{"camelCase", "inProgress", "InProgress"},
+		{"snake_case keeps underscores", "in_progress", "In_progress"},
+		{"kebab-case", "pending-review", "PendingReview"},
+		{"dot", "v1.beta", "V1Beta"},
+		{"space", "on hold", "OnHold"},
+		{"non-ASCII letters", "über", "Über"},
+		{"only symbols", "--", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := EnumMemberName(tt.input)
+			if result != tt.expected {
+				t.Errorf("EnumMemberName(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
 func TestConvertFileName(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -0,0 +1,173 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/nantokaworks/konst/internal/types"
+)
+
+// 整数型ごとの値の範囲
+var (
+	minInt32  = big.NewInt(math.MinInt32)
+	maxInt32  = big.NewInt(math.MaxInt32)
+	minInt64  = big.NewInt(math.MinInt64)
+	maxInt64  = big.NewInt(math.MaxInt64)
+	maxUint32 = new(big.Int).SetUint64(math.MaxUint32)
+	maxUint64 = new(big.Int).SetUint64(math.MaxUint64)
+)
+
+// AsNumber は JSON の数値を json.Number として返します。
+// 定義ファイルは json.Number でデコードしますが、コードから組み立てた float64 も受け付けます。
+func AsNumber(v any) (json.Number, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		return n, true
+	case float64:
+		return json.Number(strconv.FormatFloat(n, 'f', -1, 64)), true
+	default:
+		return "", false
+	}
+}
+
+// IsNumberLiteral は文字列が JSON の数値リテラルとして正しいかどうかを返します。
+func IsNumberLiteral(s string) bool {
+	if s == "" {
+		return false
+	}
+	var n json.Number
+	return json.Unmarshal([]byte(s), &n) == nil
+}
+
+// floatLiteral は YAML や TOML の浮動小数点数の表記（1_000.5、+1.5、.5、5. など）を、書かれた桁のまま JSON の数値リテラルに直します。
+// float64 を経由しないため、float64 で表せない桁も失いません。inf や nan など数値リテラルにならない表記は false を返します。
+func floatLiteral(s string) (json.Number, bool) {
+	s = strings.TrimPrefix(strings.ReplaceAll(s, "_", ""), "+")
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	exponent := ""
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		s, exponent = s[:i], s[i:]
+	}
+	integer, fraction, _ := strings.Cut(s, ".")
+	integer = strings.TrimLeft(integer, "0")
+	if integer == "" {
+		integer = "0"
+	}
+	literal := sign + integer
+	if fraction != "" {
+		literal += "." + fraction
+	}
+	literal += exponent
+	if !IsNumberLiteral(literal) {
+		return "", false
+	}
+	return json.Number(literal), true
+}
+
+// NumberRat は数値を誤差のない有理数として返します。
+func NumberRat(n json.Number) (*big.Rat, bool) {
+	return new(big.Rat).SetString(n.String())
+}
+
+// IsIntegerNumber は数値が整数値かどうかを返します（1.0 や 1e3 も整数として扱います）。
+func IsIntegerNumber(n json.Number) bool {
+	r, ok := NumberRat(n)
+	return ok && r.IsInt()
+}
+
+// IntegerLiteral は整数値の数値を指数や小数点を含まない10進表記で返します。
+func IntegerLiteral(n json.Number) (string, bool) {
+	r, ok := NumberRat(n)
+	if !ok || !r.IsInt() {
+		return "", false
+	}
+	return r.Num().String(), true
+}
+
+// CheckNumber は数値が定義型の値として表現できるかを検証します。
+// 整数型では整数であること・符号・範囲を、浮動小数点型では範囲を確認します。
+// float32 では、書かれた値が float32 に丸めると変わってしまう場合もエラーにします。
+func CheckNumber(t types.DefinitionType, n json.Number) error {
+	switch t {
+	case types.DefinitionTypeInt, types.DefinitionTypeInt64:
+		return checkInteger(t, n, minInt64, maxInt64)
+	case types.DefinitionTypeInt32:
+		return checkInteger(t, n, minInt32, maxInt32)
+	case types.DefinitionTypeUint, types.DefinitionTypeUint64:
+		return checkInteger(t, n, new(big.Int), maxUint64)
+	case types.DefinitionTypeUint32:
+		return checkInteger(t, n, new(big.Int), maxUint32)
+	case types.DefinitionTypeFloat, types.DefinitionTypeFloat32:
+		return checkFloat(t, n, 32)
+	case types.DefinitionTypeFloat64:
+		return checkFloat(t, n, 64)
+	}
+	return nil
+}
+
+// checkInteger は数値が [min, max] の範囲の整数かどうかを検証します
+func checkInteger(t types.DefinitionType, n json.Number, min, max *big.Int) error {
+	r, ok := NumberRat(n)
+	if !ok {
+		return fmt.Errorf("value %s is not a number", n)
+	}
+	if !r.IsInt() {
+		return fmt.Errorf("value %s is not an integer", n)
+	}
+	v := r.Num()
+	if v.Sign() < 0 && min.Sign() == 0 {
+		return fmt.Errorf("value %s must not be negative for type %q", n, t)
+	}
+	if v.Cmp(min) < 0 || v.Cmp(max) > 0 {
+		return fmt.Errorf("value %s is out of range for type %q (%s to %s)", n, t, min, max)
+	}
+	return nil
+}
+
+// checkFloat は数値が指定ビット幅の浮動小数点数で表現できる範囲かどうかを検証します。
+// 32ビットの場合は、丸めた値の最短表記が元の値と一致するか（精度が落ちないか）も確認します。
+func checkFloat(t types.DefinitionType, n json.Number, bitSize int) error {
+	f, err := strconv.ParseFloat(n.String(), bitSize)
+	if errors.Is(err, strconv.ErrRange) {
+		return fmt.Errorf("value %s is out of range for type %q", n, t)
+	}
+	if err != nil {
+		return fmt.Errorf("value %s is not a number", n)
+	}
+	if bitSize == 32 {
+		rounded := strconv.FormatFloat(f, 'g', -1, 32)
+		want, _ := NumberRat(n)
+		got, _ := NumberRat(json.Number(rounded))
+		if want.Cmp(got) != 0 {
+			return fmt.Errorf("value %s loses precision as type %q (would be %s)", n, t, rounded)
+		}
+	}
+	return nil
+}
+
+// IsIntegerType は整数型かどうかを返します
+func IsIntegerType(t types.DefinitionType) bool {
+	switch t {
+	case types.DefinitionTypeInt, types.DefinitionTypeInt32, types.DefinitionTypeInt64,
+		types.DefinitionTypeUint, types.DefinitionTypeUint32, types.DefinitionTypeUint64:
+		return true
+	}
+	return false
+}
+
+// isFloatType は浮動小数点型かどうかを返します
+func isFloatType(t types.DefinitionType) bool {
+	switch t {
+	case types.DefinitionTypeFloat, types.DefinitionTypeFloat32, types.DefinitionTypeFloat64:
+		return true
+	}
+	return false
+}
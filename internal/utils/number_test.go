@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/nantokaworks/konst/internal/types"
+)
+
+func TestCheckNumber(t *testing.T) {
+	tests := []struct {
+		defType  types.DefinitionType
+		value    json.Number
+		expected string // 空ならエラーなし、それ以外はエラーメッセージの断片
+	}{
+		{types.DefinitionTypeInt, "42", ""},
+		{types.DefinitionTypeInt, "1e3", ""},
+		{types.DefinitionTypeInt, "1.5", "is not an integer"},
+		{types.DefinitionTypeInt32, "2147483647", ""},
+		{types.DefinitionTypeInt32, "2147483648", "out of range"},
+		{types.DefinitionTypeInt32, "-2147483649", "out of range"},
+		{types.DefinitionTypeInt64, "9223372036854775807", ""},
+		{types.DefinitionTypeInt64, "9223372036854775808", "out of range"},
+		{types.DefinitionTypeUint, "-1", "must not be negative"},
+		{types.DefinitionTypeUint32, "4294967296", "out of range"},
+		{types.DefinitionTypeUint64, "18446744073709551615", ""},
+		{types.DefinitionTypeUint64, "18446744073709551616", "out of range"},
+		{types.DefinitionTypeFloat32, "3.4e38", ""},
+		{types.DefinitionTypeFloat32, "1e39", "out of range"},
+		{types.DefinitionTypeFloat32, "3.14", ""},
+		{types.DefinitionTypeFloat32, "16777216", ""},
+		{types.DefinitionTypeFloat32, "16777217", "loses precision"},
+		{types.DefinitionTypeFloat, "1.23456789", "loses precision"},
+		{types.DefinitionTypeFloat32, "1e-50", "loses precision"},
+		{types.DefinitionTypeFloat64, "1.23456789", ""},
+		{types.DefinitionTypeFloat64, "1e39", ""},
+		{types.DefinitionTypeFloat64, "1e309", "out of range"},
+	}
+
+	for _, tt := range tests {
+		err := CheckNumber(tt.defType, tt.value)
+		if tt.expected == "" {
+			if err != nil {
+				t.Errorf("CheckNumber(%s, %s) unexpected error: %v", tt.defType, tt.value, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("CheckNumber(%s, %s) = %v, expected error containing %q", tt.defType, tt.value, err, tt.expected)
+		}
+	}
+}
+
+func TestAsNumber(t *testing.T) {
+	tests := []struct {
+		input    any
+		expected json.Number
+		ok       bool
+	}{
+		{json.Number("18446744073709551615"), "18446744073709551615", true},
+		{float64(1.23456789), "1.23456789", true},
+		{float64(3), "3", true},
+		{"3", "", false},
+	}
+
+	for _, tt := range tests {
+		result, ok := AsNumber(tt.input)
+		if result != tt.expected || ok != tt.ok {
+			t.Errorf("AsNumber(%v) = (%q, %v), expected (%q, %v)", tt.input, result, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+func TestIntegerLiteral(t *testing.T) {
+	tests := []struct {
+		input    json.Number
+		expected string
+		ok       bool
+	}{
+		{"9223372036854775807", "9223372036854775807", true},
+		{"1e3", "1000", true},
+		{"2.0", "2", true},
+		{"2.5", "", false},
+	}
+
+	for _, tt := range tests {
+		result, ok := IntegerLiteral(tt.input)
+		if result != tt.expected || ok != tt.ok {
+			t.Errorf("IntegerLiteral(%q) = (%q, %v), expected (%q, %v)", tt.input, result, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+func TestFloatLiteral(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected json.Number
+		ok       bool
+	}{
+		{"1.23456789012345678", "1.23456789012345678", true},
+		{"1_000.000_001", "1000.000001", true},
+		{"+1.5e-3", "1.5e-3", true},
+		{"-.5", "-0.5", true},
+		{"5.", "5", true},
+		{"007.5", "7.5", true},
+		{".inf", "", false},
+		{"nan", "", false},
+	}
+
+	for _, tt := range tests {
+		result, ok := floatLiteral(tt.input)
+		if result != tt.expected || ok != tt.ok {
+			t.Errorf("floatLiteral(%q) = (%q, %v), expected (%q, %v)", tt.input, result, ok, tt.expected, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// OutputWriter は生成したファイルをまとめて書き込みます。
+// Add で内容を出力先と同じディレクトリの一時ファイルに書き込み、Commit で全ての一時ファイルの名前を出力先に変更します。
+// Commit の前に失敗した場合は Abort で一時ファイルを削除するため、既存のファイルは変更されません。
+// Commit の途中で失敗した場合は、それまでに置き換えたファイルを元の内容に戻します。
+// ゼロ値のまま使えます。
+type OutputWriter struct {
+	staged []stagedFile
+}
+
+// stagedFile は書き込み待ちのファイルです
+type stagedFile struct {
+	path string // 出力先のパス
+	temp string // 内容を書き込んだ一時ファイルのパス
+}
+
+// Add は path に書き込む内容 content を一時ファイルに書き込みます。
+// force が false で path が既に存在する場合はエラーを返します。
+func (w *OutputWriter) Add(path string, content []byte, force bool) error {
+	// 既存のファイルはパーミッションを引き継ぐ
+	mode := fs.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		if !force {
+			return fmt.Errorf("output file %s already exists (use -f to overwrite)", path)
+		}
+		mode = info.Mode().Perm()
+	}
+
+	// 書き出し先のディレクトリが存在しない場合、自動で作成する
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	// 名前の変更で置き換えられるよう、一時ファイルは出力先と同じディレクトリに作成する
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(content)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), mode)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	w.staged = append(w.staged, stagedFile{path: path, temp: tmp.Name()})
+	return nil
+}
+
+// backup は Commit で置き換える前の出力先のファイルです
+type backup struct {
+	path    string
+	content []byte // 元の内容（exists が false の場合は nil）
+	mode    fs.FileMode
+	exists  bool // false の場合は Commit で新しく作成したファイル
+}
+
+// Commit は Add で書き込んだ全ての一時ファイルの名前を出力先に変更します。
+// 途中で失敗した場合は、置き換えたファイルを元の内容に戻し、新しく作成したファイルを削除してからエラーを返します。
+func (w *OutputWriter) Commit() error {
+	var done []backup
+	for i, s := range w.staged {
+		b, err := backupFile(s.path)
+		if err == nil {
+			err = os.Rename(s.temp, s.path)
+		}
+		if err != nil {
+			w.staged = w.staged[i:]
+			w.Abort()
+			if rerr := rollback(done); rerr != nil {
+				return errors.Join(err, rerr)
+			}
+			return err
+		}
+		done = append(done, b)
+	}
+	w.staged = nil
+	return nil
+}
+
+// backupFile は置き換える前の出力先のファイルの内容を読み込みます
+func backupFile(path string) (backup, error) {
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return backup{path: path}, nil
+	}
+	if err != nil {
+		return backup{}, err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return backup{}, err
+	}
+	return backup{path: path, content: content, mode: info.Mode().Perm(), exists: true}, nil
+}
+
+// rollback は Commit で置き換えたファイルを後から順に元に戻します
+func rollback(done []backup) error {
+	var errs []error
+	for i := len(done) - 1; i >= 0; i-- {
+		b := done[i]
+		var err error
+		if b.exists {
+			err = os.WriteFile(b.path, b.content, b.mode)
+		} else {
+			err = os.Remove(b.path)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore %s: %w", b.path, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Abort は Commit していない一時ファイルを削除します。Commit の後に呼んでも何もしません
+func (w *OutputWriter) Abort() {
+	for _, s := range w.staged {
+		os.Remove(s.temp)
+	}
+	w.staged = nil
+}
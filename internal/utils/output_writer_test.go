@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOutputWriter(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.go")
+	if err := os.WriteFile(existing, []byte("old"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	created := filepath.Join(dir, "sub", "created.go")
+
+	var w OutputWriter
+	if err := w.Add(created, []byte("created"), false); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := w.Add(existing, []byte("new"), false); err == nil {
+		t.Fatal("Expected error for existing file without force")
+	}
+	if err := w.Add(existing, []byte("new"), true); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	// Commit するまで出力先は変わらない
+	if data, _ := os.ReadFile(existing); string(data) != "old" {
+		t.Errorf("Expected existing file unchanged before Commit, got %q", data)
+	}
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Errorf("Expected %s not to exist before Commit", created)
+	}
+
+	if err := w.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	if data, _ := os.ReadFile(created); string(data) != "created" {
+		t.Errorf("created.go = %q", data)
+	}
+	if info, err := os.Stat(existing); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected existing file to keep its permission, got %v, %v", info.Mode(), err)
+	}
+	assertNoTempFiles(t, dir)
+}
+
+func TestOutputWriterAbort(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.ts")
+	if err := os.WriteFile(existing, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var w OutputWriter
+	if err := w.Add(existing, []byte("new"), true); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	w.Abort()
+
+	if data, _ := os.ReadFile(existing); string(data) != "old" {
+		t.Errorf("Expected existing file unchanged after Abort, got %q", data)
+	}
+	assertNoTempFiles(t, dir)
+}
+
+func TestOutputWriterCommitRollback(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.go")
+	if err := os.WriteFile(existing, []byte("old"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	created := filepath.Join(dir, "created.go")
+	blocked := filepath.Join(dir, "blocked.go")
+
+	var w OutputWriter
+	for _, path := range []string{existing, created, blocked} {
+		if err := w.Add(path, []byte("new"), true); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+	// 最後のファイルの出力先を空でないディレクトリにして、名前の変更を失敗させる
+	if err := os.MkdirAll(filepath.Join(blocked, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Commit(); err == nil {
+		t.Fatal("Expected Commit to fail")
+	}
+	if data, _ := os.ReadFile(existing); string(data) != "old" {
+		t.Errorf("Expected existing file restored after failed Commit, got %q", data)
+	}
+	if info, err := os.Stat(existing); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected existing file to keep its permission, got %v, %v", info.Mode(), err)
+	}
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Errorf("Expected %s to be removed after failed Commit", created)
+	}
+	assertNoTempFiles(t, dir)
+}
+
+// assertNoTempFiles は一時ファイルが残っていないことを確認します
+func assertNoTempFiles(t *testing.T, dir string) {
+	t.Helper()
+	matches, _ := filepath.Glob(filepath.Join(dir, "*", ".*.tmp"))
+	top, _ := filepath.Glob(filepath.Join(dir, ".*.tmp"))
+	if len(matches)+len(top) > 0 {
+		t.Errorf("Expected no temporary files, got %v", append(matches, top...))
+	}
+}
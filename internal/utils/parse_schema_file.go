@@ -1,15 +1,19 @@
 package utils
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"io"
 	"os"
 	"path/filepath"
-	"strings"
 
+	"github.com/nantokaworks/konst/internal/diag"
 	"github.com/nantokaworks/konst/internal/types"
 )
 
-// ParseSchemaFile は指定された JSON ファイルまたはディレクトリ内の JSON を再帰的にパースします。
+// ParseSchemaFile は指定された定義ファイル、またはディレクトリ内の定義ファイルを再帰的にパースします。
+// 定義ファイルの形式は拡張子（.json / .jsonc / .json5 / .yaml / .yml / .toml）で判定します。
 func ParseSchemaFile(filename *string) (*types.Schema, error) {
 	info, err := os.Stat(*filename)
 	if err != nil {
@@ -17,21 +21,25 @@ func ParseSchemaFile(filename *string) (*types.Schema, error) {
 	}
 
 	// ディレクトリの場合は再帰的に JSON を処理
+	// 1つのスキーマ（1つの名前空間）にまとめるため、複数の定義ファイルにある同じ名前の定義はエラーにする
+	// （ディレクトリを生成する場合と同じく、同じ名前空間に生成される同じ名前の定義はエラー）
 	if info.IsDir() {
 		master := &types.Schema{
 			Definitions: make(map[string]types.Definition),
 		}
+		owners := make(map[string]string) // 定義名 → 定義ファイル
+		var duplicates diag.List
 		err := filepath.Walk(*filename, func(path string, info os.FileInfo, err error) error {
 			if err != nil {
 				return err
 			}
-			if !info.IsDir() && strings.HasSuffix(info.Name(), ".json") {
+			if !info.IsDir() && IsSchemaFile(path) {
 				data, err := os.ReadFile(path)
 				if err != nil {
 					return err
 				}
-				var schema types.Schema
-				if err := json.Unmarshal(data, &schema); err != nil {
+				schema, err := ParseSchemaData(path, data)
+				if err != nil {
 					return err
 				}
 				// master の Version と GoPackage を初回設定
@@ -41,8 +49,14 @@ func ParseSchemaFile(filename *string) (*types.Schema, error) {
 				if master.GoPackage == "" {
 					master.GoPackage = schema.GoPackage
 				}
-				for k, def := range schema.Definitions {
-					master.Definitions[k] = def // キー重複時は上書き
+				for _, k := range sortedNames(schema.Definitions) {
+					def := schema.Definitions[k]
+					if owner, exists := owners[k]; exists {
+						duplicates = append(duplicates, diag.New(diag.CodeDuplicateDefinition, def.Pos, k, "already defined in %s", owner))
+						continue
+					}
+					owners[k] = path
+					master.Definitions[k] = def
 				}
 			}
 			return nil
@@ -50,6 +64,10 @@ func ParseSchemaFile(filename *string) (*types.Schema, error) {
 		if err != nil {
 			return nil, err
 		}
+		if len(duplicates) > 0 {
+			duplicates.Sort()
+			return nil, duplicates
+		}
 		return master, nil
 	}
 
@@ -57,10 +75,48 @@ func ParseSchemaFile(filename *string) (*types.Schema, error) {
 	if err != nil {
 		return nil, err
 	}
-	var schema types.Schema
-	if err := json.Unmarshal(data, &schema); err != nil {
+	return ParseSchemaData(*filename, data)
+}
+
+// ParseSchemaData は読み込んだ定義ファイルの内容 data を、path の拡張子に対応するデコーダーでパースします。
+func ParseSchemaData(path string, data []byte) (*types.Schema, error) {
+	decoder, err := schemaDecoderFor(path)
+	if err != nil {
 		return nil, err
 	}
+	return decoder(path, data)
+}
 
+// decodeJSONSchema は JSON データをパースし、各定義に定義ファイル内の位置を設定します。
+// 数値は精度を失わないよう float64 ではなく json.Number としてデコードします。
+// パースエラーは位置付きの diag.Diagnostic として返します。
+func decodeJSONSchema(path string, data []byte) (*types.Schema, error) {
+	var schema types.Schema
+	if err := decodeJSON(data, &schema); err != nil {
+		return nil, diag.FromJSONError(path, data, err)
+	}
+
+	top, defs, err := schemaKeyPositions(path, data)
+	if err != nil {
+		return nil, diag.FromJSONError(path, data, err)
+	}
+	setSchemaPositions(&schema, path, top, defs)
 	return &schema, nil
 }
+
+// decodeJSON は数値を json.Number として保持したまま JSON をデコードします。
+// json.Unmarshal と同様に、値の後ろに余分なデータがある場合はエラーにします。
+func decodeJSON(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		if err == nil {
+			return errors.New("invalid character after top-level value")
+		}
+		return err
+	}
+	return nil
+}
@@ -1,10 +1,14 @@
 package utils
 
 import (
+	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/nantokaworks/konst/internal/diag"
 	"github.com/nantokaworks/konst/internal/types"
 )
 
@@ -128,6 +132,23 @@ func TestParseSchemaFileDirectory(t *testing.T) {
 	}
 }
 
+func TestParseSchemaFileDirectoryDuplicates(t *testing.T) {
+	tempDir := t.TempDir()
+	for _, name := range []string{"a.json", "b.json"} {
+		data := `{"version": "1.0", "definitions": {"MaxRetries": {"type": "int", "value": 3}}}`
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	// 1つのスキーマにまとめるため、後のファイルの同じ名前の定義はエラーになる
+	_, err := ParseSchemaFile(&tempDir)
+	var d *diag.Diagnostic
+	if !errors.As(err, &d) || d.Code != diag.CodeDuplicateDefinition || d.Pos.File != filepath.Join(tempDir, "b.json") {
+		t.Errorf("Expected duplicate-definition diagnostic in b.json, got %v", err)
+	}
+}
+
 func TestParseSchemaFileInvalidJSON(t *testing.T) {
 	tempDir := t.TempDir()
 
@@ -149,3 +170,117 @@ func TestParseSchemaFileInvalidJSON(t *testing.T) {
 		t.Error("Expected error for invalid JSON, but got nil")
 	}
 }
+
+func TestParseSchemaFilePositions(t *testing.T) {
+	tempDir := t.TempDir()
+
+	testJSON := "{\n" +
+		"  \"version\": \"1.0\",\n" +
+		"  \"goPackage\": \"test\",\n" +
+		"  \"definitions\": {\n" +
+		"    \"First\": {\"type\": \"string\", \"value\": \"{\\\"nested\\\": 1}\"},\n" +
+		"    \"Second\": {\n" +
+		"      \"type\": \"int[]\",\n" +
+		"      \"value\": [1, {\"First\": 2}]\n" +
+		"    }\n" +
+		"  }\n" +
+		"}"
+
+	testFile := filepath.Join(tempDir, "positions.json")
+	if err := os.WriteFile(testFile, []byte(testJSON), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	schema, err := ParseSchemaFile(&testFile)
+	if err != nil {
+		t.Fatalf("ParseSchemaFile failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		expected types.Position
+	}{
+		{"First", types.Position{File: testFile, Line: 5, Column: 5}},
+		{"Second", types.Position{File: testFile, Line: 6, Column: 5}},
+	}
+	for _, tt := range tests {
+		if got := schema.Definitions[tt.name].Pos; got != tt.expected {
+			t.Errorf("Expected %s at %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+
+	if got := schema.KeyPos("goPackage"); got.Line != 3 || got.Column != 3 {
+		t.Errorf("Expected goPackage at 3:3, got %v", got)
+	}
+}
+
+func TestParseSchemaFileErrorPosition(t *testing.T) {
+	tempDir := t.TempDir()
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			name:     "Syntax error",
+			content:  "{\n  \"version\": \"1.0\",\n  \"definitions\": {,}\n}",
+			expected: ":3:19: ",
+		},
+		{
+			name:     "Type error",
+			content:  "{\n  \"definitions\": {\n    \"A\": {\"type\": \"enum\", \"values\": \"a\"}\n  }\n}",
+			expected: ":3:37: ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testFile := filepath.Join(tempDir, "invalid.json")
+			if err := os.WriteFile(testFile, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+
+			_, err := ParseSchemaFile(&testFile)
+			if err == nil {
+				t.Fatal("Expected error, but got nil")
+			}
+			if !strings.HasPrefix(err.Error(), testFile+tt.expected) {
+				t.Errorf("Expected error at %s%s, got %q", testFile, tt.expected, err.Error())
+			}
+		})
+	}
+}
+
+func TestParseSchemaFileKeepsNumberPrecision(t *testing.T) {
+	tempDir := t.TempDir()
+
+	testJSON := `{
+		"definitions": {
+			"UInt64Value": {"type": "uint64", "value": 18446744073709551615},
+			"Float64Value": {"type": "float64", "value": 1.23456789},
+			"IntArrayValue": {"type": "int64[]", "value": [9223372036854775807, 1]}
+		}
+	}`
+
+	testFile := filepath.Join(tempDir, "numbers.json")
+	if err := os.WriteFile(testFile, []byte(testJSON), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	schema, err := ParseSchemaFile(&testFile)
+	if err != nil {
+		t.Fatalf("ParseSchemaFile failed: %v", err)
+	}
+
+	if v := schema.Definitions["UInt64Value"].Value; v != json.Number("18446744073709551615") {
+		t.Errorf("Expected exact uint64 value, got %#v", v)
+	}
+	if v := schema.Definitions["Float64Value"].Value; v != json.Number("1.23456789") {
+		t.Errorf("Expected exact float64 value, got %#v", v)
+	}
+	arr, ok := schema.Definitions["IntArrayValue"].Value.([]interface{})
+	if !ok || len(arr) != 2 || arr[0] != json.Number("9223372036854775807") {
+		t.Errorf("Expected exact array values, got %#v", schema.Definitions["IntArrayValue"].Value)
+	}
+}
@@ -0,0 +1,167 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nantokaworks/konst/internal/diag"
+	"github.com/nantokaworks/konst/internal/manifest"
+	"github.com/nantokaworks/konst/internal/types"
+)
+
+// SchemaDecoder は定義ファイルの内容を types.Schema にデコードします。
+// File・Keys・各定義の Pos を設定し、数値は精度を失わないよう json.Number として返してください。
+// エラーは可能な限り位置付きの diag.Diagnostic として返します。
+type SchemaDecoder func(path string, data []byte) (*types.Schema, error)
+
+// schemaDecoders は拡張子（小文字、"." 付き）ごとのデコーダーです
+var schemaDecoders = map[string]SchemaDecoder{
+	".json":  decodeJSONSchema,
+	".jsonc": decodeJSONCSchema,
+	".json5": decodeJSON5Schema,
+	".yaml":  decodeYAMLSchema,
+	".yml":   decodeYAMLSchema,
+	".toml":  decodeTOMLSchema,
+}
+
+// enabledExtensions はディレクトリ走査時に読み込む拡張子です。nil の場合は登録済みの全拡張子を読み込みます
+var enabledExtensions map[string]bool
+
+// RegisterSchemaDecoder は拡張子に対応するデコーダーを登録します。既存の登録は上書きします。
+func RegisterSchemaDecoder(ext string, decoder SchemaDecoder) {
+	schemaDecoders[normalizeExtension(ext)] = decoder
+}
+
+// SchemaExtensions は読み込み対象の拡張子を昇順で返します
+func SchemaExtensions() []string {
+	var exts []string
+	for ext := range schemaDecoders {
+		if enabledExtensions == nil || enabledExtensions[ext] {
+			exts = append(exts, ext)
+		}
+	}
+	sort.Strings(exts)
+	return exts
+}
+
+// SetSchemaExtensions はディレクトリ走査時に読み込む拡張子を "json,yaml" のようなカンマ区切りで指定します。
+// 空文字列の場合は登録済みの全拡張子を読み込みます。
+func SetSchemaExtensions(list string) error {
+	if strings.TrimSpace(list) == "" {
+		enabledExtensions = nil
+		return nil
+	}
+	enabled := make(map[string]bool)
+	for _, ext := range strings.Split(list, ",") {
+		ext = normalizeExtension(ext)
+		if ext == "." {
+			continue
+		}
+		if _, ok := schemaDecoders[ext]; !ok {
+			return fmt.Errorf("unsupported definition file extension: %s", ext)
+		}
+		enabled[ext] = true
+	}
+	if len(enabled) == 0 {
+		return errors.New("no definition file extensions specified")
+	}
+	enabledExtensions = enabled
+	return nil
+}
+
+// IsSchemaFile はパスが読み込み対象の定義ファイルかどうかを拡張子で判定します。
+// 出力先が入力ディレクトリの中にある場合に備えて、生成したファイルのマニフェストは除きます
+func IsSchemaFile(path string) bool {
+	if filepath.Base(path) == manifest.FileName {
+		return false
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	if _, ok := schemaDecoders[ext]; !ok {
+		return false
+	}
+	return enabledExtensions == nil || enabledExtensions[ext]
+}
+
+// normalizeExtension は拡張子を小文字の "." 付きの形に揃えます
+func normalizeExtension(ext string) string {
+	ext = strings.ToLower(strings.TrimSpace(ext))
+	return "." + strings.TrimPrefix(ext, ".")
+}
+
+// schemaDecoderFor はパスの拡張子に対応するデコーダーを返します
+func schemaDecoderFor(path string) (SchemaDecoder, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	decoder, ok := schemaDecoders[ext]
+	if !ok {
+		return nil, diag.New(diag.CodeSyntax, types.Position{File: path}, "", "unsupported definition file format %q (supported: %s)", ext, strings.Join(registeredExtensions(), ", "))
+	}
+	return decoder, nil
+}
+
+// registeredExtensions は登録済みの全拡張子を昇順で返します
+func registeredExtensions() []string {
+	exts := make([]string, 0, len(schemaDecoders))
+	for ext := range schemaDecoders {
+		exts = append(exts, ext)
+	}
+	sort.Strings(exts)
+	return exts
+}
+
+// schemaFromValue は YAML や TOML から読み込んだ JSON 相当の値を Schema に変換します。
+// 構造がスキーマと合わない場合は、該当する定義の位置（分からなければファイル）を付けたエラーを返します。
+func schemaFromValue(path string, value any, top, defs map[string]types.Position) (*types.Schema, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, diag.New(diag.CodeSyntax, types.Position{File: path}, "", "%v", err)
+	}
+	var schema types.Schema
+	if err := decodeJSON(data, &schema); err != nil {
+		pos := types.Position{File: path}
+		msg := err.Error()
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			msg = fmt.Sprintf("cannot use %s as %s", typeErr.Value, typeErr.Type)
+			if typeErr.Field != "" {
+				msg = fmt.Sprintf("%s for field %q", msg, typeErr.Field)
+			}
+			pos = fieldPosition(path, typeErr.Field, top, defs)
+		}
+		return nil, diag.New(diag.CodeJSONType, pos, "", "%s", msg)
+	}
+	setSchemaPositions(&schema, path, top, defs)
+	return &schema, nil
+}
+
+// fieldPosition は "definitions.Name.value" のようなフィールドパスに対応するキーの位置を返します
+func fieldPosition(path, field string, top, defs map[string]types.Position) types.Position {
+	parts := strings.Split(field, ".")
+	if len(parts) >= 2 && parts[0] == "definitions" {
+		if pos, ok := defs[parts[1]]; ok {
+			return pos
+		}
+	}
+	if pos, ok := top[parts[0]]; ok {
+		return pos
+	}
+	return types.Position{File: path}
+}
+
+// setSchemaPositions はスキーマにファイル名とキーの位置、各定義の名前を設定します。
+// 位置が分からない定義にはファイル名のみを設定します。
+func setSchemaPositions(schema *types.Schema, path string, top, defs map[string]types.Position) {
+	schema.File = path
+	schema.Keys = top
+	for name, def := range schema.Definitions {
+		def.Name = name
+		def.Pos = defs[name]
+		if !def.Pos.IsValid() {
+			def.Pos = types.Position{File: path}
+		}
+		schema.Definitions[name] = def
+	}
+}
@@ -0,0 +1,228 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nantokaworks/konst/internal/diag"
+	"github.com/nantokaworks/konst/internal/types"
+)
+
+func TestParseSchemaFileYAML(t *testing.T) {
+	testYAML := `# ネットワーク設定
+version: "1.0"
+goPackage: test
+definitions:
+  Port:
+    type: uint32
+    value: 0x1F90
+  # uint64 の最大値
+  MaxValue:
+    type: uint64
+    value: 18446744073709551615
+  Ratio:
+    type: float64
+    value: 1.23456789
+  Precise:
+    type: float64
+    value: +1_000.000_000_000_000_000_1
+  ReleasedAt:
+    type: date
+    value: 2024-01-01T00:00:00Z
+  Status:
+    type: enum
+    values: [active, inactive]
+`
+	testFile := filepath.Join(t.TempDir(), "test.yaml")
+	if err := os.WriteFile(testFile, []byte(testYAML), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	schema, err := ParseSchemaFile(&testFile)
+	if err != nil {
+		t.Fatalf("ParseSchemaFile failed: %v", err)
+	}
+
+	expected := map[string]any{
+		"Port":       json.Number("8080"),
+		"MaxValue":   json.Number("18446744073709551615"),
+		"Ratio":      json.Number("1.23456789"),
+		"Precise":    json.Number("1000.0000000000000001"),
+		"ReleasedAt": "2024-01-01T00:00:00Z",
+	}
+	for name, value := range expected {
+		if got := schema.Definitions[name].Value; got != value {
+			t.Errorf("Expected %s value %#v, got %#v", name, value, got)
+		}
+	}
+	if values := schema.Definitions["Status"].Values; len(values) != 2 || values[1].Name != "inactive" {
+		t.Errorf("Unexpected enum values: %v", values)
+	}
+
+	if pos := schema.Definitions["MaxValue"].Pos; pos.Line != 9 || pos.Column != 3 {
+		t.Errorf("Expected MaxValue at 9:3, got %s", pos)
+	}
+	if doc := schema.Definitions["MaxValue"].Doc; doc != "uint64 の最大値" {
+		t.Errorf("Expected MaxValue doc, got %q", doc)
+	}
+	if pos := schema.KeyPos("goPackage"); pos.Line != 3 || pos.Column != 1 {
+		t.Errorf("Expected goPackage at 3:1, got %s", pos)
+	}
+}
+
+func TestParseSchemaFileTOML(t *testing.T) {
+	testTOML := `# リトライ設定
+version = "1.0"
+goPackage = "test"
+
+[definitions.BaseRetries]
+type = "int"
+value = 3
+
+[definitions]
+Ratio = { type = "float64", value = 1.23456789 }
+StartedAt = { type = "date", value = 2024-01-01T00:00:00Z }
+Precise = { type = "float64", value = 1.23456789012345678 } # float64 で表せない桁
+Label = { type = "string", value = "1.5 = 2.5" }
+Steps = { type = "float64[]", value = [
+  +1_000.000_000_000_000_000_1,
+  2e-3,
+] }
+`
+	testFile := filepath.Join(t.TempDir(), "test.toml")
+	if err := os.WriteFile(testFile, []byte(testTOML), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	schema, err := ParseSchemaFile(&testFile)
+	if err != nil {
+		t.Fatalf("ParseSchemaFile failed: %v", err)
+	}
+	if schema.GoPackage != "test" || len(schema.Definitions) != 6 {
+		t.Fatalf("Unexpected schema: %+v", schema)
+	}
+
+	expected := map[string]any{
+		"BaseRetries": json.Number("3"),
+		"Ratio":       json.Number("1.23456789"),
+		"StartedAt":   "2024-01-01T00:00:00Z",
+		"Precise":     json.Number("1.23456789012345678"),
+		"Label":       "1.5 = 2.5",
+	}
+	for name, value := range expected {
+		if got := schema.Definitions[name].Value; got != value {
+			t.Errorf("Expected %s value %#v, got %#v", name, value, got)
+		}
+	}
+	if got, ok := schema.Definitions["Steps"].Value.([]any); !ok || len(got) != 2 || got[0] != json.Number("1000.0000000000000001") || got[1] != json.Number("2e-3") {
+		t.Errorf("Unexpected Steps value %#v", schema.Definitions["Steps"].Value)
+	}
+
+	positions := map[string]types.Position{
+		"BaseRetries": {File: testFile, Line: 5, Column: 1},
+		"StartedAt":   {File: testFile, Line: 11, Column: 1},
+	}
+	for name, pos := range positions {
+		if got := schema.Definitions[name].Pos; got != pos {
+			t.Errorf("Expected %s at %s, got %s", name, pos, got)
+		}
+	}
+}
+
+func TestParseSchemaFileDecoderErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		code     diag.Code
+		expected types.Position
+	}{
+		{"YAML syntax", "broken.yaml", "version: \"1.0\"\ndefinitions:\n  X:\n    value: [1\n", diag.CodeSyntax, types.Position{Line: 3, Column: 1}},
+		{"TOML syntax", "broken.toml", "version = \"1.0\"\n[definitions.X]\ntype = \n", diag.CodeSyntax, types.Position{Line: 3}},
+		{"YAML structure", "wrong.yml", "definitions:\n  Status:\n    type: enum\n    values: 3\n", diag.CodeJSONType, types.Position{Line: 2, Column: 3}},
+		{"Unsupported extension", "defs.ini", "x=1", diag.CodeSyntax, types.Position{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testFile := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(testFile, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+
+			_, err := ParseSchemaFile(&testFile)
+			var d *diag.Diagnostic
+			if !errors.As(err, &d) {
+				t.Fatalf("Expected diagnostic, got %v", err)
+			}
+			if d.Code != tt.code || d.Pos.File != testFile {
+				t.Errorf("Unexpected diagnostic: %v (code %s)", d, d.Code)
+			}
+			if d.Pos.Line != tt.expected.Line || (tt.expected.Column != 0 && d.Pos.Column != tt.expected.Column) {
+				t.Errorf("Expected position %d:%d, got %s", tt.expected.Line, tt.expected.Column, d.Pos)
+			}
+		})
+	}
+}
+
+func TestSetSchemaExtensions(t *testing.T) {
+	defer SetSchemaExtensions("")
+
+	if err := SetSchemaExtensions("yaml, .YML"); err != nil {
+		t.Fatalf("SetSchemaExtensions failed: %v", err)
+	}
+	for path, expected := range map[string]bool{
+		"defs/a.yaml": true,
+		"defs/b.yml":  true,
+		"defs/c.json": false,
+		"defs/d.toml": false,
+		"defs/e.txt":  false,
+	} {
+		if got := IsSchemaFile(path); got != expected {
+			t.Errorf("IsSchemaFile(%q) = %v, expected %v", path, got, expected)
+		}
+	}
+
+	if err := SetSchemaExtensions("json,ini"); err == nil {
+		t.Error("Expected error for unsupported extension, but got nil")
+	}
+
+	if err := SetSchemaExtensions(""); err != nil {
+		t.Fatalf("SetSchemaExtensions failed: %v", err)
+	}
+	if got := SchemaExtensions(); len(got) != 6 {
+		t.Errorf("Expected all 6 extensions, got %v", got)
+	}
+	if IsSchemaFile("gen/.konst-manifest.json") {
+		t.Error("Expected the generation manifest not to be a definition file")
+	}
+}
+
+func TestParseSchemaFileMixedDirectory(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.json": `{"version": "1.0", "definitions": {"A": {"type": "int", "value": 1}}}`,
+		"b.yaml": "definitions:\n  B:\n    type: string\n    value: b\n",
+		"c.toml": "[definitions.C]\ntype = \"bool\"\nvalue = true\n",
+		"d.txt":  "ignored",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	schema, err := ParseSchemaFile(&dir)
+	if err != nil {
+		t.Fatalf("ParseSchemaFile failed: %v", err)
+	}
+	if len(schema.Definitions) != 3 {
+		t.Errorf("Expected 3 definitions, got %v", schema.Definitions)
+	}
+	if schema.Definitions["C"].Value != true {
+		t.Errorf("Expected C to be true, got %#v", schema.Definitions["C"].Value)
+	}
+}
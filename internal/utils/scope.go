@@ -0,0 +1,254 @@
+package utils
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nantokaworks/konst/internal/diag"
+	"github.com/nantokaworks/konst/internal/types"
+)
+
+// Source は依存関係を解決する定義ファイルです
+type Source struct {
+	Path   string        // 定義ファイルのパス
+	ID     string        // 入力ディレクトリからの相対パス（/ 区切り、拡張子なし）。{{ID#Name}} で参照します
+	Schema *types.Schema // 定義ファイルの内容
+}
+
+// NewSource は入力ディレクトリ inputDir の定義ファイル filePath の Source を作成します
+func NewSource(inputDir, filePath string, schema *types.Schema) Source {
+	id := filePath
+	if rel, err := filepath.Rel(inputDir, filePath); err == nil {
+		id = rel
+	}
+	id = filepath.ToSlash(strings.TrimSuffix(id, filepath.Ext(id)))
+	return Source{Path: filePath, ID: id, Schema: schema}
+}
+
+// Symbol は定義ファイルの中の定義です
+type Symbol struct {
+	File string // 定義ファイルのパス
+	Name string // 定義名
+}
+
+// Scope は定義ファイルごとの定義名を管理し、{{参照}} を参照先の定義に解決します。
+// 定義名は定義ファイルごとに独立しており、他の定義ファイルと同じ名前の定義を持てます。
+type Scope struct {
+	sources []Source
+	files   map[string]*Source  // 定義ファイルのパス → 定義ファイル
+	owners  map[string][]string // 定義名 → 定義している定義ファイル（読み込み順）
+}
+
+// NewScope は定義ファイルの一覧から Scope を作成します
+func NewScope(sources []Source) *Scope {
+	s := &Scope{sources: sources, files: make(map[string]*Source), owners: make(map[string][]string)}
+	for i := range s.sources {
+		src := &s.sources[i]
+		s.files[src.Path] = src
+		for _, name := range sortedNames(src.Schema.Definitions) {
+			s.owners[name] = append(s.owners[name], src.Path)
+		}
+	}
+	return s
+}
+
+// Sources は定義ファイルを読み込み順に返します
+func (s *Scope) Sources() []Source {
+	return s.sources
+}
+
+// Definition は定義ファイルの定義を返します
+func (s *Scope) Definition(sym Symbol) (types.Definition, bool) {
+	src, ok := s.files[sym.File]
+	if !ok {
+		return types.Definition{}, false
+	}
+	def, ok := src.Schema.Definitions[sym.Name]
+	return def, ok
+}
+
+// referenceError は参照先を決められないエラーです
+type referenceError struct {
+	code diag.Code
+	msg  string
+}
+
+func (e *referenceError) Error() string { return e.msg }
+
+// Lookup は定義ファイル file の値に書かれた参照 ref の参照先を返します。
+//   - Name: 同じ定義ファイルの定義、なければ Name を定義している唯一の定義ファイルの定義
+//   - pkg.Name: goPackage またはファイル名（拡張子なし）が pkg の定義ファイルの定義
+//   - path/to/file#Name: 入力ディレクトリからの相対パスが path/to/file（拡張子は省略可）の定義ファイルの定義
+//
+// 参照先が見つからない、または複数の定義ファイルにあって決められない場合はエラーを返します。
+func (s *Scope) Lookup(file, ref string) (Symbol, error) {
+	if i := strings.LastIndex(ref, "#"); i >= 0 {
+		return s.lookupFile(ref, ref[:i], ref[i+1:])
+	}
+	if i := strings.Index(ref, "."); i >= 0 {
+		return s.lookupNamespace(ref, ref[:i], ref[i+1:])
+	}
+	if src, ok := s.files[file]; ok {
+		if _, ok := src.Schema.Definitions[ref]; ok {
+			return Symbol{File: file, Name: ref}, nil
+		}
+	}
+	switch owners := s.owners[ref]; len(owners) {
+	case 0:
+		return Symbol{}, undefinedReferenceError(ref, ref, "", s.names())
+	case 1:
+		return Symbol{File: owners[0], Name: ref}, nil
+	default:
+		return Symbol{}, s.ambiguousReferenceError(ref, ref, owners)
+	}
+}
+
+// lookupFile は path/to/file#Name の参照先を返します
+func (s *Scope) lookupFile(ref, id, name string) (Symbol, error) {
+	for _, src := range s.sources {
+		if src.ID != id && src.ID+filepath.Ext(src.Path) != id {
+			continue
+		}
+		if _, ok := src.Schema.Definitions[name]; !ok {
+			return Symbol{}, undefinedReferenceError(ref, name, id+"#", sortedNames(src.Schema.Definitions))
+		}
+		return Symbol{File: src.Path, Name: name}, nil
+	}
+	return Symbol{}, &referenceError{diag.CodeUndefinedReference, fmt.Sprintf("undefined reference {{%s}}: no definition file %q", ref, id)}
+}
+
+// lookupNamespace は pkg.Name の参照先を返します
+func (s *Scope) lookupNamespace(ref, namespace, name string) (Symbol, error) {
+	var owners, names []string
+	found := false
+	for _, src := range s.sources {
+		if src.Schema.GoPackage != namespace && path.Base(src.ID) != namespace {
+			continue
+		}
+		found = true
+		names = append(names, sortedNames(src.Schema.Definitions)...)
+		if _, ok := src.Schema.Definitions[name]; ok {
+			owners = append(owners, src.Path)
+		}
+	}
+	switch {
+	case !found:
+		return Symbol{}, &referenceError{diag.CodeUndefinedReference, fmt.Sprintf("undefined reference {{%s}}: no Go package or definition file named %q", ref, namespace)}
+	case len(owners) == 0:
+		return Symbol{}, undefinedReferenceError(ref, name, namespace+".", names)
+	case len(owners) > 1:
+		return Symbol{}, s.ambiguousReferenceError(ref, name, owners)
+	}
+	return Symbol{File: owners[0], Name: name}, nil
+}
+
+// undefinedReferenceError は見つからない参照のエラーを作成します。
+// 定義名の打ち間違いとみなせる候補があれば、参照と同じ修飾 qualifier を付けて "did you mean" として示します。
+func undefinedReferenceError(ref, name, qualifier string, candidates []string) error {
+	if suggestion := Suggest(name, candidates); suggestion != "" {
+		return &referenceError{diag.CodeUndefinedReference, fmt.Sprintf("undefined reference {{%s}} (did you mean {{%s%s}}?)", ref, qualifier, suggestion)}
+	}
+	return &referenceError{diag.CodeUndefinedReference, fmt.Sprintf("undefined reference {{%s}}", ref)}
+}
+
+// ambiguousReferenceError は複数の定義ファイルにある定義名の参照のエラーを作成します
+func (s *Scope) ambiguousReferenceError(ref, name string, owners []string) error {
+	qualified := make([]string, len(owners))
+	for i, owner := range owners {
+		qualified[i] = fmt.Sprintf("{{%s#%s}}", s.files[owner].ID, name)
+	}
+	return &referenceError{diag.CodeAmbiguousReference, fmt.Sprintf("ambiguous reference {{%s}}: %s is defined in %s (use %s)",
+		ref, name, strings.Join(owners, ", "), strings.Join(qualified, " or "))}
+}
+
+// names は全ての定義ファイルの定義名を名前順に返します
+func (s *Scope) names() []string {
+	names := make([]string, 0, len(s.owners))
+	for name := range s.owners {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Duplicates は複数の定義ファイルにある定義名と、生成コードで衝突する識別子を診断として返します。
+// namespace は定義ファイルから生成するコードの名前空間（Go のパッケージなど）を返します。
+// 同じ名前空間に生成される定義ファイルどうしの同じ名前の定義は生成コードで衝突するためエラーにし、
+// 別の名前空間であれば参照の修飾が必要になることを警告します。
+// 後に読み込んだ定義ファイルの定義の位置に、先に定義している定義ファイルを示します。
+//
+// identifiers は定義から生成する識別子（定義名と、XRows や IsValidX など定義名から作る識別子）を返します。
+// 同じ名前空間で別の定義と同じ識別子を生成する定義もエラーにします（nil の場合は定義名だけを比べます）。
+func (s *Scope) Duplicates(namespace func(Source) string, identifiers func(name string, def types.Definition) []string) diag.List {
+	var issues diag.List
+	for _, name := range s.names() {
+		owners := s.owners[name]
+		for i := 1; i < len(owners); i++ {
+			src := s.files[owners[i]]
+			def := src.Schema.Definitions[name]
+			ns := namespace(*src)
+			var conflicts []string
+			for _, owner := range owners[:i] {
+				if namespace(*s.files[owner]) == ns {
+					conflicts = append(conflicts, owner)
+				}
+			}
+			if len(conflicts) > 0 {
+				issues = append(issues, diag.New(diag.CodeDuplicateDefinition, def.Pos, name,
+					"also defined in %s, which generates code into the same namespace %s", strings.Join(conflicts, ", "), ns))
+				continue
+			}
+			issues = append(issues, diag.NewWarning(diag.CodeDuplicateDefinition, def.Pos, name,
+				"also defined in %s; references from other files must be qualified", strings.Join(owners[:i], ", ")))
+		}
+	}
+	if identifiers != nil {
+		issues = append(issues, s.identifierConflicts(namespace, identifiers)...)
+	}
+	issues.Sort()
+	return issues
+}
+
+// identifierConflicts は同じ名前空間で別の定義と同じ識別子を生成する定義を診断として返します。
+// 同じ名前の定義どうしの衝突は Duplicates が定義名で報告するため対象にしません。
+func (s *Scope) identifierConflicts(namespace func(Source) string, identifiers func(name string, def types.Definition) []string) diag.List {
+	type declaration struct {
+		file, name string
+	}
+	declared := make(map[string]map[string]declaration) // 名前空間 → 識別子 → 最初に生成する定義
+	var issues diag.List
+	for _, src := range s.sources {
+		ns := namespace(src)
+		if declared[ns] == nil {
+			declared[ns] = make(map[string]declaration)
+		}
+		for _, name := range sortedNames(src.Schema.Definitions) {
+			def := src.Schema.Definitions[name]
+			for _, id := range identifiers(name, def) {
+				first, exists := declared[ns][id]
+				if !exists {
+					declared[ns][id] = declaration{src.Path, name}
+					continue
+				}
+				if first.name != name {
+					issues = append(issues, diag.New(diag.CodeDuplicateDefinition, def.Pos, name,
+						"generates %s, which is also generated for %s in %s", id, first.name, first.file))
+				}
+			}
+		}
+	}
+	return issues
+}
+
+// sortedNames は定義名を名前順に返します
+func sortedNames(definitions map[string]types.Definition) []string {
+	names := make([]string, 0, len(definitions))
+	for name := range definitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,165 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/nantokaworks/konst/internal/diag"
+	"github.com/nantokaworks/konst/internal/types"
+)
+
+// scopeSources は同じ名前の定義を持つ定義ファイルです
+func scopeSources() []Source {
+	intDef := func(value string, line int, file string) types.Definition {
+		return types.Definition{Type: types.DefinitionTypeInt, Value: json.Number(value), Pos: types.Position{File: file, Line: line, Column: 5}}
+	}
+	return []Source{
+		NewSource("defs", "defs/network/network.json", &types.Schema{GoPackage: "network", Definitions: map[string]types.Definition{
+			"MaxRetries": intDef("5", 4, "defs/network/network.json"),
+		}}),
+		NewSource("defs", "defs/limits/retry-policy.yaml", &types.Schema{GoPackage: "limits", Definitions: map[string]types.Definition{
+			"MaxRetries": intDef("2", 4, "defs/limits/retry-policy.yaml"),
+			"Backoff":    intDef("100", 5, "defs/limits/retry-policy.yaml"),
+		}}),
+		NewSource("defs", "defs/app.json", &types.Schema{GoPackage: "app", Definitions: map[string]types.Definition{
+			"Timeout": intDef("30", 4, "defs/app.json"),
+		}}),
+	}
+}
+
+func TestScopeLookup(t *testing.T) {
+	scope := NewScope(scopeSources())
+	tests := []struct {
+		file     string
+		ref      string
+		expected Symbol
+		err      string
+	}{
+		{"defs/app.json", "Timeout", Symbol{File: "defs/app.json", Name: "Timeout"}, ""},
+		{"defs/network/network.json", "MaxRetries", Symbol{File: "defs/network/network.json", Name: "MaxRetries"}, ""},
+		{"defs/app.json", "Backoff", Symbol{File: "defs/limits/retry-policy.yaml", Name: "Backoff"}, ""},
+		{"defs/app.json", "network.MaxRetries", Symbol{File: "defs/network/network.json", Name: "MaxRetries"}, ""},
+		{"defs/app.json", "retry-policy.MaxRetries", Symbol{File: "defs/limits/retry-policy.yaml", Name: "MaxRetries"}, ""},
+		{"defs/app.json", "limits/retry-policy#MaxRetries", Symbol{File: "defs/limits/retry-policy.yaml", Name: "MaxRetries"}, ""},
+		{"defs/app.json", "limits/retry-policy.yaml#MaxRetries", Symbol{File: "defs/limits/retry-policy.yaml", Name: "MaxRetries"}, ""},
+		{"defs/app.json", "MaxRetries", Symbol{}, "ambiguous reference {{MaxRetries}}: MaxRetries is defined in defs/network/network.json, defs/limits/retry-policy.yaml (use {{network/network#MaxRetries}} or {{limits/retry-policy#MaxRetries}})"},
+		{"defs/app.json", "Timeot", Symbol{}, "undefined reference {{Timeot}} (did you mean {{Timeout}}?)"},
+		{"defs/app.json", "limits.MaxRetrys", Symbol{}, "undefined reference {{limits.MaxRetrys}} (did you mean {{limits.MaxRetries}}?)"},
+		{"defs/app.json", "network#MaxRetries", Symbol{}, `undefined reference {{network#MaxRetries}}: no definition file "network"`},
+		{"defs/app.json", "storage.MaxRetries", Symbol{}, `undefined reference {{storage.MaxRetries}}: no Go package or definition file named "storage"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			sym, err := scope.Lookup(tt.file, tt.ref)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("Lookup(%q) error = %v, expected %q", tt.ref, err, tt.err)
+				}
+				return
+			}
+			if err != nil || sym != tt.expected {
+				t.Errorf("Lookup(%q) = %v, %v, expected %v", tt.ref, sym, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestScopeDuplicates(t *testing.T) {
+	byPackage := func(src Source) string { return src.Schema.GoPackage }
+	shared := func(Source) string { return "index.ts" }
+	tests := []struct {
+		name      string
+		namespace func(Source) string
+		severity  diag.Severity
+		message   string
+	}{
+		{"Different namespaces", byPackage, diag.SeverityWarning,
+			"defs/limits/retry-policy.yaml:4:5: warning: MaxRetries: also defined in defs/network/network.json; references from other files must be qualified"},
+		{"Same namespace", shared, diag.SeverityError,
+			"defs/limits/retry-policy.yaml:4:5: MaxRetries: also defined in defs/network/network.json, which generates code into the same namespace index.ts"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := NewScope(scopeSources()).Duplicates(tt.namespace, nil)
+			if len(issues) != 1 {
+				t.Fatalf("Expected 1 issue, got %v", issues)
+			}
+			d := issues[0]
+			if d.Code != diag.CodeDuplicateDefinition || d.Severity != tt.severity || d.Name != "MaxRetries" || d.Error() != tt.message {
+				t.Errorf("Unexpected issue: %s (%s, %s)", d.Error(), d.Code, d.Severity)
+			}
+		})
+	}
+}
+
+func TestScopeDuplicateIdentifiers(t *testing.T) {
+	byPackage := func(src Source) string { return src.Schema.GoPackage }
+	// MaxRetries から MaxRetriesDefault も生成するテンプレート
+	identifiers := func(name string, def types.Definition) []string {
+		if name == "MaxRetries" {
+			return []string{name, name + "Default"}
+		}
+		return []string{name}
+	}
+	sources := scopeSources()
+	sources[1].Schema.Definitions["MaxRetriesDefault"] = types.Definition{Type: types.DefinitionTypeInt, Value: json.Number("3"), Pos: types.Position{File: "defs/limits/retry-policy.yaml", Line: 6, Column: 5}}
+	sources[2].Schema.Definitions["MaxRetriesDefault"] = types.Definition{Type: types.DefinitionTypeInt, Value: json.Number("3"), Pos: types.Position{File: "defs/app.json", Line: 5, Column: 5}}
+
+	issues := NewScope(sources).Duplicates(byPackage, identifiers)
+	var errs []string
+	for _, d := range issues {
+		if d.Severity == diag.SeverityError {
+			errs = append(errs, d.Error())
+		}
+	}
+	// 別の名前空間（app）の MaxRetriesDefault は衝突しない
+	expected := []string{"defs/limits/retry-policy.yaml:6:5: MaxRetriesDefault: generates MaxRetriesDefault, which is also generated for MaxRetries in defs/limits/retry-policy.yaml"}
+	if len(errs) != len(expected) || errs[0] != expected[0] {
+		t.Errorf("Duplicates() errors = %q, expected %q", errs, expected)
+	}
+}
+
+func TestResolveScope(t *testing.T) {
+	sources := scopeSources()
+	sources[2].Schema.Definitions["MaxRetries"] = types.Definition{Type: types.DefinitionTypeInt, Value: "{{network.MaxRetries}} + {{limits/retry-policy#MaxRetries}}"}
+	sources[2].Schema.Definitions["Total"] = types.Definition{Type: types.DefinitionTypeInt, Value: "{{MaxRetries}} * {{Backoff}}"}
+
+	resolution, _, err := ResolveScope(NewScope(sources))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// 同じ名前の定義は定義ファイルごとの値のまま
+	expected := map[string]map[string]string{
+		"defs/network/network.json":     {"MaxRetries": "5"},
+		"defs/limits/retry-policy.yaml": {"MaxRetries": "2"},
+		"defs/app.json":                 {"MaxRetries": "7", "Total": "700"},
+	}
+	for file, defs := range expected {
+		for name, value := range defs {
+			if v := resolution.Definitions[file][name].Value; v != json.Number(value) {
+				t.Errorf("%s %s = %#v, expected %s", file, name, v, value)
+			}
+		}
+	}
+
+	// 依存関係は参照の出現順
+	deps := resolution.Dependencies[Symbol{File: "defs/app.json", Name: "MaxRetries"}]
+	expectedDeps := []Symbol{{File: "defs/network/network.json", Name: "MaxRetries"}, {File: "defs/limits/retry-policy.yaml", Name: "MaxRetries"}}
+	if len(deps) != len(expectedDeps) || deps[0] != expectedDeps[0] || deps[1] != expectedDeps[1] {
+		t.Errorf("Dependencies = %v, expected %v", deps, expectedDeps)
+	}
+}
+
+func TestResolveScopeAmbiguousReference(t *testing.T) {
+	sources := scopeSources()
+	sources[2].Schema.Definitions["Total"] = types.Definition{Type: types.DefinitionTypeInt, Value: "{{MaxRetries}} * 2"}
+
+	_, _, err := ResolveScope(NewScope(sources))
+	var d *diag.Diagnostic
+	if !errors.As(err, &d) || d.Code != diag.CodeAmbiguousReference || d.Name != "Total" {
+		t.Errorf("Expected ambiguous-reference diagnostic for Total, got %#v", err)
+	}
+}
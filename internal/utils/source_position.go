@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/nantokaworks/konst/internal/diag"
+	"github.com/nantokaworks/konst/internal/types"
+)
+
+// jsonFrame はトークン走査中のコンテナ（オブジェクトまたは配列）の状態です
+type jsonFrame struct {
+	object    bool   // オブジェクトなら true、配列なら false
+	expectKey bool   // 次のトークンがキーかどうか
+	key       string // 直前に読んだキー
+}
+
+// schemaKeyPositions は JSON を走査し、トップレベルのキーと definitions 直下のキーの位置を返します。
+// 位置はキー文字列の開始位置です。
+func schemaKeyPositions(file string, data []byte) (top, defs map[string]types.Position, err error) {
+	topOffsets, defOffsets, err := schemaKeyOffsets(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	posAt := func(offset int64) types.Position {
+		return diag.PositionAt(file, data, offset)
+	}
+	return offsetsToPositions(topOffsets, posAt), offsetsToPositions(defOffsets, posAt), nil
+}
+
+// offsetsToPositions はキーごとのオフセットを位置に変換します
+func offsetsToPositions(offsets map[string]int64, posAt func(int64) types.Position) map[string]types.Position {
+	positions := make(map[string]types.Position, len(offsets))
+	for key, offset := range offsets {
+		positions[key] = posAt(offset)
+	}
+	return positions
+}
+
+// schemaKeyOffsets は JSON を走査し、トップレベルのキーと definitions 直下のキーの開始オフセットを返します
+func schemaKeyOffsets(data []byte) (top, defs map[string]int64, err error) {
+	top = make(map[string]int64)
+	defs = make(map[string]int64)
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	var stack []*jsonFrame
+	for {
+		start := skipSeparators(data, dec.InputOffset())
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return top, defs, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var parent *jsonFrame
+		if len(stack) > 0 {
+			parent = stack[len(stack)-1]
+		}
+
+		if delim, ok := tok.(json.Delim); ok {
+			switch delim {
+			case '{':
+				stack = append(stack, &jsonFrame{object: true, expectKey: true})
+			case '[':
+				stack = append(stack, &jsonFrame{})
+			default:
+				stack = stack[:len(stack)-1]
+				if len(stack) > 0 && stack[len(stack)-1].object {
+					stack[len(stack)-1].expectKey = true
+				}
+			}
+			continue
+		}
+
+		if parent == nil || !parent.object {
+			continue
+		}
+		if !parent.expectKey {
+			// スカラー値を読んだので次はキー
+			parent.expectKey = true
+			continue
+		}
+
+		key, _ := tok.(string)
+		parent.key = key
+		parent.expectKey = false
+		switch {
+		case len(stack) == 1:
+			top[key] = start
+		case len(stack) == 2 && stack[0].key == "definitions":
+			defs[key] = start
+		}
+	}
+}
+
+// skipSeparators は空白・カンマ・コロンを読み飛ばした次のトークンの開始位置を返します
+func skipSeparators(data []byte, offset int64) int64 {
+	for offset < int64(len(data)) {
+		switch data[offset] {
+		case ' ', '\t', '\r', '\n', ',', ':':
+			offset++
+		default:
+			return offset
+		}
+	}
+	return offset
+}
@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"sort"
+	"strings"
+)
+
+// Suggest は name に最も近い候補を編集距離で探し、打ち間違いとみなせる距離（名前の長さの 1/3 まで、最低 1）の候補を返します。
+// 大文字・小文字だけが異なる候補を最優先し、距離が同じ候補は名前順で先のものを選びます。見つからない場合は空文字列を返します。
+func Suggest(name string, candidates []string) string {
+	sorted := append([]string(nil), candidates...)
+	sort.Strings(sorted)
+
+	best, bestDistance := "", len(name)/3
+	if bestDistance < 1 {
+		bestDistance = 1
+	}
+	for _, c := range sorted {
+		if c == name {
+			continue
+		}
+		if strings.EqualFold(c, name) {
+			return c
+		}
+		if d := editDistance(name, c); d <= bestDistance && (best == "" || d < editDistance(name, best)) {
+			best = c
+		}
+	}
+	return best
+}
+
+// editDistance は2つの文字列のレーベンシュタイン距離（文字単位）を返します
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,44 @@
+package utils
+
+import "testing"
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"MaxRetries", "BaseRetries", "ApiTimeout", "Port", "Host"}
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"MaxRetires", "MaxRetries"},
+		{"maxretries", "MaxRetries"},
+		{"BaseRetry", "BaseRetries"},
+		{"APITimeout", "ApiTimeout"},
+		{"Prt", "Port"},
+		{"Post", "Host"}, // Host と Port は同じ距離なので名前順で先の Host
+		{"Timeout", ""},
+		{"X", ""},
+	}
+
+	for _, tt := range tests {
+		if result := Suggest(tt.name, candidates); result != tt.expected {
+			t.Errorf("Suggest(%q) = %q, expected %q", tt.name, result, tt.expected)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+		{"MaxRetries", "MaxRetires", 2},
+		{"日本語", "日本", 1},
+	}
+
+	for _, tt := range tests {
+		if result := editDistance(tt.a, tt.b); result != tt.expected {
+			t.Errorf("editDistance(%q, %q) = %d, expected %d", tt.a, tt.b, result, tt.expected)
+		}
+	}
+}
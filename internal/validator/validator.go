@@ -0,0 +1,641 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/nantokaworks/konst/internal/diag"
+	"github.com/nantokaworks/konst/internal/types"
+	"github.com/nantokaworks/konst/internal/utils"
+)
+
+var (
+	identifierPattern  = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	placeholderPattern = regexp.MustCompile(`%([^%\s]+)%`)
+	referencePattern   = regexp.MustCompile(`\{\{[^}]+\}\}`)
+)
+
+// scalarTypes は value を持つスカラー型の一覧です
+var scalarTypes = map[types.DefinitionType]bool{
+	types.DefinitionTypeInt:       true,
+	types.DefinitionTypeInt32:     true,
+	types.DefinitionTypeInt64:     true,
+	types.DefinitionTypeUint:      true,
+	types.DefinitionTypeUint32:    true,
+	types.DefinitionTypeUint64:    true,
+	types.DefinitionTypeFloat:     true,
+	types.DefinitionTypeFloat32:   true,
+	types.DefinitionTypeFloat64:   true,
+	types.DefinitionTypeString:    true,
+	types.DefinitionTypeBool:      true,
+	types.DefinitionTypeDate:      true,
+	types.DefinitionTypeTimestamp: true,
+	types.DefinitionTypeDuration:  true,
+}
+
+var tsModes = map[types.TSMode]bool{
+	types.ModeNumber:       true,
+	types.ModeBigInt:       true,
+	types.ModeString:       true,
+	types.ModeDate:         true,
+	types.ModeTimestamp:    true,
+	types.ModeMilliseconds: true,
+	types.ModeSeconds:      true,
+}
+
+var goModes = map[types.GoMode]bool{
+	types.GoModeInt:       true,
+	types.GoModeInt64:     true,
+	types.GoModeTime:      true,
+	types.GoModeString:    true,
+	types.GoModeTimestamp: true,
+}
+
+// ValidateSchema は1ファイル分のスキーマを検証し、見つかった全ての問題を返します。
+// 問題は定義ファイル内の位置順（同じ位置の場合は定義名順）に並べて返します。
+func ValidateSchema(schema *types.Schema) diag.List {
+	var list diag.List
+	if schema.GoPackage != "" && !identifierPattern.MatchString(schema.GoPackage) {
+		list = append(list, diag.New(diag.CodeInvalidName, schema.KeyPos("goPackage"), "", "invalid goPackage %q", schema.GoPackage))
+	}
+	for _, i := range schema.GoEnumInterfaces {
+		if !i.IsKnown() {
+			list = append(list, diag.New(diag.CodeUnknownMode, schema.KeyPos("goEnumInterfaces"), "", "unknown goEnumInterfaces entry %q (expected stringer, json, text or sql)", i))
+		}
+	}
+
+	names := make([]string, 0, len(schema.Definitions))
+	for name := range schema.Definitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		list = append(list, ValidateDefinition(name, schema.Definitions[name])...)
+	}
+	list.Sort()
+	return list
+}
+
+// checker は1つの定義の検証結果を集めます
+type checker struct {
+	name string
+	def  types.Definition
+	list diag.List
+}
+
+// errorf は定義の位置にエラー診断を追加します
+func (c *checker) errorf(code diag.Code, format string, args ...any) {
+	c.list = append(c.list, diag.New(code, c.def.Pos, c.name, format, args...))
+}
+
+// ValidateDefinition は1つの定義を宣言された型に照らして検証し、問題の一覧を返します。
+func ValidateDefinition(name string, def types.Definition) diag.List {
+	c := &checker{name: name, def: def}
+	if !identifierPattern.MatchString(name) {
+		c.errorf(diag.CodeInvalidName, "definition name %q is not a valid identifier", name)
+	}
+
+	if def.TSMode != "" && !tsModes[def.TSMode] {
+		c.errorf(diag.CodeUnknownMode, "unknown tsMode %q", def.TSMode)
+	} else if def.Type == types.DefinitionTypeMap {
+		if msg := checkDurationMode(def.ValueType, def.TSMode); msg != "" {
+			c.errorf(diag.CodeUnknownMode, "%s", msg)
+		}
+	} else if msg := checkDurationMode(def.Type, def.TSMode); msg != "" {
+		c.errorf(diag.CodeUnknownMode, "%s", msg)
+	}
+	if def.GoMode != "" && !goModes[def.GoMode] {
+		c.errorf(diag.CodeUnknownMode, "unknown goMode %q", def.GoMode)
+	}
+
+	if len(def.ValueDescriptions) > 0 && def.Type != types.DefinitionTypeEnum {
+		c.errorf(diag.CodeInvalidEnum, "valueDescriptions can only be used with enum definitions")
+	}
+	if len(def.Fields) > 0 && def.Type != types.DefinitionTypeObject {
+		c.errorf(diag.CodeInvalidObject, "fields can only be used with object definitions")
+	}
+	if (len(def.Columns) > 0 || len(def.Rows) > 0) && def.Type != types.DefinitionTypeTable {
+		c.errorf(diag.CodeInvalidObject, "columns and rows can only be used with table definitions")
+	}
+	if (def.KeyType != "" || def.ValueType != "") && def.Type != types.DefinitionTypeMap {
+		c.errorf(diag.CodeInvalidMap, "keyType and valueType can only be used with map definitions")
+	}
+
+	switch {
+	case def.Type == "":
+		c.errorf(diag.CodeMissingField, "type is required")
+	case def.Type == types.DefinitionTypeEnum:
+		c.validateEnum()
+	case def.Type == types.DefinitionTypeTemplate:
+		c.validateTemplate()
+	case def.Type == types.DefinitionTypeObject:
+		c.validateObject()
+	case def.Type == types.DefinitionTypeTable:
+		c.validateTable()
+	case def.Type == types.DefinitionTypeMap:
+		c.validateMap()
+	case scalarTypes[def.Type]:
+		if def.Value == nil {
+			c.errorf(diag.CodeMissingField, "value is required")
+		} else if s, ok := def.Value.(string); ok && utils.HasReference(s) {
+			if err := utils.CheckExpression(s, def.Type); err != nil {
+				c.errorf(diag.CodeInvalidExpression, "%v", err)
+			}
+		} else if msg := checkValue(def.Type, def.Value); msg != "" {
+			c.errorf(diag.CodeValueType, "%s", msg)
+		}
+	case strings.HasSuffix(string(def.Type), "[]"):
+		c.validateArray()
+	default:
+		c.errorf(diag.CodeUnknownType, "unknown type %q", def.Type)
+	}
+	return c.list
+}
+
+// validateEnum は enum 型の values と default を検証します。
+// 値から作る定数名（utils.EnumMemberName）が識別子になり、値どうしで重複しないことも確認します。
+// 整数の enum では全ての値が int32 の範囲の整数を持ち、番号が重複しないことも確認します（番号の飛びは許可します）。
+func (c *checker) validateEnum() {
+	if len(c.def.Values) == 0 {
+		c.errorf(diag.CodeInvalidEnum, "enum must have at least one value")
+	}
+	isInt := c.def.IsIntEnum()
+	seen := make(map[string]bool)
+	constants := make(map[string]string) // 定数名 → 名前
+	numbers := make(map[string]string)   // 整数値 → 名前
+	for _, v := range c.def.Values {
+		if v.Name == "" {
+			c.errorf(diag.CodeInvalidEnum, "enum value must not be empty")
+			continue
+		}
+		validName := !isInt || identifierPattern.MatchString(v.Name)
+		constant := utils.EnumMemberName(v.Name)
+		switch {
+		case seen[v.Name]:
+			c.errorf(diag.CodeInvalidEnum, "duplicate enum value %q", v.Name)
+		case !validName:
+			c.errorf(diag.CodeInvalidEnum, "enum value name %q is not a valid identifier", v.Name)
+		case constant == "" || unicode.IsDigit([]rune(constant)[0]):
+			c.errorf(diag.CodeInvalidEnum, "enum value %q cannot be used in a constant name (it must start with a letter or _ once symbols are removed)", v.Name)
+		case constants[constant] != "":
+			c.errorf(diag.CodeInvalidEnum, "enum value %q has the same constant name %s as %q", v.Name, constant, constants[constant])
+		default:
+			constants[constant] = v.Name
+		}
+		seen[v.Name] = true
+
+		if !isInt {
+			continue
+		}
+		if !v.IsInt() {
+			c.errorf(diag.CodeInvalidEnum, "enum value %q has no integer value (all values of an integer enum need one)", v.Name)
+			continue
+		}
+		if err := utils.CheckNumber(types.DefinitionTypeInt32, v.Value); err != nil {
+			c.errorf(diag.CodeInvalidEnum, "enum value %q: %v", v.Name, err)
+			continue
+		}
+		literal, _ := utils.IntegerLiteral(v.Value)
+		if other, ok := numbers[literal]; ok {
+			c.errorf(diag.CodeInvalidEnum, "enum value %q has the same number %s as %q", v.Name, literal, other)
+		}
+		numbers[literal] = v.Name
+	}
+	if c.def.Default != "" && !seen[c.def.Default] {
+		c.errorf(diag.CodeInvalidEnum, "default %q is not one of the enum values", c.def.Default)
+	}
+	for _, v := range sortedStringKeys(c.def.ValueDescriptions) {
+		if !seen[v] {
+			c.errorf(diag.CodeInvalidEnum, "valueDescriptions has description for unknown enum value %q", v)
+		}
+	}
+}
+
+// validateTemplate は template 型のプレースホルダーと parameters の対応を検証します
+func (c *checker) validateTemplate() {
+	if c.def.Template == "" {
+		c.errorf(diag.CodeMissingField, "template is required")
+		return
+	}
+
+	declared := make(map[string]bool)
+	for _, p := range c.def.Parameters {
+		if declared[p] {
+			c.errorf(diag.CodeInvalidTemplate, "duplicate parameter %q", p)
+		}
+		declared[p] = true
+	}
+
+	used := make(map[string]bool)
+	for _, m := range placeholderPattern.FindAllStringSubmatch(c.def.Template, -1) {
+		used[m[1]] = true
+	}
+
+	for _, p := range sortedKeys(used) {
+		if !declared[p] {
+			c.errorf(diag.CodeInvalidTemplate, "placeholder %%%s%% is not declared in parameters", p)
+		}
+	}
+	for _, p := range c.def.Parameters {
+		if !used[p] {
+			c.errorf(diag.CodeInvalidTemplate, "parameter %q is not used in template", p)
+		}
+	}
+}
+
+// validateArray は配列型の要素型と各要素を検証します
+func (c *checker) validateArray() {
+	baseType := types.DefinitionType(strings.TrimSuffix(string(c.def.Type), "[]"))
+	if !scalarTypes[baseType] {
+		c.errorf(diag.CodeUnknownType, "unknown type %q", c.def.Type)
+		return
+	}
+	if c.def.Value == nil {
+		c.errorf(diag.CodeMissingField, "value is required")
+		return
+	}
+	for _, msg := range checkArray(c.def.Type, c.def.Value) {
+		c.errorf(diag.CodeValueType, "%s", msg)
+	}
+}
+
+// validateObject は object 型のフィールド定義と値を検証します
+func (c *checker) validateObject() {
+	c.checkFields("", c.def.Fields, false)
+	if c.def.Value == nil {
+		c.errorf(diag.CodeMissingField, "value is required")
+		return
+	}
+	obj, ok := c.def.Value.(map[string]interface{})
+	if !ok {
+		c.errorf(diag.CodeValueType, "value must be an object for type %q, got %s", c.def.Type, jsonTypeName(c.def.Value))
+		return
+	}
+	c.checkObjectValue("", c.def.Fields, obj)
+}
+
+// validateTable は table 型の列定義と各行の値を検証し、キー列の値が重複していないことを確認します
+func (c *checker) validateTable() {
+	c.checkFields("", c.def.Columns, true)
+	for i, row := range c.def.Rows {
+		c.checkObjectValue(fmt.Sprintf("rows[%d].", i), c.def.Columns, row)
+	}
+
+	for _, col := range c.def.Columns {
+		if !col.Key || !isKeyType(col.Type) {
+			continue
+		}
+		seen := make(map[string]int) // キーの値 → 行番号
+		for i, row := range c.def.Rows {
+			key, ok := keyString(row[col.Name])
+			if !ok {
+				continue // 型の誤りは checkObjectValue が報告する
+			}
+			if first, ok := seen[key]; ok {
+				c.errorf(diag.CodeDuplicateKey, "key column %q has duplicate value %s in rows %d and %d", col.Name, key, first, i)
+				continue
+			}
+			seen[key] = i
+		}
+	}
+}
+
+// validateMap は map 型のキーと値の型、各エントリーを検証します。
+// キーが enum の定義を参照している場合、enum のメンバーとの対応は依存関係の解決時に検証します。
+func (c *checker) validateMap() {
+	keyType := c.def.KeyType
+	switch {
+	case keyType == "":
+		c.errorf(diag.CodeMissingField, "keyType is required")
+	case !isKeyType(keyType) && !identifierPattern.MatchString(string(keyType)):
+		c.errorf(diag.CodeUnknownType, "unknown keyType %q (expected string, an integer type or an enum definition name)", keyType)
+	}
+	valueOK := false
+	switch {
+	case c.def.ValueType == "":
+		c.errorf(diag.CodeMissingField, "valueType is required")
+	case !isFieldType(c.def.ValueType):
+		c.errorf(diag.CodeUnknownType, "unknown valueType %q", c.def.ValueType)
+	default:
+		valueOK = true
+	}
+
+	if c.def.Value == nil {
+		c.errorf(diag.CodeMissingField, "value is required")
+		return
+	}
+	entries, ok := c.def.Value.(map[string]interface{})
+	if !ok {
+		c.errorf(diag.CodeValueType, "value must be an object for type %q, got %s", c.def.Type, jsonTypeName(c.def.Value))
+		return
+	}
+
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	integerKeys := make(map[string]string) // 整数値 → キー（1 と 1.0 のような重複の検出用）
+	for _, key := range keys {
+		if isKeyType(keyType) && keyType != types.DefinitionTypeString {
+			n := json.Number(key)
+			if !utils.IsNumberLiteral(key) {
+				c.errorf(diag.CodeInvalidMap, "key %q is not a number for keyType %q", key, keyType)
+			} else if err := utils.CheckNumber(keyType, n); err != nil {
+				c.errorf(diag.CodeInvalidMap, "key %q: %v", key, err)
+			} else {
+				literal, _ := utils.IntegerLiteral(n)
+				if other, ok := integerKeys[literal]; ok {
+					c.errorf(diag.CodeInvalidMap, "key %q is the same number as %q", key, other)
+				}
+				integerKeys[literal] = key
+			}
+		}
+		if !valueOK {
+			continue
+		}
+
+		value := entries[key]
+		if s, ok := value.(string); ok && referencePattern.MatchString(s) {
+			c.errorf(diag.CodeValueType, "key %q: references are not supported in map values", key)
+			continue
+		}
+		if scalarTypes[c.def.ValueType] {
+			if msg := checkValue(c.def.ValueType, value); msg != "" {
+				c.errorf(diag.CodeValueType, "key %q: %s", key, msg)
+			}
+			continue
+		}
+		for _, msg := range checkArray(c.def.ValueType, value) {
+			c.errorf(diag.CodeValueType, "key %q: %s", key, msg)
+		}
+	}
+}
+
+// isKeyType は table のキー列・map のキーに使える組み込みの型（整数型と文字列型）かどうかを返します
+func isKeyType(t types.DefinitionType) bool {
+	switch t {
+	case types.DefinitionTypeInt, types.DefinitionTypeInt32, types.DefinitionTypeInt64,
+		types.DefinitionTypeUint, types.DefinitionTypeUint32, types.DefinitionTypeUint64,
+		types.DefinitionTypeString:
+		return true
+	}
+	return false
+}
+
+// keyString はキー列の値を重複の比較に使う文字列にします（1 と 1.0 は同じ値として扱います）
+func keyString(value interface{}) (string, bool) {
+	if s, ok := value.(string); ok {
+		return strconv.Quote(s), true
+	}
+	if n, ok := utils.AsNumber(value); ok {
+		return utils.IntegerLiteral(n)
+	}
+	return "", false
+}
+
+// checkFields は object のフィールド・table の列の定義を検証します。path は入れ子のフィールドの接頭辞（"retry." など）です。
+// table が true の場合は key の指定を許可し、キー列の型を確認します。
+func (c *checker) checkFields(path string, fields []types.Field, table bool) {
+	if len(fields) == 0 {
+		if table {
+			c.errorf(diag.CodeMissingField, "table must have at least one column")
+		} else if path == "" {
+			c.errorf(diag.CodeMissingField, "object must have at least one field")
+		} else {
+			c.errorf(diag.CodeMissingField, "field %q: object must have at least one field", strings.TrimSuffix(path, "."))
+		}
+		return
+	}
+
+	goNames := make(map[string]string) // Go のフィールド名 → フィールド名
+	for _, f := range fields {
+		name := path + f.Name
+		if !identifierPattern.MatchString(f.Name) {
+			c.errorf(diag.CodeInvalidName, "field name %q is not a valid identifier", name)
+		} else if other, ok := goNames[utils.ToPascalCase(f.Name)]; ok {
+			c.errorf(diag.CodeInvalidName, "field %q has the same Go name as %q", name, other)
+		} else {
+			goNames[utils.ToPascalCase(f.Name)] = name
+		}
+		if f.TSMode != "" && !tsModes[f.TSMode] {
+			c.errorf(diag.CodeUnknownMode, "field %q: unknown tsMode %q", name, f.TSMode)
+		} else if msg := checkDurationMode(f.Type, f.TSMode); msg != "" {
+			c.errorf(diag.CodeUnknownMode, "field %q: %s", name, msg)
+		}
+		if f.GoMode != "" && !goModes[f.GoMode] {
+			c.errorf(diag.CodeUnknownMode, "field %q: unknown goMode %q", name, f.GoMode)
+		}
+
+		switch {
+		case f.Type == "":
+			c.errorf(diag.CodeMissingField, "field %q: type is required", name)
+		case f.Type == types.DefinitionTypeObject:
+			c.checkFields(name+".", f.Fields, false)
+		case isFieldType(f.Type):
+			if len(f.Fields) > 0 {
+				c.errorf(diag.CodeInvalidObject, "field %q: fields can only be used with object fields", name)
+			}
+		default:
+			c.errorf(diag.CodeUnknownType, "field %q: unknown type %q", name, f.Type)
+		}
+
+		if f.Key && !table {
+			c.errorf(diag.CodeInvalidObject, "field %q: key can only be used with table columns", name)
+		} else if f.Key && !isKeyType(f.Type) {
+			c.errorf(diag.CodeInvalidObject, "key column %q must be an integer or string type, got %q", name, f.Type)
+		}
+	}
+}
+
+// checkObjectValue は object の値が全てのフィールドを持ち、各フィールドの型に合っているかを検証します
+func (c *checker) checkObjectValue(path string, fields []types.Field, value map[string]interface{}) {
+	known := make(map[string]bool)
+	for _, f := range fields {
+		known[f.Name] = true
+		name := path + f.Name
+		v, ok := value[f.Name]
+		if !ok || v == nil {
+			c.errorf(diag.CodeMissingField, "field %q: value is required", name)
+			continue
+		}
+		if s, ok := v.(string); ok && referencePattern.MatchString(s) {
+			c.errorf(diag.CodeValueType, "field %q: references are not supported in object values", name)
+			continue
+		}
+
+		switch {
+		case f.Type == types.DefinitionTypeObject:
+			obj, ok := v.(map[string]interface{})
+			if !ok {
+				c.errorf(diag.CodeValueType, "field %q: value must be an object, got %s", name, jsonTypeName(v))
+				continue
+			}
+			c.checkObjectValue(name+".", f.Fields, obj)
+		case scalarTypes[f.Type]:
+			if msg := checkValue(f.Type, v); msg != "" {
+				c.errorf(diag.CodeValueType, "field %q: %s", name, msg)
+			}
+		case isFieldType(f.Type):
+			for _, msg := range checkArray(f.Type, v) {
+				c.errorf(diag.CodeValueType, "field %q: %s", name, msg)
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(value))
+	for key := range value {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !known[key] {
+			c.errorf(diag.CodeInvalidObject, "field %q is not declared in fields", path+key)
+		}
+	}
+}
+
+// checkDurationMode は tsMode の milliseconds・seconds が duration 型（またはその配列）だけに使われているかを検証し、問題の内容を返します
+func checkDurationMode(t types.DefinitionType, mode types.TSMode) string {
+	if mode == "" {
+		return ""
+	}
+	isDuration := types.DefinitionType(strings.TrimSuffix(string(t), "[]")) == types.DefinitionTypeDuration
+	durationMode := mode == types.ModeMilliseconds || mode == types.ModeSeconds
+	switch {
+	case isDuration && !durationMode:
+		return fmt.Sprintf("tsMode %q cannot be used with type %q (expected milliseconds or seconds)", mode, t)
+	case !isDuration && durationMode:
+		return fmt.Sprintf("tsMode %q can only be used with duration types", mode)
+	}
+	return ""
+}
+
+// isFieldType は object のフィールドに使えるスカラー型・スカラー型の配列かどうかを返します
+func isFieldType(t types.DefinitionType) bool {
+	return scalarTypes[t] || scalarTypes[types.DefinitionType(strings.TrimSuffix(string(t), "[]"))]
+}
+
+// checkArray は配列型 t の値を検証し、問題の内容を返します
+func checkArray(t types.DefinitionType, value interface{}) []string {
+	elems, ok := value.([]interface{})
+	if !ok {
+		return []string{fmt.Sprintf("value must be an array for type %q, got %s", t, jsonTypeName(value))}
+	}
+
+	baseType := types.DefinitionType(strings.TrimSuffix(string(t), "[]"))
+	var msgs []string
+	for i, elem := range elems {
+		if s, ok := elem.(string); ok && referencePattern.MatchString(s) {
+			msgs = append(msgs, fmt.Sprintf("element %d: references are not supported in array values", i))
+			continue
+		}
+		if msg := checkValue(baseType, elem); msg != "" {
+			msgs = append(msgs, fmt.Sprintf("element %d: %s", i, msg))
+		}
+	}
+	return msgs
+}
+
+// checkValue は JSON の値が宣言された型に合っているかを確認し、問題があれば内容を返します
+func checkValue(t types.DefinitionType, value interface{}) string {
+	switch t {
+	case types.DefinitionTypeInt, types.DefinitionTypeInt32, types.DefinitionTypeInt64,
+		types.DefinitionTypeUint, types.DefinitionTypeUint32, types.DefinitionTypeUint64,
+		types.DefinitionTypeFloat, types.DefinitionTypeFloat32, types.DefinitionTypeFloat64:
+		n, ok := utils.AsNumber(value)
+		if !ok {
+			return typeMismatch(t, value)
+		}
+		if err := utils.CheckNumber(t, n); err != nil {
+			return err.Error()
+		}
+	case types.DefinitionTypeString:
+		if _, ok := value.(string); !ok {
+			return typeMismatch(t, value)
+		}
+	case types.DefinitionTypeBool:
+		if _, ok := value.(bool); !ok {
+			return typeMismatch(t, value)
+		}
+	case types.DefinitionTypeDate, types.DefinitionTypeTimestamp:
+		s, ok := value.(string)
+		if !ok {
+			return typeMismatch(t, value)
+		}
+		if _, err := time.Parse(time.RFC3339, s); err != nil {
+			return fmt.Sprintf("value %q is not an RFC3339 date", s)
+		}
+	case types.DefinitionTypeDuration:
+		s, ok := value.(string)
+		if !ok {
+			return typeMismatch(t, value)
+		}
+		if _, err := time.ParseDuration(s); err != nil {
+			return fmt.Sprintf("value %q is not a duration (e.g. \"30s\", \"1h30m\", \"250ms\")", s)
+		}
+	}
+	return ""
+}
+
+// typeMismatch は型不一致のメッセージを作成します
+func typeMismatch(t types.DefinitionType, value interface{}) string {
+	return fmt.Sprintf("value of type %q must be %s, got %s", t, expectedJSONType(t), jsonTypeName(value))
+}
+
+// expectedJSONType は定義型に対応する JSON の型名を返します
+func expectedJSONType(t types.DefinitionType) string {
+	switch t {
+	case types.DefinitionTypeString, types.DefinitionTypeDate, types.DefinitionTypeTimestamp, types.DefinitionTypeDuration:
+		return "a string"
+	case types.DefinitionTypeBool:
+		return "a boolean"
+	default:
+		return "a number"
+	}
+}
+
+// jsonTypeName はデコードされた JSON 値の型名を返します
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case json.Number, float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func sortedStringKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
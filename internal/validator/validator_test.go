@@ -0,0 +1,470 @@
+package validator
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/nantokaworks/konst/internal/diag"
+	"github.com/nantokaworks/konst/internal/types"
+)
+
+func TestValidateDefinition(t *testing.T) {
+	tests := []struct {
+		name     string
+		defName  string
+		def      types.Definition
+		expected []string // 含まれるべきメッセージの断片
+		code     diag.Code
+	}{
+		{
+			name:    "Valid int",
+			defName: "MaxRetries",
+			def:     types.Definition{Type: types.DefinitionTypeInt, Value: float64(3)},
+		},
+		{
+			name:    "Dependency expression is not type checked",
+			defName: "MaxRetries",
+			def:     types.Definition{Type: types.DefinitionTypeInt, Value: "{{BaseRetries}} * 2"},
+		},
+		{
+			name:     "Value type mismatch",
+			defName:  "MaxRetries",
+			def:      types.Definition{Type: types.DefinitionTypeInt, Value: "three"},
+			expected: []string{"must be a number, got string"},
+			code:     diag.CodeValueType,
+		},
+		{
+			name:     "Non-integer for int",
+			defName:  "MaxRetries",
+			def:      types.Definition{Type: types.DefinitionTypeInt32, Value: 1.5},
+			expected: []string{"is not an integer"},
+			code:     diag.CodeValueType,
+		},
+		{
+			name:     "Negative unsigned",
+			defName:  "Count",
+			def:      types.Definition{Type: types.DefinitionTypeUint32, Value: float64(-1)},
+			expected: []string{"must not be negative"},
+			code:     diag.CodeValueType,
+		},
+		{
+			name:    "Uint64 max keeps precision",
+			defName: "UInt64Value",
+			def:     types.Definition{Type: types.DefinitionTypeUint64, Value: json.Number("18446744073709551615")},
+		},
+		{
+			name:     "Int32 out of range",
+			defName:  "Int32Value",
+			def:      types.Definition{Type: types.DefinitionTypeInt32, Value: json.Number("2147483648")},
+			expected: []string{`out of range for type "int32"`},
+			code:     diag.CodeValueType,
+		},
+		{
+			name:     "Float32 out of range",
+			defName:  "Float32Value",
+			def:      types.Definition{Type: types.DefinitionTypeFloat32, Value: json.Number("1e39")},
+			expected: []string{`out of range for type "float32"`},
+			code:     diag.CodeValueType,
+		},
+		{
+			name:     "Missing value",
+			defName:  "Name",
+			def:      types.Definition{Type: types.DefinitionTypeString},
+			expected: []string{"value is required"},
+			code:     diag.CodeMissingField,
+		},
+		{
+			name:     "Invalid date",
+			defName:  "ReleaseDate",
+			def:      types.Definition{Type: types.DefinitionTypeDate, Value: "2024-01-01"},
+			expected: []string{"is not an RFC3339 date"},
+			code:     diag.CodeValueType,
+		},
+		{
+			name:     "Unknown type",
+			defName:  "Value",
+			def:      types.Definition{Type: "decimal", Value: float64(1)},
+			expected: []string{`unknown type "decimal"`},
+			code:     diag.CodeUnknownType,
+		},
+		{
+			name:     "Unknown modes",
+			defName:  "Value",
+			def:      types.Definition{Type: types.DefinitionTypeInt, Value: float64(1), TSMode: "hex", GoMode: "uint8"},
+			expected: []string{`unknown tsMode "hex"`, `unknown goMode "uint8"`},
+			code:     diag.CodeUnknownMode,
+		},
+		{
+			name:     "Invalid identifier",
+			defName:  "max-retries",
+			def:      types.Definition{Type: types.DefinitionTypeInt, Value: float64(1)},
+			expected: []string{"not a valid identifier"},
+			code:     diag.CodeInvalidName,
+		},
+		{
+			name:     "Enum default not in values",
+			defName:  "Status",
+			def:      types.Definition{Type: types.DefinitionTypeEnum, Values: types.StringEnumValues("a", "b", "a"), Default: "c"},
+			expected: []string{`duplicate enum value "a"`, `default "c" is not one of the enum values`},
+			code:     diag.CodeInvalidEnum,
+		},
+		{
+			name:     "Enum value descriptions",
+			defName:  "Status",
+			def:      types.Definition{Type: types.DefinitionTypeEnum, Values: types.StringEnumValues("a", "b"), ValueDescriptions: map[string]string{"a": "A", "c": "C"}},
+			expected: []string{`description for unknown enum value "c"`},
+			code:     diag.CodeInvalidEnum,
+		},
+		{
+			name:     "Value descriptions on non-enum",
+			defName:  "MaxRetries",
+			def:      types.Definition{Type: types.DefinitionTypeInt, Value: float64(3), ValueDescriptions: map[string]string{"3": "three"}},
+			expected: []string{"can only be used with enum definitions"},
+			code:     diag.CodeInvalidEnum,
+		},
+		{
+			name:    "Enum values with symbols",
+			defName: "Status",
+			def:     types.Definition{Type: types.DefinitionTypeEnum, Values: types.StringEnumValues("pending-review", "on hold", "in_progress")},
+		},
+		{
+			name:     "Enum values with the same constant name",
+			defName:  "Status",
+			def:      types.Definition{Type: types.DefinitionTypeEnum, Values: types.StringEnumValues("pending-review", "pendingReview")},
+			expected: []string{`enum value "pendingReview" has the same constant name PendingReview as "pending-review"`},
+			code:     diag.CodeInvalidEnum,
+		},
+		{
+			name:     "Enum values that cannot be constant names",
+			defName:  "Status",
+			def:      types.Definition{Type: types.DefinitionTypeEnum, Values: types.StringEnumValues("1st", "--")},
+			expected: []string{`enum value "1st" cannot be used in a constant name`, `enum value "--" cannot be used in a constant name`},
+			code:     diag.CodeInvalidEnum,
+		},
+		{
+			name:     "Empty enum",
+			defName:  "Status",
+			def:      types.Definition{Type: types.DefinitionTypeEnum},
+			expected: []string{"at least one value"},
+			code:     diag.CodeInvalidEnum,
+		},
+		{
+			name:    "Valid template",
+			defName: "ChatChannel",
+			def:     types.Definition{Type: types.DefinitionTypeTemplate, Template: "chat:%twitch_id%", Parameters: []string{"twitch_id"}},
+		},
+		{
+			name:     "Template parameter mismatch",
+			defName:  "ChatChannel",
+			def:      types.Definition{Type: types.DefinitionTypeTemplate, Template: "chat:%id%:%room%", Parameters: []string{"id", "user"}},
+			expected: []string{"%room% is not declared", `parameter "user" is not used`},
+			code:     diag.CodeInvalidTemplate,
+		},
+		{
+			name:     "Array element mismatch",
+			defName:  "Ports",
+			def:      types.Definition{Type: "int[]", Value: []interface{}{float64(80), "443"}},
+			expected: []string{"element 1: value of type \"int\" must be a number"},
+			code:     diag.CodeValueType,
+		},
+		{
+			name:    "Valid object",
+			defName: "RateLimit",
+			def: types.Definition{
+				Type: types.DefinitionTypeObject,
+				Fields: []types.Field{
+					{Name: "limit", Type: types.DefinitionTypeInt},
+					{Name: "hosts", Type: "string[]"},
+					{Name: "retry", Type: types.DefinitionTypeObject, Fields: []types.Field{{Name: "max", Type: types.DefinitionTypeUint32}}},
+				},
+				Value: map[string]interface{}{
+					"limit": json.Number("100"),
+					"hosts": []interface{}{"a", "b"},
+					"retry": map[string]interface{}{"max": json.Number("3")},
+				},
+			},
+		},
+		{
+			name:    "Object value type mismatch",
+			defName: "RateLimit",
+			def: types.Definition{
+				Type: types.DefinitionTypeObject,
+				Fields: []types.Field{
+					{Name: "limit", Type: types.DefinitionTypeInt},
+					{Name: "retry", Type: types.DefinitionTypeObject, Fields: []types.Field{{Name: "max", Type: types.DefinitionTypeUint32}}},
+				},
+				Value: map[string]interface{}{
+					"limit": "many",
+					"retry": map[string]interface{}{"max": json.Number("-1")},
+				},
+			},
+			expected: []string{`field "limit": value of type "int" must be a number`, `field "retry.max": value -1 must not be negative`},
+			code:     diag.CodeValueType,
+		},
+		{
+			name:    "Object value has undeclared field",
+			defName: "RateLimit",
+			def: types.Definition{
+				Type:   types.DefinitionTypeObject,
+				Fields: []types.Field{{Name: "limit", Type: types.DefinitionTypeInt}},
+				Value:  map[string]interface{}{"limit": json.Number("1"), "burst": json.Number("2")},
+			},
+			expected: []string{`field "burst" is not declared in fields`},
+			code:     diag.CodeInvalidObject,
+		},
+		{
+			name:    "Object field value missing",
+			defName: "RateLimit",
+			def: types.Definition{
+				Type:   types.DefinitionTypeObject,
+				Fields: []types.Field{{Name: "limit", Type: types.DefinitionTypeInt}},
+				Value:  map[string]interface{}{},
+			},
+			expected: []string{`field "limit": value is required`},
+			code:     diag.CodeMissingField,
+		},
+		{
+			name:    "Object field Go name conflict",
+			defName: "RateLimit",
+			def: types.Definition{
+				Type: types.DefinitionTypeObject,
+				Fields: []types.Field{
+					{Name: "max_burst", Type: types.DefinitionTypeInt},
+					{Name: "maxBurst", Type: types.DefinitionTypeInt},
+				},
+				Value: map[string]interface{}{"max_burst": json.Number("1"), "maxBurst": json.Number("1")},
+			},
+			expected: []string{`field "maxBurst" has the same Go name as "max_burst"`},
+			code:     diag.CodeInvalidName,
+		},
+		{
+			name:    "Valid table",
+			defName: "Plan",
+			def: types.Definition{
+				Type:    types.DefinitionTypeTable,
+				Columns: []types.Field{{Name: "id", Type: types.DefinitionTypeInt, Key: true}, {Name: "name", Type: types.DefinitionTypeString, Key: true}},
+				Rows: []map[string]interface{}{
+					{"id": json.Number("1"), "name": "free"},
+					{"id": json.Number("2"), "name": "pro"},
+				},
+			},
+		},
+		{
+			name:    "Table duplicate keys",
+			defName: "Plan",
+			def: types.Definition{
+				Type:    types.DefinitionTypeTable,
+				Columns: []types.Field{{Name: "id", Type: types.DefinitionTypeInt, Key: true}, {Name: "name", Type: types.DefinitionTypeString, Key: true}},
+				Rows: []map[string]interface{}{
+					{"id": json.Number("1"), "name": "free"},
+					{"id": json.Number("1.0"), "name": "pro"},
+					{"id": json.Number("3"), "name": "free"},
+				},
+			},
+			expected: []string{`key column "id" has duplicate value 1 in rows 0 and 1`, `key column "name" has duplicate value "free" in rows 0 and 2`},
+			code:     diag.CodeDuplicateKey,
+		},
+		{
+			name:    "Table row type mismatch",
+			defName: "Plan",
+			def: types.Definition{
+				Type:    types.DefinitionTypeTable,
+				Columns: []types.Field{{Name: "id", Type: types.DefinitionTypeInt, Key: true}},
+				Rows:    []map[string]interface{}{{"id": "one"}},
+			},
+			expected: []string{`field "rows[0].id": value of type "int" must be a number`},
+			code:     diag.CodeValueType,
+		},
+		{
+			name:    "Invalid key columns",
+			defName: "Plan",
+			def: types.Definition{
+				Type:    types.DefinitionTypeTable,
+				Columns: []types.Field{{Name: "price", Type: types.DefinitionTypeFloat64, Key: true}},
+			},
+			expected: []string{`key column "price" must be an integer or string type`},
+			code:     diag.CodeInvalidObject,
+		},
+		{
+			name:    "Key outside table",
+			defName: "RateLimit",
+			def: types.Definition{
+				Type:   types.DefinitionTypeObject,
+				Fields: []types.Field{{Name: "limit", Type: types.DefinitionTypeInt, Key: true}},
+				Value:  map[string]interface{}{"limit": json.Number("1")},
+			},
+			expected: []string{`field "limit": key can only be used with table columns`},
+			code:     diag.CodeInvalidObject,
+		},
+		{
+			name:    "Valid map",
+			defName: "Ports",
+			def: types.Definition{
+				Type:      types.DefinitionTypeMap,
+				KeyType:   types.DefinitionTypeString,
+				ValueType: types.DefinitionTypeUint32,
+				Value:     map[string]interface{}{"http": json.Number("80"), "https": json.Number("443")},
+			},
+		},
+		{
+			name:    "Valid enum keyed map",
+			defName: "StatusLabels",
+			def: types.Definition{
+				Type:      types.DefinitionTypeMap,
+				KeyType:   "UserStatus",
+				ValueType: "string[]",
+				Value:     map[string]interface{}{"active": []interface{}{"Active"}},
+			},
+		},
+		{
+			name:    "Map integer keys",
+			defName: "Codes",
+			def: types.Definition{
+				Type:      types.DefinitionTypeMap,
+				KeyType:   types.DefinitionTypeInt32,
+				ValueType: types.DefinitionTypeString,
+				Value:     map[string]interface{}{"1": "a", "1.0": "b", "3000000000": "c", "x": "d"},
+			},
+			expected: []string{`key "1.0" is the same number as "1"`, `key "3000000000"`, `key "x" is not a number for keyType "int32"`},
+			code:     diag.CodeInvalidMap,
+		},
+		{
+			name:    "Map value type mismatch",
+			defName: "Ports",
+			def: types.Definition{
+				Type:      types.DefinitionTypeMap,
+				KeyType:   types.DefinitionTypeString,
+				ValueType: types.DefinitionTypeInt,
+				Value:     map[string]interface{}{"http": "80", "https": "{{HTTPSPort}}"},
+			},
+			expected: []string{`key "http": value of type "int" must be a number`, `key "https": references are not supported in map values`},
+			code:     diag.CodeValueType,
+		},
+		{
+			name:     "Map without key and value types",
+			defName:  "Ports",
+			def:      types.Definition{Type: types.DefinitionTypeMap, Value: map[string]interface{}{}},
+			expected: []string{"keyType is required", "valueType is required"},
+			code:     diag.CodeMissingField,
+		},
+		{
+			name:     "Key type outside map",
+			defName:  "Port",
+			def:      types.Definition{Type: types.DefinitionTypeInt, KeyType: types.DefinitionTypeString, Value: json.Number("80")},
+			expected: []string{"keyType and valueType can only be used with map definitions"},
+			code:     diag.CodeInvalidMap,
+		},
+		{
+			name:    "Valid durations",
+			defName: "Timeouts",
+			def: types.Definition{
+				Type:   types.DefinitionTypeObject,
+				Fields: []types.Field{{Name: "connect", Type: types.DefinitionTypeDuration, TSMode: types.ModeSeconds}, {Name: "backoff", Type: "duration[]"}},
+				Value:  map[string]interface{}{"connect": "1h30m", "backoff": []interface{}{"250ms", "1s"}},
+			},
+		},
+		{
+			name:     "Invalid duration",
+			defName:  "Timeout",
+			def:      types.Definition{Type: types.DefinitionTypeDuration, Value: "1d"},
+			expected: []string{`value "1d" is not a duration`},
+			code:     diag.CodeValueType,
+		},
+		{
+			name:     "Duration as number",
+			defName:  "Timeout",
+			def:      types.Definition{Type: types.DefinitionTypeDuration, Value: json.Number("30000")},
+			expected: []string{`value of type "duration" must be a string, got number`},
+			code:     diag.CodeValueType,
+		},
+		{
+			name:     "Duration with non-duration tsMode",
+			defName:  "Timeout",
+			def:      types.Definition{Type: types.DefinitionTypeDuration, Value: "30s", TSMode: types.ModeBigInt},
+			expected: []string{`tsMode "bigint" cannot be used with type "duration"`},
+			code:     diag.CodeUnknownMode,
+		},
+		{
+			name:     "Seconds mode outside duration",
+			defName:  "Port",
+			def:      types.Definition{Type: types.DefinitionTypeInt, Value: json.Number("80"), TSMode: types.ModeSeconds},
+			expected: []string{`tsMode "seconds" can only be used with duration types`},
+			code:     diag.CodeUnknownMode,
+		},
+		{
+			name:     "Invalid expression",
+			defName:  "MaxRetries",
+			def:      types.Definition{Type: types.DefinitionTypeInt, Value: "{{BaseRetries}} * (2 +"},
+			expected: []string{`invalid expression "{{BaseRetries}} * (2 +": column 23: unexpected end of expression`},
+			code:     diag.CodeInvalidExpression,
+		},
+		{
+			name:     "Reference in array element",
+			defName:  "Ports",
+			def:      types.Definition{Type: "int[]", Value: []interface{}{json.Number("80"), "{{HttpsPort}}"}},
+			expected: []string{"element 1: references are not supported in array values"},
+			code:     diag.CodeValueType,
+		},
+		{
+			name:     "Array value is not an array",
+			defName:  "Ports",
+			def:      types.Definition{Type: "int[]", Value: float64(80)},
+			expected: []string{"value must be an array"},
+			code:     diag.CodeValueType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := ValidateDefinition(tt.defName, tt.def)
+			if len(problems) != len(tt.expected) {
+				t.Fatalf("Expected %d problems, got %d: %v", len(tt.expected), len(problems), problems)
+			}
+			for i, fragment := range tt.expected {
+				if !strings.Contains(problems[i].Message, fragment) {
+					t.Errorf("Expected problem %d to contain %q, got %q", i, fragment, problems[i].Message)
+				}
+				if problems[i].Code != tt.code {
+					t.Errorf("Expected problem %d to have code %s, got %s", i, tt.code, problems[i].Code)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateSchemaCollectsAllIssues(t *testing.T) {
+	schema := &types.Schema{
+		File:      "defs/test.json",
+		Version:   "1.0",
+		GoPackage: "test",
+		Definitions: map[string]types.Definition{
+			"B": {Type: types.DefinitionTypeBool, Value: "yes"},
+			"A": {Type: types.DefinitionTypeEnum, Values: types.StringEnumValues("x"), Default: "y"},
+			"C": {Type: types.DefinitionTypeString, Value: "ok"},
+		},
+	}
+
+	issues := ValidateSchema(schema)
+	if len(issues) != 2 {
+		t.Fatalf("Expected 2 issues, got %d: %v", len(issues), issues)
+	}
+	if issues[0].Name != "A" || issues[1].Name != "B" {
+		t.Errorf("Expected issues sorted by name, got %v", issues)
+	}
+	if got := issues[1].Error(); !strings.HasPrefix(got, "B: ") {
+		t.Errorf("Unexpected issue format: %q", got)
+	}
+}
+
+func TestValidateSchemaGoEnumInterfaces(t *testing.T) {
+	schema := &types.Schema{
+		File:             "defs/test.json",
+		GoEnumInterfaces: []types.GoEnumInterface{types.GoEnumInterfaceJSON, "yaml"},
+	}
+
+	issues := ValidateSchema(schema)
+	if len(issues) != 1 || issues[0].Code != diag.CodeUnknownMode || !strings.Contains(issues[0].Message, `"yaml"`) {
+		t.Errorf("Expected one unknown-mode issue for \"yaml\", got %v", issues)
+	}
+}
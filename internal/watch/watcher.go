@@ -0,0 +1,204 @@
+package watch
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Op はファイル変更の種類を示す列挙型です。
+type Op string
+
+const (
+	OpCreate Op = "create"
+	OpModify Op = "modify"
+	OpRemove Op = "remove"
+)
+
+// Event は検出された1件のファイル変更を表します。
+type Event struct {
+	Path string
+	Op   Op
+}
+
+// fileState はポーリング時点でのファイルの状態を保持します
+type fileState struct {
+	modTime time.Time
+	size    int64
+	hash    string
+}
+
+// Watcher はポーリングでディレクトリ内のファイル変更を監視します。
+// 外部デーモンに依存せず、mtime とサイズで変更候補を絞り込み、
+// 内容ハッシュで実際に変更されたかを判定します。
+type Watcher struct {
+	Dir      string                 // 監視対象ディレクトリ
+	Interval time.Duration          // ポーリング間隔
+	Debounce time.Duration          // 変更がこの時間途切れるまで通知を待つ
+	Match    func(path string) bool // 監視対象ファイルの判定（nil の場合は全ファイル）
+
+	states map[string]fileState
+}
+
+// New は指定ディレクトリを監視する Watcher を作成します。
+func New(dir string, interval, debounce time.Duration, match func(path string) bool) *Watcher {
+	return &Watcher{
+		Dir:      dir,
+		Interval: interval,
+		Debounce: debounce,
+		Match:    match,
+	}
+}
+
+// Run は ctx がキャンセルされるまで監視を続け、変更がまとまるごとに onChange を呼び出します。
+// 開始時点のファイル状態は変更として通知しません。
+func (w *Watcher) Run(ctx context.Context, onChange func([]Event)) error {
+	if _, err := w.Poll(); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	var pending []Event
+	var lastChange time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			events, err := w.Poll()
+			if err != nil {
+				// 一時的な読み込みエラーは次回のポーリングで再試行する
+				continue
+			}
+			if len(events) > 0 {
+				pending = mergeEvents(pending, events)
+				lastChange = now
+				continue
+			}
+			if len(pending) > 0 && now.Sub(lastChange) >= w.Debounce {
+				onChange(pending)
+				pending = nil
+			}
+		}
+	}
+}
+
+// Poll はディレクトリを走査し、前回の走査からの変更を返します。
+func (w *Watcher) Poll() ([]Event, error) {
+	next := make(map[string]fileState)
+	var events []Event
+
+	err := filepath.Walk(w.Dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			// 走査中に削除されたファイルは無視する
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() || (w.Match != nil && !w.Match(path)) {
+			return nil
+		}
+
+		prev, known := w.states[path]
+		state := fileState{modTime: info.ModTime(), size: info.Size()}
+		if known && prev.modTime.Equal(state.modTime) && prev.size == state.size {
+			state.hash = prev.hash
+			next[path] = state
+			return nil
+		}
+
+		hash, err := hashFile(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		state.hash = hash
+		next[path] = state
+
+		if !known {
+			if w.states != nil {
+				events = append(events, Event{Path: path, Op: OpCreate})
+			}
+		} else if prev.hash != hash {
+			events = append(events, Event{Path: path, Op: OpModify})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for path := range w.states {
+		if _, ok := next[path]; !ok {
+			events = append(events, Event{Path: path, Op: OpRemove})
+		}
+	}
+	w.states = next
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Path < events[j].Path
+	})
+	return events, nil
+}
+
+// hashFile はファイル内容の SHA-256 ハッシュを返します
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// mergeEvents はデバウンス中に溜まった変更をパスごとにまとめます。
+// 作成後に削除されたファイルなど、結果的に差分がないものは取り除きます。
+func mergeEvents(pending, events []Event) []Event {
+	ops := make(map[string]Op)
+	listed := make(map[string]bool)
+	var order []string
+	for _, e := range append(pending, events...) {
+		if !listed[e.Path] {
+			listed[e.Path] = true
+			order = append(order, e.Path)
+		}
+		prev, seen := ops[e.Path]
+		if !seen {
+			ops[e.Path] = e.Op
+			continue
+		}
+		switch {
+		case prev == OpCreate && e.Op == OpRemove:
+			delete(ops, e.Path)
+		case prev == OpCreate:
+			// 作成扱いのまま
+		case prev == OpRemove && e.Op == OpCreate:
+			ops[e.Path] = OpModify
+		default:
+			ops[e.Path] = e.Op
+		}
+	}
+
+	merged := make([]Event, 0, len(ops))
+	for _, path := range order {
+		if op, ok := ops[path]; ok {
+			merged = append(merged, Event{Path: path, Op: op})
+		}
+	}
+	return merged
+}
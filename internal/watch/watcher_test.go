@@ -0,0 +1,126 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPoll(t *testing.T) {
+	tempDir := t.TempDir()
+	writeFile := func(name, content string) string {
+		path := filepath.Join(tempDir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		return path
+	}
+
+	a := writeFile("a.json", `{"a": 1}`)
+	writeFile("ignored.txt", "ignored")
+
+	w := New(tempDir, time.Millisecond, time.Millisecond, func(path string) bool {
+		return strings.HasSuffix(path, ".json")
+	})
+
+	// 初回の走査では変更を通知しない
+	events, err := w.Poll()
+	if err != nil {
+		t.Fatalf("Poll failed: %v", err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("Expected no events on first poll, got %v", events)
+	}
+
+	// 内容が同じまま mtime だけ変わった場合は変更扱いしない
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(a, future, future); err != nil {
+		t.Fatalf("Chtimes failed: %v", err)
+	}
+	events, err = w.Poll()
+	if err != nil {
+		t.Fatalf("Poll failed: %v", err)
+	}
+	if len(events) != 0 {
+		t.Errorf("Expected no events for touch-only change, got %v", events)
+	}
+
+	writeFile("a.json", `{"a": 2}`)
+	b := writeFile("b.json", `{"b": 1}`)
+	writeFile("ignored.txt", "changed")
+
+	events, err = w.Poll()
+	if err != nil {
+		t.Fatalf("Poll failed: %v", err)
+	}
+	expected := []Event{{Path: a, Op: OpModify}, {Path: b, Op: OpCreate}}
+	if len(events) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, events)
+	}
+	for i := range expected {
+		if events[i] != expected[i] {
+			t.Errorf("Expected event %v, got %v", expected[i], events[i])
+		}
+	}
+
+	if err := os.Remove(b); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	events, err = w.Poll()
+	if err != nil {
+		t.Fatalf("Poll failed: %v", err)
+	}
+	if len(events) != 1 || events[0] != (Event{Path: b, Op: OpRemove}) {
+		t.Errorf("Expected remove event for %s, got %v", b, events)
+	}
+}
+
+func TestMergeEvents(t *testing.T) {
+	tests := []struct {
+		name     string
+		pending  []Event
+		events   []Event
+		expected []Event
+	}{
+		{
+			name:     "Create then modify stays create",
+			pending:  []Event{{Path: "a", Op: OpCreate}},
+			events:   []Event{{Path: "a", Op: OpModify}},
+			expected: []Event{{Path: "a", Op: OpCreate}},
+		},
+		{
+			name:     "Create then remove cancels out",
+			pending:  []Event{{Path: "a", Op: OpCreate}, {Path: "b", Op: OpModify}},
+			events:   []Event{{Path: "a", Op: OpRemove}},
+			expected: []Event{{Path: "b", Op: OpModify}},
+		},
+		{
+			name:     "Remove then create becomes modify",
+			pending:  []Event{{Path: "a", Op: OpRemove}},
+			events:   []Event{{Path: "a", Op: OpCreate}},
+			expected: []Event{{Path: "a", Op: OpModify}},
+		},
+		{
+			name:     "Modify then remove becomes remove",
+			pending:  []Event{{Path: "a", Op: OpModify}},
+			events:   []Event{{Path: "a", Op: OpRemove}},
+			expected: []Event{{Path: "a", Op: OpRemove}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := mergeEvents(tt.pending, tt.events)
+			if len(result) != len(tt.expected) {
+				t.Fatalf("Expected %v, got %v", tt.expected, result)
+			}
+			for i := range tt.expected {
+				if result[i] != tt.expected[i] {
+					t.Errorf("Expected event %v, got %v", tt.expected[i], result[i])
+				}
+			}
+		})
+	}
+}
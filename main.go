@@ -1,16 +1,38 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
+	"time"
 
+	"github.com/nantokaworks/konst/internal/diag"
 	"github.com/nantokaworks/konst/internal/i18n"
+	"github.com/nantokaworks/konst/internal/ir"
+	"github.com/nantokaworks/konst/internal/manifest"
 	"github.com/nantokaworks/konst/internal/process"
+	"github.com/nantokaworks/konst/internal/textdiff"
 	"github.com/nantokaworks/konst/internal/types"
 	"github.com/nantokaworks/konst/internal/utils"
+	"github.com/nantokaworks/konst/internal/watch"
+)
+
+const (
+	watchInterval = 500 * time.Millisecond // ウォッチモードのポーリング間隔
+	watchDebounce = 300 * time.Millisecond // 連続した変更をまとめる待ち時間
+)
+
+var (
+	reporter        diag.Writer             // 診断の出力先
+	machineReadable bool                    // 診断を JSON / SARIF で出力するかどうか
+	progress        io.Writer   = os.Stdout // 進捗メッセージの出力先
 )
 
 func init() {
@@ -22,102 +44,191 @@ func init() {
 }
 
 // dryRunPreview は生成予定のファイル一覧を表示します
-func dryRunPreview(inputPath, outputDir string, option *types.CommandOption) error {
+// 実際の生成と同じく全てのファイルの内容を求め、出力先にある既存のファイルと比較します。ファイルは書き込みません
+// showDiff が true の場合は既存のファイルとの差分を unified diff 形式で表示します
+func dryRunPreview(inputPath, outputDir string, option *types.CommandOption, showDiff bool) error {
 	info, err := os.Stat(inputPath)
 	if err != nil {
 		return err
 	}
 
 	isTS := strings.ToLower(*option.Mode) == "ts"
-	ext := ".go"
-	if isTS {
-		ext = ".ts"
-	}
 
-	fmt.Printf("%s: %s\n", i18n.T(i18n.MsgMode), *option.Mode)
-	fmt.Printf("%s: %s\n", i18n.T(i18n.MsgOutputDirectory), outputDir)
-	fmt.Printf("%s:\n", i18n.T(i18n.MsgFilesToBeGenerated))
+	fmt.Fprintf(progress, "%s: %s\n", i18n.T(i18n.MsgMode), *option.Mode)
+	fmt.Fprintf(progress, "%s: %s\n", i18n.T(i18n.MsgOutputDirectory), outputDir)
+	fmt.Fprintf(progress, "%s:\n", i18n.T(i18n.MsgFilesToBeGenerated))
 
 	if !info.IsDir() {
 		return fmt.Errorf(i18n.T(i18n.MsgInputMustBeDir))
 	}
 
-	var files []string
-	err = filepath.Walk(inputPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil || info.IsDir() || !strings.HasSuffix(info.Name(), ".json") {
-			return nil
-		}
-		rel, err := filepath.Rel(inputPath, path)
+	prog, err := ir.Load(inputPath, reporter.Write)
+	if err != nil {
+		return err
+	}
+	target := ir.NewTarget(outputDir, option, isTS)
+	outputs, err := process.Plan(prog, target, option, nil)
+	if err != nil {
+		return err
+	}
+
+	labels := map[process.Change]string{
+		process.Created:   i18n.T(i18n.MsgFileCreated),
+		process.Changed:   i18n.T(i18n.MsgFileChanged),
+		process.Unchanged: i18n.T(i18n.MsgFileUnchanged),
+	}
+	counts := make(map[process.Change]int)
+	var diffs []string
+	for _, out := range outputs {
+		change, current, err := out.Compare()
 		if err != nil {
 			return err
 		}
-		
-		// ディレクトリとファイル名を分離
-		dir := filepath.Dir(rel)
-		fileName := filepath.Base(rel)
-		fileName = strings.TrimSuffix(fileName, filepath.Ext(fileName))
-		
-		// ファイル名を命名規則に従って変換
-		namingStyle := ""
-		if option.NamingStyle != nil {
-			namingStyle = *option.NamingStyle
+		counts[change]++
+		fmt.Fprintf(progress, "  - %s (%s)\n", out.Path, labels[change])
+
+		if showDiff && change != process.Unchanged {
+			oldName := out.Path
+			if change == process.Created {
+				oldName = "/dev/null"
+			}
+			diffs = append(diffs, textdiff.Unified(oldName, out.Path, string(current), string(out.Content)))
 		}
-		convertedFileName := utils.ConvertFileName(fileName, namingStyle, isTS)
-		
-		// ディレクトリも変換
-		convertedDir := dir
-		if dir != "." {
-			convertedDir = utils.ConvertPath(dir, namingStyle, isTS)
+	}
+	// prune する場合は削除するファイルも表示
+	if *option.Prune {
+		prev, err := manifest.Load(outputDir)
+		if err != nil {
+			return err
 		}
-		
-		// 出力パスを構築
-		var outFile string
-		if convertedDir != "." {
-			outFile = filepath.Join(outputDir, convertedDir, convertedFileName+ext)
-		} else {
-			outFile = filepath.Join(outputDir, convertedFileName+ext)
+		leftovers, err := process.Leftovers(target, prev, outputs)
+		if err != nil {
+			return err
+		}
+		for _, l := range leftovers {
+			if !l.Modified {
+				fmt.Fprintf(progress, "  - %s (%s)\n", filepath.Join(outputDir, filepath.FromSlash(l.Path)), i18n.T(i18n.MsgFileRemoved))
+			}
 		}
-		files = append(files, outFile)
-		return nil
-	})
-	if err != nil {
-		return err
 	}
+	fmt.Fprintf(progress, i18n.T(i18n.MsgDryRunSummary)+"\n", counts[process.Created], counts[process.Changed], counts[process.Unchanged])
 
-	// TypeScript の場合はindex.tsも生成される
-	if isTS {
-		files = append(files, filepath.Join(outputDir, "index.ts"))
+	for _, d := range diffs {
+		fmt.Fprint(progress, "\n"+d)
 	}
+	return nil
+}
 
-	for _, file := range files {
-		fmt.Printf("  - %s\n", file)
+// checkGenerated は出力先の生成済みのコードが定義ファイルから生成するコードと一致するか確認します
+// 実際の生成と同じく全てのファイルの内容を求めて比較します。ファイルは書き込みません
+func checkGenerated(inputPath, outputDir string, option *types.CommandOption) (process.CheckResult, error) {
+	info, err := os.Stat(inputPath)
+	if err != nil {
+		return process.CheckResult{}, err
+	}
+	if !info.IsDir() {
+		return process.CheckResult{}, fmt.Errorf(i18n.T(i18n.MsgInputMustBeDir))
 	}
 
-	return nil
+	prog, err := ir.Load(inputPath, reporter.Write)
+	if err != nil {
+		return process.CheckResult{}, err
+	}
+	target := ir.NewTarget(outputDir, option, strings.ToLower(*option.Mode) == "ts")
+	outputs, err := process.Plan(prog, target, option, nil)
+	if err != nil {
+		return process.CheckResult{}, err
+	}
+	prev, err := manifest.Load(outputDir)
+	if err != nil {
+		return process.CheckResult{}, err
+	}
+	return process.Check(outputs, target, prev)
 }
 
-// validateOnly は JSON定義ファイルの検証のみを行います
-func validateOnly(inputPath string) error {
+// validateOnly は定義ファイルの検証のみを行います
+// 構文エラーに加えて定義内容の意味的な誤りも検証し、見つかった全ての問題を診断として出力します
+// 同じ名前の定義が生成コードで衝突するかは -m の出力モードで判定します
+// 戻り値はエラーの件数です（警告は含みません）
+func validateOnly(inputPath string, option *types.CommandOption) (int, error) {
 	info, err := os.Stat(inputPath)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	if !info.IsDir() {
-		return fmt.Errorf(i18n.T(i18n.MsgInputMustBeDir))
+		return 0, fmt.Errorf(i18n.T(i18n.MsgInputMustBeDir))
 	}
 
-	return filepath.Walk(inputPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil || info.IsDir() || !strings.HasSuffix(info.Name(), ".json") {
-			return nil
+	errorCount := 0
+	write := func(issues diag.List) error {
+		for _, d := range issues {
+			if d.Severity == diag.SeverityError {
+				errorCount++
+			}
 		}
-		_, err = utils.ParseSchemaFile(&path)
-		if err != nil {
-			return fmt.Errorf("%s %s: %v", i18n.T(i18n.MsgFileError), path, err)
+		return reporter.Write(issues)
+	}
+	// 生成と同じ手順で読み込み、生成と同じ問題を検証する
+	prog, err := ir.Load(inputPath, write)
+	if prog == nil {
+		return errorCount, err
+	}
+	for _, f := range prog.Files {
+		fmt.Fprintf(progress, "✓ %s\n", f.Path)
+	}
+	if err != nil {
+		return errorCount, write(diag.FromError(err))
+	}
+	target := ir.NewTarget("", option, strings.ToLower(*option.Mode) == "ts")
+	return errorCount, write(process.Validate(prog, target, option))
+}
+
+// printError はエラーを診断として出力します
+// 位置情報を持たないエラーはテキスト形式の場合のみ見出し付きで表示します
+func printError(key i18n.MessageKey, err error) {
+	if list := diag.Flatten(err); list != nil || machineReadable {
+		reporter.Write(diag.FromError(err))
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%s: %v\n", i18n.T(key), err)
+}
+
+// exit は出力途中の診断を書き出してから終了します
+func exit(code int) {
+	if err := reporter.Close(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	os.Exit(code)
+}
+
+// watchAndRegenerate は入力ディレクトリを監視し、変更があるたびにコードを再生成します
+// 再生成は初回の生成と同じオプション（-f を含む）で行います
+func watchAndRegenerate(inputPath, outDir string, option *types.CommandOption, isTS bool) error {
+	w := watch.New(inputPath, watchInterval, watchDebounce, func(path string) bool {
+		return utils.IsSchemaFile(path)
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Fprintf(progress, "%s: %s\n", i18n.T(i18n.MsgWatchStarted), inputPath)
+	err := w.Run(ctx, func(events []watch.Event) {
+		for _, e := range events {
+			fmt.Fprintf(progress, "%s: %s (%s)\n", i18n.T(i18n.MsgWatchChangeDetected), e.Path, e.Op)
+		}
+		// エラーが発生しても監視は継続する
+		if err := process.ProcessDirectory(inputPath, outDir, option, isTS); err != nil {
+			printError(i18n.MsgProcessingError, err)
+			return
 		}
-		fmt.Printf("✓ %s\n", path)
-		return nil
+		fmt.Fprintln(progress, i18n.T(i18n.MsgWatchRegenerated))
 	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(progress, i18n.T(i18n.MsgWatchStopped))
+	return nil
 }
 
 func main() {
@@ -135,49 +246,119 @@ func main() {
 		// エラーでも続行（英語デフォルトで動作）
 	}
 
+	// 診断の出力形式を決定
+	// 機械可読な形式では診断を標準出力に書き、進捗メッセージは標準エラー出力に回す
+	machineReadable = diag.IsMachineReadable(*option.Format)
+	diagOut := os.Stderr
+	if machineReadable {
+		diagOut = os.Stdout
+		progress = os.Stderr
+		process.Progress = os.Stderr
+	}
+	reporter, err = diag.NewWriter(*option.Format, diagOut, utils.VERSION)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", i18n.T(i18n.MsgCmdArgError), err)
+		os.Exit(1)
+	}
+	process.Report = reporter.Write
+
+	// ディレクトリから読み込む定義ファイルの拡張子を設定
+	if err := utils.SetSchemaExtensions(*option.Extensions); err != nil {
+		printError(i18n.MsgCmdArgError, err)
+		exit(1)
+	}
+
 	// バリデーションモードの場合は検証のみを実行
 	if *option.Validate {
-		if err := validateOnly(*option.SchemaFile); err != nil {
-			fmt.Fprintf(os.Stderr, "%s: %v\n", i18n.T(i18n.MsgValidationError), err)
-			os.Exit(1)
+		errorCount, err := validateOnly(*option.SchemaFile, option)
+		if err != nil {
+			printError(i18n.MsgValidationError, err)
+			exit(1)
 		}
-		fmt.Println(i18n.T(i18n.MsgValidationSuccess))
-		return
+		if errorCount > 0 {
+			if !machineReadable {
+				fmt.Fprintf(os.Stderr, "%s: %s\n", i18n.T(i18n.MsgValidationError), fmt.Sprintf(i18n.T(i18n.MsgValidationIssues), errorCount))
+			}
+			exit(1)
+		}
+		fmt.Fprintln(progress, i18n.T(i18n.MsgValidationSuccess))
+		exit(0)
+	}
+
+	// チェックモードの場合は生成済みのコードが最新か確認
+	if *option.Check {
+		result, err := checkGenerated(*option.SchemaFile, *option.OutputFile, option)
+		if err != nil {
+			printError(i18n.MsgCheckError, err)
+			exit(1)
+		}
+		if !result.UpToDate() {
+			fmt.Fprintf(os.Stderr, "%s:\n", i18n.T(i18n.MsgCheckOutOfDate))
+			printFiles := func(files []string, label i18n.MessageKey) {
+				for _, file := range files {
+					fmt.Fprintf(os.Stderr, "  - %s (%s)\n", file, i18n.T(label))
+				}
+			}
+			printFiles(result.Stale, i18n.MsgFileStale)
+			printFiles(result.Missing, i18n.MsgFileMissing)
+			printFiles(result.Orphaned, i18n.MsgFileOrphaned)
+			exit(1)
+		}
+		fmt.Fprintln(progress, i18n.T(i18n.MsgCheckUpToDate))
+		exit(0)
 	}
 
 	// ドライランモードの場合は生成予定ファイル一覧を表示
-	if *option.DryRun {
-		if err := dryRunPreview(*option.SchemaFile, *option.OutputFile, option); err != nil {
-			fmt.Fprintf(os.Stderr, "%s: %v\n", i18n.T(i18n.MsgDryRunError), err)
-			os.Exit(1)
+	if *option.DryRun || *option.Diff {
+		if err := dryRunPreview(*option.SchemaFile, *option.OutputFile, option, *option.Diff); err != nil {
+			printError(i18n.MsgDryRunError, err)
+			exit(1)
 		}
-		return
+		exit(0)
 	}
 
 	inputPath := *option.SchemaFile
 	info, err := os.Stat(inputPath)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "%s: %v\n", i18n.T(i18n.MsgInputPathError), err)
-		os.Exit(1)
+		printError(i18n.MsgInputPathError, err)
+		exit(1)
 	}
 
 	// ディレクトリのみ対応
 	if !info.IsDir() {
-		fmt.Fprintln(os.Stderr, i18n.T(i18n.MsgInputMustBeDir))
-		os.Exit(1)
+		printError(i18n.MsgInputPathError, errors.New(i18n.T(i18n.MsgInputMustBeDir)))
+		exit(1)
 	}
 
 	// 出力先が拡張子付きファイル名の場合はエラー
 	if filepath.Ext(*option.OutputFile) != "" {
-		fmt.Fprintln(os.Stderr, i18n.T(i18n.MsgOutputMustBeDir))
-		os.Exit(1)
+		printError(i18n.MsgCmdArgError, errors.New(i18n.T(i18n.MsgOutputMustBeDir)))
+		exit(1)
+	}
+
+	// ウォッチモードの再生成は前回生成したファイルを上書きするため、-f がないと初回以外は必ず失敗する
+	if *option.Watch && !*option.Force {
+		printError(i18n.MsgCmdArgError, errors.New(i18n.T(i18n.MsgWatchRequiresForce)))
+		exit(1)
 	}
 
 	// 出力モードは --mode フラグで判定
 	isTS := strings.ToLower(*option.Mode) == "ts"
 
 	if err := process.ProcessDirectory(inputPath, *option.OutputFile, option, isTS); err != nil {
-		fmt.Fprintf(os.Stderr, "%s: %v\n", i18n.T(i18n.MsgProcessingError), err)
-		os.Exit(1)
+		printError(i18n.MsgProcessingError, err)
+		// ウォッチモードでは初回生成に失敗しても監視を開始する
+		if !*option.Watch {
+			exit(1)
+		}
+	}
+
+	// ウォッチモードの場合は変更を監視して再生成を続ける
+	if *option.Watch {
+		if err := watchAndRegenerate(inputPath, *option.OutputFile, option, isTS); err != nil {
+			printError(i18n.MsgWatchError, err)
+			exit(1)
+		}
 	}
+	exit(0)
 }